@@ -0,0 +1,297 @@
+// Package azure provides a quantify.Exporter that reports metrics to Azure
+// Monitor as custom metrics, using the REST ingestion API.
+//
+// see: https://learn.microsoft.com/en-us/azure/azure-monitor/essentials/metrics-store-custom-rest-api
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+)
+
+const (
+	ingestionEndpoint = "https://%s.monitoring.azure.com%s/metrics"
+
+	defaultNamespace = "quantify"
+
+	// defaultTimeout bounds each request made by the default http.Client, so that
+	// an unresponsive ingestion endpoint can't block reporting indefinitely.
+	defaultTimeout = time.Second * 10
+)
+
+var (
+	ErrNoRegion      = errors.New("no region provided")
+	ErrNoResourceId  = errors.New("no resource id provided")
+	ErrNoTokenSource = errors.New("no token source provided")
+)
+
+// TokenSource provides the Azure AD access tokens used to authenticate with the
+// ingestion API (with the "https://monitoring.azure.com/" audience), and is
+// implemented by wrapping an Azure credential.
+type TokenSource interface {
+
+	// Token returns a valid access token.
+	Token(ctx context.Context) (string, error)
+}
+
+// Exporter implements quantify.Exporter, reporting metrics to Azure Monitor.
+type Exporter struct {
+	endpoint   string
+	tokens     TokenSource
+	client     *http.Client
+	namespace  string
+	dimensions map[string]string
+}
+
+// New returns an instantiated Exporter that reports metrics against the Azure
+// resource with the provided id (e.g. "/subscriptions/.../virtualMachines/vm1")
+// in the provided region (e.g. "eastus"), or returns an error if instantiation
+// fails.
+//
+// options allow the user to provide custom configurations as a list of Options.
+// If any options are invalid, a quantify.ValidationErrors describing every
+// problem is returned.
+func New(region string, resourceId string, tokens TokenSource, options ...Option) (*Exporter, error) {
+
+	if region == "" {
+		return nil, ErrNoRegion
+	}
+
+	if resourceId == "" {
+		return nil, ErrNoResourceId
+	}
+
+	if tokens == nil {
+		return nil, ErrNoTokenSource
+	}
+
+	exporter := &Exporter{
+		endpoint:   fmt.Sprintf(ingestionEndpoint, region, "/"+strings.TrimPrefix(resourceId, "/")),
+		tokens:     tokens,
+		client:     &http.Client{Timeout: defaultTimeout},
+		namespace:  defaultNamespace,
+		dimensions: make(map[string]string),
+	}
+
+	// apply every option, so that all problems are reported together
+	var errs quantify.ValidationErrors
+
+	for _, option := range options {
+		err := option(exporter)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return exporter, nil
+}
+
+// Export implements quantify.Exporter. Points are grouped into a request for each
+// metric, set of dimensions, and point end time, with each point's value reported
+// as a pre-aggregated sample (where the count, sum, min, and max are derived from
+// the single value). All requests are attempted, with the first error encountered
+// being returned.
+func (e *Exporter) Export(ctx context.Context, series []*quantify.Series) error {
+
+	requests := e.createRequestBodies(series)
+	if len(requests) == 0 {
+		return nil
+	}
+
+	token, err := e.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+
+	for _, body := range requests {
+		err = e.post(ctx, token, body)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// SumsCounts implements quantify.CountSummer, as custom metrics are aggregated per
+// minute by summing the values of their points, so running totals (see
+// quantify.OptionWithCumulativeTotals) would inflate their sums.
+func (e *Exporter) SumsCounts() bool {
+	return true
+}
+
+// createRequestBodies groups the points of the provided series into the request
+// bodies accepted by the ingestion API, ordered by time.
+func (e *Exporter) createRequestBodies(series []*quantify.Series) []*requestBody {
+
+	bodies := make(map[string]*requestBody)
+	keys := make([]string, 0)
+
+	for _, s := range series {
+
+		dimNames, dimValues := e.dimensionsOf(s.Metric)
+
+		for _, point := range s.Points {
+
+			key := fmt.Sprintf("%d\x00%s\x00%s", point.End.Unix(), s.Metric.Name, strings.Join(dimNames, "\x00"))
+
+			body, ok := bodies[key]
+			if !ok {
+				body = &requestBody{
+					Time: point.End.UTC(),
+					Data: &requestData{
+						BaseData: &baseData{
+							Metric:    s.Metric.Name,
+							Namespace: e.namespace,
+							DimNames:  dimNames,
+						},
+					},
+				}
+				bodies[key] = body
+				keys = append(keys, key)
+			}
+
+			body.Data.BaseData.Series = append(body.Data.BaseData.Series, pointToSeriesData(s.Metric, point, dimValues))
+		}
+	}
+
+	sort.Strings(keys)
+
+	result := make([]*requestBody, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, bodies[key])
+	}
+
+	return result
+}
+
+// dimensionsOf returns the sorted dimension names of the provided metric, mapped
+// from its label keys (see OptionWithDimensionMapping), and the corresponding
+// dimension values.
+func (e *Exporter) dimensionsOf(metric *quantify.Metric) ([]string, []string) {
+
+	mapped := make(map[string]string, len(metric.Labels))
+	names := make([]string, 0, len(metric.Labels))
+
+	for key, value := range metric.Labels {
+
+		name, ok := e.dimensions[key]
+		if !ok {
+			name = key
+		}
+
+		mapped[name] = value
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		values = append(values, mapped[name])
+	}
+
+	return names, values
+}
+
+// post sends a single request body to the ingestion API.
+func (e *Exporter) post(ctx context.Context, token string, body *requestBody) error {
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("failed to ingest metric %s: %s: %s", body.Data.BaseData.Metric, response.Status, message)
+	}
+
+	return nil
+}
+
+// pointToSeriesData converts a single point of the provided metric to the series
+// data of a custom metric, with distributions summarised by their minimum, maximum,
+// sum and count.
+func pointToSeriesData(metric *quantify.Metric, point *quantify.Point, dimValues []string) *seriesData {
+
+	if metric.ValueType == quantify.ValueTypeDistribution && point.Distribution != nil {
+
+		dv := point.Distribution
+
+		return &seriesData{
+			DimValues: dimValues,
+			Min:       dv.Min,
+			Max:       dv.Max,
+			Sum:       dv.Mean * float64(dv.Count),
+			Count:     dv.Count,
+		}
+	}
+
+	value := float64(point.Count)
+	if metric.ValueType == quantify.ValueTypeDouble {
+		value = point.Value
+	}
+
+	return &seriesData{
+		DimValues: dimValues,
+		Min:       value,
+		Max:       value,
+		Sum:       value,
+		Count:     1,
+	}
+}
+
+// requestBody is the JSON body accepted by the ingestion API.
+type requestBody struct {
+	Time time.Time    `json:"time"`
+	Data *requestData `json:"data"`
+}
+
+type requestData struct {
+	BaseData *baseData `json:"baseData"`
+}
+
+type baseData struct {
+	Metric    string        `json:"metric"`
+	Namespace string        `json:"namespace"`
+	DimNames  []string      `json:"dimNames,omitempty"`
+	Series    []*seriesData `json:"series"`
+}
+
+type seriesData struct {
+	DimValues []string `json:"dimValues,omitempty"`
+	Min       float64  `json:"min"`
+	Max       float64  `json:"max"`
+	Sum       float64  `json:"sum"`
+	Count     int64    `json:"count"`
+}
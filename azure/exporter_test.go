@@ -0,0 +1,162 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+type mockTokenSource struct {
+	token string
+	err   error
+}
+
+func (mts *mockTokenSource) Token(ctx context.Context) (string, error) {
+	return mts.token, mts.err
+}
+
+func TestExporter_createRequestBodies(t *testing.T) {
+
+	exporter, err := New(
+		"eastus",
+		"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm1",
+		&mockTokenSource{},
+		OptionWithDimensionMapping(map[string]string{"region": "Region"}),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://eastus.monitoring.azure.com/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm1/metrics", exporter.endpoint)
+
+	series := []*quantify.Series{
+		{
+			Metric: &quantify.Metric{
+				Name:   "requests",
+				Labels: map[string]string{"region": "uk", "code": "200"},
+			},
+			Points: []*quantify.Point{
+				{Start: time.Unix(1672693320, 0), End: time.Unix(1672693380, 0), Count: 3},
+			},
+		},
+		{
+			Metric: &quantify.Metric{
+				Name:   "requests",
+				Labels: map[string]string{"region": "us", "code": "500"},
+			},
+			Points: []*quantify.Point{
+				{Start: time.Unix(1672693320, 0), End: time.Unix(1672693380, 0), Count: 1},
+			},
+		},
+	}
+
+	bodies := exporter.createRequestBodies(series)
+
+	// series of the same metric, dimensions, and time share a request
+	assert.Equal(t, []*requestBody{
+		{
+			Time: time.Unix(1672693380, 0).UTC(),
+			Data: &requestData{
+				BaseData: &baseData{
+					Metric:    "requests",
+					Namespace: "quantify",
+					DimNames:  []string{"Region", "code"},
+					Series: []*seriesData{
+						{DimValues: []string{"uk", "200"}, Min: 3, Max: 3, Sum: 3, Count: 1},
+						{DimValues: []string{"us", "500"}, Min: 1, Max: 1, Sum: 1, Count: 1},
+					},
+				},
+			},
+		},
+	}, bodies)
+}
+
+func TestExporter_Export(t *testing.T) {
+
+	var header http.Header
+	var received *requestBody
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ := io.ReadAll(r.Body)
+		received = &requestBody{}
+		_ = json.Unmarshal(body, received)
+	}))
+	defer server.Close()
+
+	tokens := &mockTokenSource{token: "secret"}
+
+	exporter, err := New("eastus", "/resource", tokens)
+	assert.NoError(t, err)
+	exporter.endpoint = server.URL
+
+	series := []*quantify.Series{
+		{
+			Metric: &quantify.Metric{Name: "requests"},
+			Points: []*quantify.Point{
+				{Start: time.Unix(1672693320, 0), End: time.Unix(1672693380, 0), Count: 3},
+			},
+		},
+	}
+
+	assert.NoError(t, exporter.Export(context.Background(), series))
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+	assert.Equal(t, "requests", received.Data.BaseData.Metric)
+
+	// token errors are returned
+	tokens.err = errors.New("token expired")
+	assert.Equal(t, tokens.err, exporter.Export(context.Background(), series))
+}
+
+func TestNew(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		region        string
+		resourceId    string
+		tokens        TokenSource
+		expectedError error
+	}{
+		{
+			name:          "missing region",
+			resourceId:    "/resource",
+			tokens:        &mockTokenSource{},
+			expectedError: ErrNoRegion,
+		},
+		{
+			name:          "missing resource id",
+			region:        "eastus",
+			tokens:        &mockTokenSource{},
+			expectedError: ErrNoResourceId,
+		},
+		{
+			name:          "missing token source",
+			region:        "eastus",
+			resourceId:    "/resource",
+			expectedError: ErrNoTokenSource,
+		},
+	}
+
+	for _, test := range tests {
+
+		_, err := New(test.region, test.resourceId, test.tokens)
+
+		assert.Equalf(t, test.expectedError, err, "%s failed", test.name)
+	}
+
+	// the default client is bounded, rather than waiting indefinitely
+	exporter, err := New("eastus", "/resource", &mockTokenSource{})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTimeout, exporter.client.Timeout)
+
+	// running totals would inflate the per-minute sums, so are rejected
+	_, err = quantify.New(context.Background(), quantify.OptionWithExporter(exporter), quantify.OptionWithCumulativeTotals())
+	assert.ErrorIs(t, err, quantify.ErrCumulativeTotalsUnsupported)
+}
@@ -0,0 +1,23 @@
+package azure
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rustedturnip/quantify/internal/golden"
+)
+
+func TestExporter_golden(t *testing.T) {
+
+	exporter, err := New("eastus", "/resource", &mockTokenSource{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := json.Marshal(exporter.createRequestBodies(golden.Series()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden.Assert(t, "request_bodies", payload)
+}
@@ -0,0 +1,47 @@
+package azure
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Option defines a function for supplying the Exporter constructor with certain
+// configurations.
+type Option func(*Exporter) error
+
+// OptionWithHTTPClient allows a manually configured http.Client to be used to
+// call the ingestion API, instead of the default client, which times out requests
+// after 10 seconds. The provided client should also bound its requests (e.g. with
+// a Timeout), as a request that never completes blocks reporting.
+func OptionWithHTTPClient(client *http.Client) Option {
+	return func(exporter *Exporter) error {
+		exporter.client = client
+		return nil
+	}
+}
+
+// OptionWithNamespace sets the metric namespace that metrics are reported under,
+// which defaults to "quantify".
+func OptionWithNamespace(namespace string) Option {
+	return func(exporter *Exporter) error {
+		if namespace == "" {
+			return &quantify.FieldError{Path: "namespace", Err: errors.New("can't be empty")}
+		}
+		exporter.namespace = namespace
+		return nil
+	}
+}
+
+// OptionWithDimensionMapping maps metric label keys to Azure Monitor dimension
+// names (e.g. "region" to "Region"). Labels without a mapping are reported as a
+// dimension of the same name.
+func OptionWithDimensionMapping(mapping map[string]string) Option {
+	return func(exporter *Exporter) error {
+		for key, name := range mapping {
+			exporter.dimensions[key] = name
+		}
+		return nil
+	}
+}
@@ -0,0 +1,154 @@
+package quantify
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var (
+	ErrInvalidBuckets = errors.New("invalid bucket layout")
+)
+
+// BucketLayout describes how the boundaries of Buckets are defined.
+type BucketLayout int
+
+const (
+
+	// BucketLayoutExplicit buckets have the boundaries provided in Buckets.Bounds.
+	BucketLayoutExplicit BucketLayout = iota
+
+	// BucketLayoutLinear buckets have the same width (see LinearBuckets).
+	BucketLayoutLinear
+
+	// BucketLayoutExponential buckets grow in width exponentially (see
+	// ExponentialBuckets).
+	BucketLayoutExponential
+)
+
+// Buckets describes how the observations of a Distribution are counted into
+// buckets. Along with its finite buckets, each layout has an underflow bucket,
+// counting observations below the lowest boundary, and an overflow bucket,
+// counting observations at or above the highest.
+type Buckets struct {
+	Layout BucketLayout
+
+	// Bounds are the ascending boundaries between buckets of BucketLayoutExplicit.
+	Bounds []float64
+
+	// Count is the number of finite buckets of BucketLayoutLinear and
+	// BucketLayoutExponential.
+	Count int
+
+	// Width and Offset define BucketLayoutLinear buckets, the finite bucket i (from
+	// 1) covering [Offset + Width*(i-1), Offset + Width*i).
+	Width  float64
+	Offset float64
+
+	// GrowthFactor and Scale define BucketLayoutExponential buckets, the finite
+	// bucket i (from 1) covering [Scale * GrowthFactor^(i-1), Scale * GrowthFactor^i).
+	GrowthFactor float64
+	Scale        float64
+}
+
+// LinearBuckets returns count buckets of equal width, starting at offset, for
+// example LinearBuckets(10, 100, 0) for 0-100, 100-200, ..., 900-1000.
+func LinearBuckets(count int, width float64, offset float64) *Buckets {
+	return &Buckets{
+		Layout: BucketLayoutLinear,
+		Count:  count,
+		Width:  width,
+		Offset: offset,
+	}
+}
+
+// ExponentialBuckets returns count buckets whose boundaries grow by growthFactor,
+// starting at scale, for example ExponentialBuckets(10, 2, 1) for 1-2, 2-4, ...,
+// 512-1024. They suit latencies, which span orders of magnitude.
+func ExponentialBuckets(count int, growthFactor float64, scale float64) *Buckets {
+	return &Buckets{
+		Layout:       BucketLayoutExponential,
+		Count:        count,
+		GrowthFactor: growthFactor,
+		Scale:        scale,
+	}
+}
+
+// ExplicitBuckets returns buckets with the provided ascending boundaries, for
+// example ExplicitBuckets(10, 50, 100) for 10-50 and 50-100.
+func ExplicitBuckets(bounds ...float64) *Buckets {
+	return &Buckets{
+		Layout: BucketLayoutExplicit,
+		Bounds: bounds,
+	}
+}
+
+// validate returns ErrInvalidBuckets if the Buckets can't be used.
+func (b *Buckets) validate() error {
+
+	switch b.Layout {
+	case BucketLayoutLinear:
+		if b.Count <= 0 || b.Width <= 0 {
+			return ErrInvalidBuckets
+		}
+
+	case BucketLayoutExponential:
+		if b.Count <= 0 || b.GrowthFactor <= 1 || b.Scale <= 0 {
+			return ErrInvalidBuckets
+		}
+
+	case BucketLayoutExplicit:
+		if len(b.Bounds) == 0 {
+			return ErrInvalidBuckets
+		}
+
+		for i := 1; i < len(b.Bounds); i++ {
+			if b.Bounds[i] <= b.Bounds[i-1] {
+				return ErrInvalidBuckets
+			}
+		}
+
+	default:
+		return ErrInvalidBuckets
+	}
+
+	return nil
+}
+
+// Boundaries returns the ascending boundaries between the buckets, from the lower
+// boundary of the first finite bucket to the upper boundary of the last.
+func (b *Buckets) Boundaries() []float64 {
+
+	if b == nil {
+		return nil
+	}
+
+	switch b.Layout {
+	case BucketLayoutLinear:
+
+		bounds := make([]float64, b.Count+1)
+		for i := range bounds {
+			bounds[i] = b.Offset + b.Width*float64(i)
+		}
+		return bounds
+
+	case BucketLayoutExponential:
+
+		bounds := make([]float64, b.Count+1)
+		for i := range bounds {
+			bounds[i] = b.Scale * math.Pow(b.GrowthFactor, float64(i))
+		}
+		return bounds
+	}
+
+	return append([]float64{}, b.Bounds...)
+}
+
+// bucketIndex returns the index of the bucket that value is counted in, given the
+// boundaries of the buckets, where 0 is the underflow bucket and len(bounds) the
+// overflow bucket.
+func bucketIndex(bounds []float64, value float64) int {
+	return sort.Search(len(bounds), func(i int) bool {
+		return bounds[i] > value
+	})
+}
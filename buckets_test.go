@@ -0,0 +1,113 @@
+package quantify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuckets_validate(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		buckets     *Buckets
+		expectedErr error
+	}{
+		{
+			name:    "linear",
+			buckets: LinearBuckets(10, 100, 0),
+		},
+		{
+			name:        "linear without width",
+			buckets:     LinearBuckets(10, 0, 0),
+			expectedErr: ErrInvalidBuckets,
+		},
+		{
+			name:    "exponential",
+			buckets: ExponentialBuckets(10, 2, 1),
+		},
+		{
+			name:        "exponential without growth",
+			buckets:     ExponentialBuckets(10, 1, 1),
+			expectedErr: ErrInvalidBuckets,
+		},
+		{
+			name:        "exponential without count",
+			buckets:     ExponentialBuckets(0, 2, 1),
+			expectedErr: ErrInvalidBuckets,
+		},
+		{
+			name:    "explicit",
+			buckets: ExplicitBuckets(10, 50, 100),
+		},
+		{
+			name:        "explicit unordered",
+			buckets:     ExplicitBuckets(10, 100, 50),
+			expectedErr: ErrInvalidBuckets,
+		},
+		{
+			name:        "explicit empty",
+			buckets:     ExplicitBuckets(),
+			expectedErr: ErrInvalidBuckets,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expectedErr, test.buckets.validate(), "%s failed", test.name)
+	}
+}
+
+func TestBuckets_Boundaries(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		buckets        *Buckets
+		expectedBounds []float64
+	}{
+		{
+			name:           "linear",
+			buckets:        LinearBuckets(3, 100, 50),
+			expectedBounds: []float64{50, 150, 250, 350},
+		},
+		{
+			name:           "exponential",
+			buckets:        ExponentialBuckets(4, 2, 1),
+			expectedBounds: []float64{1, 2, 4, 8, 16},
+		},
+		{
+			name:           "explicit",
+			buckets:        ExplicitBuckets(10, 50, 100),
+			expectedBounds: []float64{10, 50, 100},
+		},
+		{
+			name:           "nil",
+			buckets:        nil,
+			expectedBounds: nil,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expectedBounds, test.buckets.Boundaries(), "%s failed", test.name)
+	}
+}
+
+func TestBucketIndex(t *testing.T) {
+
+	bounds := []float64{10, 50, 100}
+
+	tests := []struct {
+		name          string
+		value         float64
+		expectedIndex int
+	}{
+		{name: "underflow", value: 5, expectedIndex: 0},
+		{name: "lower boundary", value: 10, expectedIndex: 1},
+		{name: "within", value: 75, expectedIndex: 2},
+		{name: "upper boundary", value: 100, expectedIndex: 3},
+		{name: "overflow", value: 1000, expectedIndex: 3},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expectedIndex, bucketIndex(bounds, test.value), "%s failed", test.name)
+	}
+}
@@ -0,0 +1,99 @@
+package quantify
+
+import "time"
+
+// countEvent is a count sent through the buffer of a Counter.
+type countEvent struct {
+	t time.Time
+	n int64
+
+	// flushed, when set, marks a flush request rather than a count, and is closed
+	// once every count sent before it has been aggregated.
+	flushed chan struct{}
+}
+
+// startBuffer configures the Counter to count through a buffered channel of the
+// provided size, starting the goroutine that aggregates its counts.
+func (c *Counter) startBuffer(size int) {
+
+	c.buffer = make(chan countEvent, size)
+	c.bufferStopped = make(chan struct{})
+
+	go c.aggregate()
+}
+
+// aggregate adds each count received through the buffer to the Counter, until the
+// buffer is stopped (see stopBuffer).
+func (c *Counter) aggregate() {
+
+	for {
+		select {
+
+		case event := <-c.buffer:
+
+			if event.flushed != nil {
+				close(event.flushed)
+				continue
+			}
+
+			c.add(event.t, event.n)
+
+		case <-c.bufferStopped:
+			return
+
+		}
+	}
+}
+
+// flushBuffer blocks until every count sent through the buffer before it was
+// called has been aggregated. It does nothing if the Counter isn't buffered.
+func (c *Counter) flushBuffer() {
+
+	if c.buffer == nil {
+		return
+	}
+
+	flushed := make(chan struct{})
+
+	select {
+	case c.buffer <- countEvent{flushed: flushed}:
+	case <-c.bufferStopped:
+		return
+	}
+
+	select {
+	case <-flushed:
+	case <-c.bufferStopped:
+	}
+}
+
+// stopBuffer aggregates every count sent through the buffer, and then stops the
+// goroutine aggregating it, discarding any later counts. It does nothing if the
+// Counter isn't buffered, and is safe to call multiple times.
+func (c *Counter) stopBuffer() {
+
+	if c.buffer == nil {
+		return
+	}
+
+	c.flushBuffer()
+
+	c.bufferStop.Do(func() {
+		close(c.bufferStopped)
+	})
+}
+
+// stopBuffers stops the buffers of every counter of the Quantifier (see
+// stopBuffer).
+func (q *Quantifier) stopBuffers() {
+
+	for _, mc := range q.registeredCounters() {
+		mc.counter.stopBuffer()
+	}
+
+	for _, source := range q.sources {
+		for _, mc := range source.metricCounters() {
+			mc.counter.stopBuffer()
+		}
+	}
+}
@@ -0,0 +1,130 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter_buffered(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	client := &Quantifier{
+		clock:    mockClock,
+		exporter: &mockExporter{},
+	}
+
+	parent, err := client.CreateCounter("requests", nil, 10)
+	assert.NoError(t, err)
+
+	counter, err := client.CreateChildCounter(parent, "requests_bursty", nil, 10, MetricOptionWithBufferedCounting(16))
+	assert.NoError(t, err)
+
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				counter.Count()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// all buffered counts are aggregated before points are taken
+	points := counter.takePoints(true, 0)
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, int64(8000), points[0].Count)
+	}
+
+	// and roll up into the parent
+	points = parent.takePoints(true, 0)
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, int64(8000), points[0].Count)
+	}
+}
+
+func TestCounter_stopBuffer(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	client := &Quantifier{
+		clock:    mockClock,
+		exporter: &mockExporter{},
+	}
+
+	baseline := runtime.NumGoroutine()
+
+	counter, err := client.CreateCounter("requests", nil, 10, MetricOptionWithBufferedCounting(16))
+	assert.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		counter.Count()
+	}
+
+	counter.stopBuffer()
+	counter.stopBuffer()
+
+	assert.True(t, waitForGoroutines(baseline), "aggregating goroutine didn't exit")
+
+	// counts sent before stopping are aggregated, and later counts discarded rather
+	// than blocking once the buffer is full
+	for i := 0; i < 32; i++ {
+		counter.Count()
+	}
+
+	points := counter.takePoints(true, 0)
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, int64(10), points[0].Count)
+	}
+}
+
+func TestQuantifier_Stop_buffered(t *testing.T) {
+
+	baseline := runtime.NumGoroutine()
+
+	exporter := &mockExporter{}
+
+	q, err := New(context.Background(), OptionWithExporter(exporter), OptionSynchronous())
+	assert.NoError(t, err)
+
+	counter, err := q.CreateCounter("requests", nil, 10, MetricOptionWithBufferedCounting(16))
+	assert.NoError(t, err)
+
+	deleted, err := q.CreateCounter("requests_deleted", nil, 10, MetricOptionWithBufferedCounting(16))
+	assert.NoError(t, err)
+
+	counter.Count()
+	deleted.Count()
+
+	// the goroutine of a deleted counter exits once its counts are reported
+	assert.NoError(t, q.DeleteCounter(deleted))
+	assert.NoError(t, q.Flush())
+	assert.True(t, waitForGoroutines(baseline+1), "goroutine of deleted counter didn't exit")
+
+	// and the remaining goroutines once the Quantifier is stopped, after the final
+	// report
+	q.Stop()
+	assert.True(t, waitForGoroutines(baseline), "goroutine of counter didn't exit")
+
+	counts := make(map[string]int64)
+	for _, s := range exporter.series {
+		for _, point := range s.Points {
+			counts[s.Metric.Name] += point.Count
+		}
+	}
+	assert.Equal(t, map[string]int64{"requests": 1, "requests_deleted": 1}, counts)
+}
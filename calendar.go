@@ -0,0 +1,104 @@
+package quantify
+
+import (
+	"time"
+)
+
+// CalendarPeriod defines the calendar window that a calendar counter's points are
+// aligned to.
+type CalendarPeriod int
+
+const (
+	// CalendarPeriodDay aligns points to days, starting at midnight.
+	CalendarPeriodDay CalendarPeriod = iota
+
+	// CalendarPeriodWeek aligns points to weeks, starting at midnight on the
+	// calendar's week start.
+	CalendarPeriodWeek
+
+	// CalendarPeriodMonth aligns points to months, starting at midnight on the
+	// first day of the month.
+	CalendarPeriodMonth
+)
+
+// Calendar defines the calendar windows that a calendar counter's points are
+// aligned to (see CreateCalendarCounter).
+type Calendar struct {
+
+	// Period is the window each point covers.
+	Period CalendarPeriod
+
+	// Location is the timezone that windows start in, defaulting to UTC.
+	Location *time.Location
+
+	// WeekStart is the day that weeks start on, defaulting to Sunday.
+	WeekStart time.Weekday
+}
+
+// CreateCalendarCounter creates a Counter (see CreateCounter) whose points are
+// aligned to calendar windows in a given timezone, such as days starting at
+// midnight in Europe/London or weeks starting on Monday, for business reporting
+// metrics. Unlike fixed intervals, windows follow the calendar, so days spanning a
+// daylight saving change are 23 or 25 hours long.
+//
+// Note: points are only reported once their window has ended, so a weekly counter
+// reports once a week (and its current window on Stop).
+func (q *Quantifier) CreateCalendarCounter(name string, labels map[string]string, calendar Calendar, options ...MetricOption) (*Counter, error) {
+
+	instrument, err := q.createCalendarCounter(name, labels, calendar, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopCounter(), nil
+	}
+
+	return instrument, err
+}
+
+// createCalendarCounter creates a Counter (see CreateCalendarCounter).
+func (q *Quantifier) createCalendarCounter(name string, labels map[string]string, calendar Calendar, options ...MetricOption) (*Counter, error) {
+
+	if calendar.Location == nil {
+		calendar.Location = time.UTC
+	}
+
+	mc, err := q.newMetricCounter(nil, name, labels, int64((24 * time.Hour).Seconds()), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	mc.counter.calendar = &calendar
+
+	q.registerCounter(mc)
+	return mc.counter, nil
+}
+
+// start returns the start of the calendar window containing the provided time.
+func (c *Calendar) start(t time.Time) time.Time {
+
+	t = t.In(c.Location)
+	year, month, day := t.Date()
+
+	switch c.Period {
+	case CalendarPeriodWeek:
+		offset := (int(t.Weekday()) - int(c.WeekStart) + 7) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, c.Location)
+	case CalendarPeriodMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, c.Location)
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, c.Location)
+	}
+}
+
+// end returns the end of the calendar window starting at the provided time.
+func (c *Calendar) end(start time.Time) time.Time {
+
+	start = start.In(c.Location)
+
+	switch c.Period {
+	case CalendarPeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case CalendarPeriodMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
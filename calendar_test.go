@@ -0,0 +1,117 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendar(t *testing.T) {
+
+	london, err := time.LoadLocation("Europe/London")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name          string
+		calendar      Calendar
+		input         time.Time
+		expectedStart time.Time
+		expectedEnd   time.Time
+	}{
+		{
+			name:          "utc day",
+			calendar:      Calendar{Period: CalendarPeriodDay, Location: time.UTC},
+			input:         time.Date(2023, 3, 15, 23, 30, 0, 0, time.UTC),
+			expectedStart: time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2023, 3, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day in timezone",
+			calendar: Calendar{Period: CalendarPeriodDay, Location: time.FixedZone("UTC+10", 10*60*60)},
+			// 2023-03-16 09:30 in UTC+10
+			input:         time.Date(2023, 3, 15, 23, 30, 0, 0, time.UTC),
+			expectedStart: time.Date(2023, 3, 15, 14, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2023, 3, 16, 14, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "day across daylight saving change",
+			calendar:      Calendar{Period: CalendarPeriodDay, Location: london},
+			input:         time.Date(2023, 3, 26, 12, 0, 0, 0, time.UTC),
+			expectedStart: time.Date(2023, 3, 26, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2023, 3, 26, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "week starting sunday",
+			calendar:      Calendar{Period: CalendarPeriodWeek, Location: time.UTC},
+			input:         time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC), // wednesday
+			expectedStart: time.Date(2023, 3, 12, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2023, 3, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "week starting monday",
+			calendar:      Calendar{Period: CalendarPeriodWeek, Location: time.UTC, WeekStart: time.Monday},
+			input:         time.Date(2023, 3, 12, 12, 0, 0, 0, time.UTC), // sunday
+			expectedStart: time.Date(2023, 3, 6, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2023, 3, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "month",
+			calendar:      Calendar{Period: CalendarPeriodMonth, Location: time.UTC},
+			input:         time.Date(2023, 2, 28, 12, 0, 0, 0, time.UTC),
+			expectedStart: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+
+		start := test.calendar.start(test.input)
+		end := test.calendar.end(start)
+
+		assert.Truef(t, test.expectedStart.Equal(start), "%s failed: start %s", test.name, start)
+		assert.Truef(t, test.expectedEnd.Equal(end), "%s failed: end %s", test.name, end)
+	}
+}
+
+func TestQuantifier_CreateCalendarCounter(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC))
+
+	client := &Quantifier{
+		clock:    mockClock,
+		exporter: &mockExporter{},
+	}
+
+	counter, err := client.CreateCalendarCounter("orders", nil, Calendar{
+		Period:    CalendarPeriodWeek,
+		WeekStart: time.Monday,
+	})
+	assert.NoError(t, err)
+
+	counter.Count()
+	counter.CountAt(time.Date(2023, 3, 8, 12, 0, 0, 0, time.UTC))
+
+	// only the previous week has ended
+	assert.Equal(t, []*Point{
+		{
+			Start: time.Unix(time.Date(2023, 3, 6, 0, 0, 0, 0, time.UTC).Unix(), 0),
+			End:   time.Unix(time.Date(2023, 3, 13, 0, 0, 0, 0, time.UTC).Unix(), 0),
+			Count: 1,
+		},
+	}, counter.takePoints(false, 0))
+
+	mockClock.Set(time.Date(2023, 3, 20, 0, 0, 0, 0, time.UTC))
+
+	points := counter.takePoints(false, 0)
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, time.Date(2023, 3, 13, 0, 0, 0, 0, time.UTC).Unix(), points[0].Start.Unix())
+		assert.Equal(t, int64(1), points[0].Count)
+	}
+}
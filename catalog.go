@@ -0,0 +1,211 @@
+package quantify
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Catalog is a machine-readable inventory of the metrics of a Quantifier, for
+// example to feed documentation or governance tooling.
+type Catalog struct {
+	Metrics []*CatalogEntry `json:"metrics"`
+}
+
+// CatalogEntry describes a metric of a Catalog. Each metric appears once, however
+// many series (label values) it publishes.
+type CatalogEntry struct {
+
+	// Name is the name of the metric, and Scope the part of it before its first "/"
+	// (e.g. "payments" for "payments/requests"), if any.
+	Name  string `json:"name"`
+	Scope string `json:"scope,omitempty"`
+
+	// Labels are the keys of the labels of the metric's series.
+	Labels []string `json:"labels"`
+
+	Kind        string `json:"kind"`
+	ValueType   string `json:"value_type"`
+	Unit        string `json:"unit,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Description string `json:"description,omitempty"`
+	LaunchStage string `json:"launch_stage,omitempty"`
+	Group       string `json:"group,omitempty"`
+}
+
+// describer is implemented by instruments that can describe the metrics they
+// publish before publishing them (see Catalog).
+type describer interface {
+	describe() []*Metric
+}
+
+// Catalog returns an inventory of the metrics created with the Quantifier, ordered
+// by name.
+func (q *Quantifier) Catalog() *Catalog {
+
+	counters := q.registeredCounters()
+
+	metrics := make([]*Metric, 0, len(counters))
+	for _, mc := range counters {
+		metrics = append(metrics, mc.metric)
+	}
+
+	for _, r := range q.recorders {
+		metrics = append(metrics, r.recordedMetric())
+	}
+
+	for _, source := range q.sources {
+		if d, ok := source.(describer); ok {
+			metrics = append(metrics, d.describe()...)
+		}
+	}
+
+	for _, c := range q.collectors {
+		if d, ok := c.(describer); ok {
+			metrics = append(metrics, d.describe()...)
+		}
+	}
+
+	entries := make(map[string]*CatalogEntry)
+	labels := make(map[string]map[string]bool)
+
+	for _, metric := range metrics {
+
+		entry, ok := entries[metric.Name]
+		if !ok {
+
+			entry = &CatalogEntry{
+				Name:        metric.Name,
+				Kind:        metric.Kind.String(),
+				ValueType:   metric.ValueType.String(),
+				Unit:        metric.Unit,
+				DisplayName: metric.DisplayName,
+				Description: metric.Description,
+				LaunchStage: string(metric.LaunchStage),
+				Group:       metric.Group,
+			}
+
+			if scope, _, found := strings.Cut(metric.Name, "/"); found {
+				entry.Scope = scope
+			}
+
+			entries[metric.Name] = entry
+			labels[metric.Name] = make(map[string]bool)
+		}
+
+		for key := range metric.Labels {
+			labels[metric.Name][key] = true
+		}
+	}
+
+	catalog := &Catalog{
+		Metrics: make([]*CatalogEntry, 0, len(entries)),
+	}
+
+	for name, entry := range entries {
+
+		entry.Labels = make([]string, 0, len(labels[name]))
+		for key := range labels[name] {
+			entry.Labels = append(entry.Labels, key)
+		}
+		sort.Strings(entry.Labels)
+
+		catalog.Metrics = append(catalog.Metrics, entry)
+	}
+
+	sort.Slice(catalog.Metrics, func(i, j int) bool {
+		return catalog.Metrics[i].Name < catalog.Metrics[j].Name
+	})
+
+	return catalog
+}
+
+// WriteFile writes the Catalog, as JSON, to the file at path, replacing it.
+func (c *Catalog) WriteFile(path string) error {
+
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, append(content, '\n'))
+}
+
+// describe implements describer, describing the metric with its label keys, whether
+// or not any series have been created.
+func (v *CounterVec) describe() []*Metric {
+	return []*Metric{v.metric}
+}
+
+// writeCatalog writes the Catalog to the file configured with OptionWithCatalogFile
+// if metrics have been created since it was last written.
+func (q *Quantifier) writeCatalog() {
+
+	if q.catalogPath == "" {
+		return
+	}
+
+	size := len(q.registeredCounters()) + len(q.recorders) + len(q.sources) + len(q.collectors)
+	if size == q.catalogSize {
+		return
+	}
+
+	if err := q.Catalog().WriteFile(q.catalogPath); err != nil {
+		q.errorHandler(q, err)
+		return
+	}
+
+	q.catalogSize = size
+}
+
+// describe implements describer.
+func (g *Gauge) describe() []*Metric {
+	return []*Metric{g.metric}
+}
+
+// describe implements describer.
+func (udc *UpDownCounter) describe() []*Metric {
+	return []*Metric{udc.metric}
+}
+
+// describe implements describer.
+func (og *ObservableGauge) describe() []*Metric {
+	return []*Metric{og.metric}
+}
+
+// describe implements describer, describing the metric with its label of values.
+func (tk *TopK) describe() []*Metric {
+	return []*Metric{withLabel(tk.metric, tk.key, TopKOther)}
+}
+
+// describe implements describer.
+func (uc *UniqueCounter) describe() []*Metric {
+	return []*Metric{uc.metric}
+}
+
+// describe implements describer, describing the metric with its label of windows.
+func (br *BurnRate) describe() []*Metric {
+	return []*Metric{withLabel(br.metric, burnRateLabelKeyWindow, formatWindow(br.windows[0]))}
+}
+
+// describe implements describer, describing the metric with its label of windows.
+func (r *Rate) describe() []*Metric {
+	return []*Metric{withLabel(r.metric, rateLabelKeyWindow, formatWindow(r.windows[0]))}
+}
+
+// describe implements describer, describing the counters of successes and failures
+// (whether or not any failures have been counted), and the success ratio, if
+// published.
+func (oc *OutcomeCounter) describe() []*Metric {
+
+	metrics := []*Metric{
+		oc.success.metric,
+		withLabel(oc.success.metric, outcomeLabelKeyReason, ReasonUnknown),
+	}
+
+	if oc.ratio != nil {
+		metrics = append(metrics, oc.ratio)
+	}
+
+	return metrics
+}
@@ -0,0 +1,125 @@
+package quantify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_Catalog(t *testing.T) {
+
+	q := &Quantifier{
+		clock:        systemClock{},
+		commonLabels: map[string]string{"hostname": "host-a"},
+	}
+
+	_, err := q.CreateCounter("payments/requests", map[string]string{"method": "GET"}, 60,
+		MetricOptionWithDescription("Requests received."),
+		MetricOptionWithUnit("1"),
+	)
+	assert.NoError(t, err)
+
+	_, err = q.CreateCounter("payments/requests", map[string]string{"method": "POST", "region": "eu"}, 60)
+	assert.NoError(t, err)
+
+	_, err = q.CreateGauge("queue_depth", nil, GaugeAggregationMax)
+	assert.NoError(t, err)
+
+	_, err = q.CreateOutcomeCounter("calls", nil, 60, true)
+	assert.NoError(t, err)
+
+	_, err = q.CreateTopK("paths", nil, "path", 5)
+	assert.NoError(t, err)
+
+	assert.Equal(t, &Catalog{
+		Metrics: []*CatalogEntry{
+			{
+				Name:      "calls",
+				Labels:    []string{"hostname", "outcome", "reason"},
+				Kind:      "CUMULATIVE",
+				ValueType: "INT64",
+			},
+			{
+				Name:      "calls_success_ratio",
+				Labels:    []string{"hostname"},
+				Kind:      "GAUGE",
+				ValueType: "DOUBLE",
+			},
+			{
+				Name:      "paths",
+				Labels:    []string{"hostname", "path"},
+				Kind:      "CUMULATIVE",
+				ValueType: "INT64",
+			},
+			{
+				Name:        "payments/requests",
+				Scope:       "payments",
+				Labels:      []string{"hostname", "method", "region"},
+				Kind:        "CUMULATIVE",
+				ValueType:   "INT64",
+				Unit:        "1",
+				Description: "Requests received.",
+			},
+			{
+				Name:      "queue_depth",
+				Labels:    []string{"hostname"},
+				Kind:      "GAUGE",
+				ValueType: "DOUBLE",
+			},
+		},
+	}, q.Catalog())
+}
+
+func TestOptionWithCatalogFile(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	path := filepath.Join(t.TempDir(), "catalog.json")
+
+	q, err := New(
+		context.Background(),
+		OptionWithClock(mockClock),
+		OptionWithExporter(&mockExporter{}),
+		OptionWithCatalogFile(path),
+		OptionSynchronous(),
+	)
+	assert.NoError(t, err)
+
+	_, err = q.CreateCounter("planes", nil, 10)
+	assert.NoError(t, err)
+
+	// the catalog is written with the first report
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, q.Flush())
+
+	read := func() *Catalog {
+
+		content, err := os.ReadFile(path)
+		assert.NoError(t, err)
+
+		catalog := &Catalog{}
+		assert.NoError(t, json.Unmarshal(content, catalog))
+		return catalog
+	}
+
+	if catalog := read(); assert.Len(t, catalog.Metrics, 1) {
+		assert.Equal(t, "planes", catalog.Metrics[0].Name)
+	}
+
+	// and rewritten once metrics are created later
+	_, err = q.CreateCounter("boats", nil, 10)
+	assert.NoError(t, err)
+	assert.NoError(t, q.Flush())
+
+	assert.Len(t, read().Metrics, 2)
+
+	q.Stop()
+}
@@ -0,0 +1,217 @@
+package quantify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CheckpointStore durably records the series of each report before they are
+// exported, and which of them have been acknowledged by the Exporter, so that a
+// crash or failed export doesn't lose intervals (see OptionWithCheckpointStore).
+//
+// Implementations may be backed by any durable storage, such as a file (see
+// FileCheckpointStore), Firestore, or Cloud Storage.
+type CheckpointStore interface {
+
+	// Save durably records the provided series, which are about to be exported,
+	// replacing any series previously saved.
+	Save(ctx context.Context, series []*Series) error
+
+	// Load returns any saved series that haven't been acknowledged.
+	Load(ctx context.Context) ([]*Series, error)
+
+	// Acknowledge records that the saved series have been exported.
+	Acknowledge(ctx context.Context) error
+}
+
+// checkpoint saves the provided series, along with any series still pending from
+// earlier reports, to the CheckpointStore. The combined series, which should be
+// exported, are returned.
+//
+// If the series can't be saved, they're kept pending without counting an attempt,
+// and the error is returned, so that they aren't exported until they've been
+// saved.
+//
+// If a maximum number of attempts has been set (see
+// OptionWithCheckpointMaxAttempts), pending series that have already been
+// attempted that many times are dropped, and recorded as part of the ongoing
+// outage.
+func (q *Quantifier) checkpoint(ctx context.Context, now time.Time, series []*Series) ([]*Series, error) {
+
+	if q.checkpointMaxAttempts > 0 && q.pendingAttempts >= q.checkpointMaxAttempts {
+		q.outage.recordFailure(now, q.pending)
+		q.pending = nil
+		q.pendingAttempts = 0
+	}
+
+	series = mergeSeries(q.pending, series)
+	q.pending = series
+
+	err := q.checkpointStore.Save(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+
+	q.pendingAttempts++
+
+	return series, nil
+}
+
+// acknowledge records that the pending series have been exported.
+func (q *Quantifier) acknowledge(ctx context.Context) {
+
+	q.pending = nil
+	q.pendingAttempts = 0
+
+	err := q.checkpointStore.Acknowledge(ctx)
+	if err != nil {
+		q.errorHandler(q, err)
+	}
+}
+
+// mergeSeries combines two sets of series, merging the points of any series of
+// the same metric (by name and labels).
+func mergeSeries(a, b []*Series) []*Series {
+
+	if len(a) == 0 {
+		return b
+	}
+
+	merged := make([]*Series, 0, len(a)+len(b))
+	byKey := make(map[string]*Series, len(a)+len(b))
+
+	for _, series := range [][]*Series{a, b} {
+		for _, s := range series {
+
+			key := metricKey(s.Metric)
+
+			existing, ok := byKey[key]
+			if !ok {
+				existing = &Series{
+					Metric: s.Metric,
+				}
+				byKey[key] = existing
+				merged = append(merged, existing)
+			}
+
+			existing.Points = mergePoints(existing.Points, s.Points)
+		}
+	}
+
+	return merged
+}
+
+// FileCheckpointStore implements CheckpointStore using a JSON file on the local
+// filesystem. Each write replaces the file atomically, so that a crash part way
+// through a write never leaves it corrupted.
+type FileCheckpointStore struct {
+	mu   *sync.Mutex
+	path string
+}
+
+// checkpointFile is the content of the file written by FileCheckpointStore.
+type checkpointFile struct {
+	Series       []*Series `json:"series"`
+	Acknowledged bool      `json:"acknowledged"`
+}
+
+// NewFileCheckpointStore returns a FileCheckpointStore that writes to the file at
+// the provided path.
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{
+		mu:   &sync.Mutex{},
+		path: path,
+	}
+}
+
+// Save implements CheckpointStore.
+func (fcs *FileCheckpointStore) Save(ctx context.Context, series []*Series) error {
+
+	fcs.mu.Lock()
+	defer fcs.mu.Unlock()
+
+	return fcs.write(&checkpointFile{
+		Series: series,
+	})
+}
+
+// Load implements CheckpointStore. No series are returned if the file doesn't
+// exist.
+func (fcs *FileCheckpointStore) Load(ctx context.Context) ([]*Series, error) {
+
+	fcs.mu.Lock()
+	defer fcs.mu.Unlock()
+
+	content, err := os.ReadFile(fcs.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file := &checkpointFile{}
+
+	err = json.Unmarshal(content, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Acknowledged {
+		return nil, nil
+	}
+
+	return file.Series, nil
+}
+
+// Acknowledge implements CheckpointStore.
+func (fcs *FileCheckpointStore) Acknowledge(ctx context.Context) error {
+
+	fcs.mu.Lock()
+	defer fcs.mu.Unlock()
+
+	return fcs.write(&checkpointFile{
+		Acknowledged: true,
+	})
+}
+
+// write atomically replaces the file with the provided content (see
+// writeFileAtomic).
+func (fcs *FileCheckpointStore) write(file *checkpointFile) error {
+
+	content, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(fcs.path, content)
+}
+
+// writeFileAtomic replaces the file at path with the provided content, by writing
+// to a temporary file and renaming it.
+func writeFileAtomic(path string, content []byte) error {
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(content)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,273 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileCheckpointStore(t *testing.T) {
+
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+
+	// missing file has nothing pending
+	pending, err := store.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, pending)
+
+	series := []*Series{
+		{
+			Metric: &Metric{Name: "invoices", Labels: map[string]string{"plan": "pro"}},
+			Points: []*Point{
+				{Start: time.Unix(1670681770, 0).UTC(), End: time.Unix(1670681780, 0).UTC(), Count: 3},
+			},
+		},
+	}
+
+	assert.NoError(t, store.Save(context.Background(), series))
+
+	pending, err = store.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, series, pending)
+
+	assert.NoError(t, store.Acknowledge(context.Background()))
+
+	pending, err = store.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, pending)
+}
+
+func TestQuantifier_report_checkpoint(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+
+	client := &Quantifier{
+		clock:           mockClock,
+		exporter:        exporter,
+		checkpointStore: store,
+		errorHandler:    func(q *Quantifier, err error) {},
+	}
+
+	counter, err := client.CreateCounter("invoices", nil, 10)
+	assert.NoError(t, err)
+
+	// failed exports are retained
+	exporter.exportErr = errors.New("export failed")
+	counter.Count()
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	pending, err := store.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	// and retried with the next report
+	exporter.exportErr = nil
+	exporter.series = nil
+	counter.Count()
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	assert.Len(t, exporter.series, 1)
+	assert.Len(t, exporter.series[0].Points, 2)
+
+	pending, err = store.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, pending)
+}
+
+func TestQuantifier_report_checkpointAttempts(t *testing.T) {
+
+	tests := []struct {
+		name            string
+		maxAttempts     int
+		expectedPending int
+		expectedDropped int
+	}{
+		{
+			name:            "unlimited",
+			expectedPending: 1,
+		},
+		{
+			name:            "limited",
+			maxAttempts:     3,
+			expectedDropped: 1,
+		},
+	}
+
+	for _, test := range tests {
+
+		mockClock := newMockClock()
+		mockClock.Set(time.Unix(1670681770, 0))
+
+		exporter := &mockExporter{exportErr: errors.New("export failed")}
+
+		client := &Quantifier{
+			clock:                 mockClock,
+			exporter:              exporter,
+			checkpointStore:       NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json")),
+			checkpointMaxAttempts: test.maxAttempts,
+			errorHandler:          func(q *Quantifier, err error) {},
+		}
+
+		counter, err := client.CreateCounter("invoices", nil, 10)
+		assert.NoError(t, err, "%s failed", test.name)
+
+		counter.Count()
+		mockClock.Add(time.Second * 10)
+
+		for i := 0; i < 3; i++ {
+			client.report(false)
+		}
+		assert.Len(t, client.pending, 1, "%s failed", test.name)
+
+		// pending series are only dropped once a limit of attempts is exhausted
+		client.report(false)
+		assert.Len(t, client.pending, test.expectedPending, "%s failed", test.name)
+
+		dropped := 0
+		if client.outage.gap != nil {
+			dropped = client.outage.gap.PointsDropped
+		}
+		assert.Equal(t, test.expectedDropped, dropped, "%s failed", test.name)
+	}
+}
+
+func TestQuantifier_report_checkpointSaveFailure(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+	saveErr := errors.New("save failed")
+	store := &mockCheckpointStore{saveErr: saveErr}
+
+	var handled []error
+
+	client := &Quantifier{
+		clock:                 mockClock,
+		exporter:              exporter,
+		checkpointStore:       store,
+		checkpointMaxAttempts: 1,
+		errorHandler: func(q *Quantifier, err error) {
+			handled = append(handled, err)
+		},
+	}
+
+	counter, err := client.CreateCounter("invoices", nil, 10)
+	assert.NoError(t, err)
+
+	// series that can't be saved aren't exported, and remain pending without
+	// using an attempt
+	for i := 0; i < 2; i++ {
+		counter.Count()
+		mockClock.Add(time.Second * 10)
+
+		err = client.report(false)
+		assert.ErrorIs(t, err, saveErr)
+	}
+
+	assert.Nil(t, exporter.series)
+	assert.Len(t, client.pending, 1)
+	assert.Len(t, client.pending[0].Points, 2)
+	assert.Equal(t, 0, client.pendingAttempts)
+	assert.Equal(t, []error{saveErr, saveErr}, handled)
+
+	// and are exported once they've been saved
+	store.saveErr = nil
+	client.report(false)
+
+	assert.Len(t, exporter.series, 1)
+	assert.Len(t, exporter.series[0].Points, 2)
+	assert.Nil(t, client.pending)
+	assert.Len(t, store.saved, 1)
+	assert.True(t, store.acknowledged)
+}
+
+// mockCheckpointStore implements CheckpointStore, failing to save whilst saveErr
+// is set.
+type mockCheckpointStore struct {
+	saveErr      error
+	saved        []*Series
+	acknowledged bool
+}
+
+func (mcs *mockCheckpointStore) Save(ctx context.Context, series []*Series) error {
+
+	if mcs.saveErr != nil {
+		return mcs.saveErr
+	}
+
+	mcs.saved = series
+	mcs.acknowledged = false
+
+	return nil
+}
+
+func (mcs *mockCheckpointStore) Load(ctx context.Context) ([]*Series, error) {
+	return nil, nil
+}
+
+func (mcs *mockCheckpointStore) Acknowledge(ctx context.Context) error {
+	mcs.acknowledged = true
+	return nil
+}
+
+func TestQuantifier_report_checkpointDistribution(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+
+	client := &Quantifier{
+		clock:           mockClock,
+		exporter:        exporter,
+		checkpointStore: store,
+		errorHandler:    func(q *Quantifier, err error) {},
+	}
+
+	latency, err := client.CreateDistribution("latency", nil, 10, ExplicitBuckets(10))
+	assert.NoError(t, err)
+
+	// a failed export is retained
+	exporter.exportErr = errors.New("export failed")
+	latency.Record(5)
+	latency.Record(15)
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	// and merged with the next report, keeping the distributions of both
+	exporter.exportErr = nil
+	exporter.series = nil
+	latency.Record(7)
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	if assert.Len(t, exporter.series, 1) && assert.Len(t, exporter.series[0].Points, 2) {
+
+		first := exporter.series[0].Points[0].Distribution
+		if assert.NotNil(t, first) {
+			assert.Equal(t, int64(2), first.Count)
+			assert.Equal(t, float64(10), first.Mean)
+			assert.Equal(t, []int64{1, 1}, first.BucketCounts)
+		}
+
+		second := exporter.series[0].Points[1].Distribution
+		if assert.NotNil(t, second) {
+			assert.Equal(t, int64(1), second.Count)
+			assert.Equal(t, []int64{1, 0}, second.BucketCounts)
+		}
+	}
+}
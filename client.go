@@ -1,107 +1,263 @@
-// Package quantify provides a simplified set of tools for reporting custom
-// metrics to Google Cloud Metrics.
+// Package quantify provides a simplified set of tools for recording custom
+// metrics and periodically pushing them to an Exporter, such as Google Cloud
+// Monitoring (see the gcms subpackage).
 package quantify
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"path"
+	"io"
+	"runtime"
 	"sync"
 	"time"
-
-	monitoring "cloud.google.com/go/monitoring/apiv3"
-	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
-	"github.com/benbjohnson/clock"
-	metricpb "google.golang.org/genproto/googleapis/api/metric"
-	"google.golang.org/genproto/googleapis/api/monitoredres"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
-	// see https://cloud.google.com/monitoring/api/metrics_gcp for more info on
-	// metric roots.
-	//
-	// as this client is designed for custom metrics, this root is non-configurable
-	// (see https://cloud.google.com/monitoring/custom-metrics#identifier).
-	customMetricRoot = "custom.googleapis.com"
-
 	defaultRefreshInterval = time.Minute
 
-	resourceLabelKeyProjectId = "project_id"
+	// parallelCollectionThreshold is the number of counters above which points are
+	// taken from counters concurrently during a report.
+	parallelCollectionThreshold = 64
+
+	// resumeThreshold is the number of refresh intervals that must have passed
+	// since the previous report for a tick to be treated as resuming from a pause
+	// (e.g. laptop suspend), in which case the backlog is reported in chunks of
+	// backlogChunkSize intervals.
+	resumeThreshold  = 3
+	backlogChunkSize = 10
 
-	projectPathPrefix = "projects"
+	// closeCheckInterval is how often intervals are checked for having closed when
+	// publishing on close (see OptionWithPublishOnClose).
+	closeCheckInterval = time.Second
+)
+
+var (
+	ErrNoExporter     = errors.New("no exporter provided")
+	ErrUnknownCounter = errors.New("counter wasn't created by this quantifier")
+
+	ErrNotSynchronous = errors.New("quantifier isn't synchronous")
+
+	ErrDuplicateOption    = errors.New("option provided more than once")
+	ErrConflictingOptions = errors.New("conflicting options provided")
+
+	ErrCumulativeTotalsUnsupported = errors.New("exporter doesn't support cumulative totals")
+
+	ErrIntervalTooShort     = errors.New("interval is shorter than the exporter accepts")
+	ErrIntervalBelowRefresh = errors.New("counter interval is shorter than the refresh interval")
 )
 
 // metricCounter defines a wrapper around the Counter unit, tethering it to
 // a Metric config.
 type metricCounter struct {
-	metric  *metricpb.Metric
+	metric  *Metric
 	counter *Counter
+
+	// observers are called with the points taken from the counter on each report.
+	observers []func([]*Point)
+
+	// start and total track the cumulative epoch and running total of the counter
+	// when reporting cumulative totals (see OptionWithCumulativeTotals).
+	start time.Time
+	total int64
+
+	// published is set once points of the counter have been exported.
+	published bool
 }
 
-// Quantifier implements a client that reports user defined metrics to Google
-// Cloud Monitoring.
+// accumulate converts the provided interval points into running totals since the
+// start of the counter's cumulative epoch. The epoch is established by the start
+// of the first point reported by the process, so that a restarted process begins
+// a new epoch (which Google Cloud Monitoring treats as a reset).
+func (mc *metricCounter) accumulate(points []*Point) []*Point {
+
+	if mc.start.IsZero() && len(points) > 0 {
+		mc.start = points[0].Start
+	}
+
+	accumulated := make([]*Point, 0, len(points))
+
+	for _, point := range points {
+
+		mc.total += point.Count
+
+		accumulated = append(accumulated, &Point{
+			Start: mc.start,
+			End:   point.End,
+			Count: mc.total,
+		})
+	}
+
+	return accumulated
+}
+
+// counterSource is implemented by instruments that create counters of their own
+// whilst running, which are reported alongside those of the Quantifier.
+type counterSource interface {
+
+	// metricCounters returns the counters of the instrument.
+	metricCounters() []*metricCounter
+}
+
+// idleEvicter is implemented by counter sources that remove series once they're
+// idle (see MetricOptionWithIdleTTL).
+type idleEvicter interface {
+
+	// evictIdle removes the counters of any idle series, returning them so that
+	// their remaining points can be reported.
+	evictIdle(now time.Time) []*metricCounter
+}
+
+// recorder is implemented by instruments, other than Counters, that record values
+// into intervals, with their points taken alongside those of the counters.
+type recorder interface {
+
+	// recordedMetric returns the metric of the instrument.
+	recordedMetric() *Metric
+
+	// takePoints takes the points of the instrument (see Counter.takePoints).
+	takePoints(current bool, limit int) []*Point
+
+	// memoryUsage returns the approximate number of bytes held by the instrument
+	// for intervals yet to be reported.
+	memoryUsage() int64
+}
+
+// collector is implemented by instruments whose series are derived at report
+// time, rather than recorded by a Counter.
+type collector interface {
+
+	// collect returns the series of the instrument at the provided time.
+	collect(now time.Time) []*Series
+}
+
+// Quantifier implements a client that periodically reports user defined metrics
+// to an Exporter.
 type Quantifier struct {
-	ctx             context.Context
-	clock           clock.Clock
-	mu              *sync.Mutex
-	stop            chan struct{}
-	stopped         chan struct{}
-	running         bool
-	resourceName    string
-	resourceLabels  map[string]string
-	client          *monitoring.MetricClient
-	counters        []*metricCounter
-	errorHandler    func(*Quantifier, error)
-	refreshInterval time.Duration
+	ctx                   context.Context
+	clock                 Clock
+	mu                    *sync.Mutex
+	stop                  chan struct{}
+	stopped               chan struct{}
+	running               bool
+	exporter              Exporter
+	counters              []*metricCounter
+	collectors            []collector
+	recorders             []recorder
+	sources               []counterSource
+	pollers               []poller
+	errorHandler          func(*Quantifier, error)
+	namePolicy            func(string) error
+	refreshInterval       time.Duration
+	maxPoints             int
+	gapHandler            func(*Quantifier, *Gap)
+	outage                outage
+	commonLabels          map[string]string
+	countLatency          *latencyRecorder
+	sampleAbove           int64
+	reportingDelay        time.Duration
+	compactor             *compactor
+	cumulative            bool
+	checkpointStore       CheckpointStore
+	pending               []*Series
+	pendingAttempts       int
+	checkpointMaxAttempts int
+	enableIf              func() bool
+	lastReportSize        int
+	synchronous           bool
+	bestEffort            bool
+	lastReport            time.Time
+	hooks                 Hooks
+	catalogPath           string
+	catalogSize           int
+	publishOnClose        bool
+	memoryLimit           int64
+	truncate              bool
+	lifecycle             *lifecycle
+	final                 bool
+
+	// intervals are the distinct intervals (in seconds) of the Quantifier's
+	// instruments, and nextClose the time the next of them closes, after the
+	// reporting delay (see OptionWithPublishOnClose).
+	intervalsMu sync.Mutex
+	intervals   map[int64]bool
+	nextClose   time.Time
+
+	// countersMu guards counters, and deleted, the counters whose remaining points
+	// are reported with the next refresh (see DeleteCounter), as counters may be
+	// created and deleted whilst reports are running.
+	countersMu sync.Mutex
+	deleted    []*metricCounter
+
+	// finalOnce ensures the final report is only made once (see finalReport).
+	finalOnce sync.Once
+
+	// closeOnce and closeErr ensure the Exporter is only closed once (see Stop).
+	closeOnce sync.Once
+	closeErr  error
+
+	// reported tracks the errors of instruments already passed to the error handler
+	// (see OptionWithBestEffortInstruments).
+	reported map[string]bool
+
+	// configured tracks the options that have been applied (see configure).
+	configured map[string]bool
 }
 
 // New returns an instantiated Quantifier, or returns an error if instantiation
 // fails.
 //
 // options allow the user to provide custom configurations as a list of Options.
+// An Exporter must be provided with OptionWithExporter. If any options are
+// invalid, a ValidationErrors describing every problem is returned.
 func New(ctx context.Context, options ...Option) (*Quantifier, error) {
 
 	// build Quantifier
 	quantifier := &Quantifier{
 		ctx:             ctx,
-		clock:           clock.New(),
+		clock:           systemClock{},
 		mu:              &sync.Mutex{},
 		stopped:         make(chan struct{}),
 		refreshInterval: defaultRefreshInterval,
 	}
 
+	// apply every option, so that all problems are reported together
+	var errs ValidationErrors
+
 	for _, option := range options {
 		err := option(quantifier)
 		if err != nil {
-			return nil, err
+			errs = append(errs, err)
 		}
 	}
 
-	// if quantifier.client isn't supplied with options
-	if quantifier.client == nil {
-
-		client, err := monitoring.NewMetricClient(ctx)
-		if err != nil {
-			return nil, err
-		}
-
-		quantifier.client = client
+	// when disabled, counts are still taken each refresh, but are discarded
+	if quantifier.enableIf != nil && !quantifier.enableIf() {
+		quantifier.exporter = discardExporter{}
 	}
 
-	// if quantifier.resource isn't supplied with options
-	if quantifier.resourceName == "" || quantifier.resourceLabels == nil {
+	if quantifier.exporter == nil {
+		errs = append(errs, &FieldError{Path: "exporter", Err: ErrNoExporter})
+	}
 
-		// set to be global resource
-		option := OptionWithResourceType(&ResourceGlobal{
-			ProjectId: DetectProjectId(),
+	// series written more often than the exporter accepts are rejected, which
+	// synchronous quantifiers leave to the caller
+	minInterval := minWriteInterval(quantifier.exporter)
+	if !quantifier.synchronous && quantifier.refreshInterval < minInterval {
+		errs = append(errs, &FieldError{
+			Path: "refresh_interval",
+			Err:  fmt.Errorf("%w: %s is below the minimum of %s", ErrIntervalTooShort, quantifier.refreshInterval, minInterval),
 		})
+	}
 
-		// attempt to apply resource
-		err := option(quantifier)
-		if err != nil {
-			return nil, err
-		}
+	// running totals would be counted again with every report by backends that sum
+	// the points of counters
+	if quantifier.cumulative && sumsCounts(quantifier.exporter) {
+		errs = append(errs, &FieldError{Path: "cumulative_totals", Err: ErrCumulativeTotalsUnsupported})
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
 	}
 
 	// if quantifier.errorHandler isn't set
@@ -111,16 +267,54 @@ func New(ctx context.Context, options ...Option) (*Quantifier, error) {
 		quantifier.errorHandler = func(r *Quantifier, err error) {}
 	}
 
-	go quantifier.run()
+	// recover any series that weren't acknowledged before the last shutdown, to
+	// be exported with the first report
+	if quantifier.checkpointStore != nil {
+
+		pending, err := quantifier.checkpointStore.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		quantifier.pending = pending
+	}
+
+	if err := quantifier.startLifecycle(); err != nil {
+		return nil, err
+	}
+
+	// in synchronous mode, reports are driven by the caller (see Flush)
+	if !quantifier.synchronous {
+		quantifier.run()
+	}
 
 	return quantifier, nil
 }
 
-// run starts execution of the client providing it isn't already running. Whilst
-// running, it will attempt to push recorded data at the interval provided.
+// configure records that the option configuring the field at the provided path
+// has been applied, returning an error if it already has been, so that duplicate
+// options are caught rather than silently overriding each other.
+func (q *Quantifier) configure(path string) error {
+
+	if q.configured == nil {
+		q.configured = make(map[string]bool)
+	}
+
+	if q.configured[path] {
+		return &FieldError{Path: path, Err: ErrDuplicateOption}
+	}
+
+	q.configured[path] = true
+	return nil
+}
+
+// run starts execution of the client in the background providing it isn't already
+// running. Whilst running, it will attempt to push recorded data at the interval
+// provided.
 //
 // run also monitors stop signals and ctc cancelling to cease operations when
-// required.
+// required. The client is marked as running before run returns, so that a Stop
+// immediately afterwards can't miss the background goroutine and leak it.
 func (q *Quantifier) run() {
 
 	q.mu.Lock()
@@ -132,11 +326,128 @@ func (q *Quantifier) run() {
 
 	q.running = true
 	q.stop = make(chan struct{})
+	q.stopped = make(chan struct{})
 	q.mu.Unlock()
 
-	q.runTicker(q.clock.Ticker(q.refreshInterval), func() {
-		q.report(false)
-	})
+	if q.publishOnClose {
+		go q.runTicker(q.clock.NewTicker(closeCheckInterval), q.tickOnClose)
+		return
+	}
+
+	go q.runTicker(q.clock.NewTicker(q.refreshInterval), q.tick)
+}
+
+// tick reports on each tick of the refresh ticker. Ticks arriving in a burst (e.g.
+// as a process resumes from being paused) are collapsed into one report, and when
+// resuming after a long pause, the backlog is reported oldest first in chunks
+// rather than all at once.
+func (q *Quantifier) tick() {
+
+	if !q.lastReport.IsZero() {
+
+		elapsed := q.clock.Now().Sub(q.lastReport)
+
+		// already reported for this tick
+		if elapsed < q.refreshInterval/2 {
+			return
+		}
+
+		if elapsed >= q.refreshInterval*resumeThreshold {
+			q.reportBacklog()
+			return
+		}
+	}
+
+	q.report(false)
+}
+
+// tickOnClose reports on each tick of the close check ticker if the next interval
+// to close has closed, or the refresh interval has passed since the previous
+// report (see OptionWithPublishOnClose). Intervals of several instruments closing
+// at the same boundary are published together. Collectors and pollers are only
+// sampled with the reports of each refresh interval.
+func (q *Quantifier) tickOnClose() {
+
+	now := q.clock.Now()
+
+	if q.lastReport.IsZero() || now.Sub(q.lastReport) >= q.refreshInterval {
+		q.tick()
+		q.scheduleClose(now)
+		return
+	}
+
+	q.intervalsMu.Lock()
+	next := q.nextClose
+	q.intervalsMu.Unlock()
+
+	if next.IsZero() {
+		q.scheduleClose(now)
+		return
+	}
+
+	if now.Before(next) {
+		return
+	}
+
+	lastReport := q.lastReport
+
+	q.reportLimited(false, q.maxPoints, false)
+
+	// refreshes remain relative to the previous refresh
+	q.lastReport = lastReport
+
+	q.scheduleClose(now)
+}
+
+// scheduleClose sets the time that the next interval of any of the Quantifier's
+// instruments closes after the provided time, once the reporting delay has passed.
+func (q *Quantifier) scheduleClose(now time.Time) {
+
+	q.intervalsMu.Lock()
+	defer q.intervalsMu.Unlock()
+
+	q.nextClose = time.Time{}
+
+	for interval := range q.intervals {
+
+		if end := q.closeAfter(now, interval); q.nextClose.IsZero() || end.Before(q.nextClose) {
+			q.nextClose = end
+		}
+	}
+}
+
+// closeAfter returns the time that the next interval of the provided length (in
+// seconds) closes after the provided time, once the reporting delay has passed.
+func (q *Quantifier) closeAfter(now time.Time, interval int64) time.Time {
+
+	length := time.Second * time.Duration(interval)
+
+	return now.Add(-q.reportingDelay).Truncate(length).Add(length).Add(q.reportingDelay)
+}
+
+// reportBacklog reports all completed intervals in chunks of backlogChunkSize
+// intervals per counter, oldest first. Collectors and pollers are only sampled
+// with the first chunk.
+func (q *Quantifier) reportBacklog() {
+
+	limit := backlogChunkSize
+	if q.maxPoints > 0 && q.maxPoints < limit {
+		limit = q.maxPoints
+	}
+
+	// bound the chunks to the configured maximum points per flush, if any
+	chunks := -1
+	if q.maxPoints > 0 {
+		chunks = (q.maxPoints + limit - 1) / limit
+	}
+
+	for sample := true; chunks != 0; sample, chunks = false, chunks-1 {
+
+		taken, err := q.reportLimited(false, limit, sample)
+		if err != nil || taken == 0 {
+			return
+		}
+	}
 }
 
 // runTicker starts a blocking operation that will call the provided function (fn)
@@ -144,31 +455,36 @@ func (q *Quantifier) run() {
 //
 // The function will cease when a stop signal is received (Quantifier.Stop) or when
 // the Quantifier.ctx is cancelled.
-func (q *Quantifier) runTicker(t *clock.Ticker, fn func()) {
+func (q *Quantifier) runTicker(t Ticker, fn func()) {
 
-	stop := func() {
-		q.mu.Lock()
-		q.running = false
-		close(q.stop)
-		q.stopped <- struct{}{}
-		q.mu.Unlock()
-	}
+	// stopped is closed once the final report of a cancelled context has been
+	// made, so that terminate can't return whilst it's in progress
+	stopped := q.stopped
+	defer close(stopped)
+	defer t.Stop()
 
 	for {
 		select {
 
 		// when interval passes, send data
-		case <-t.C:
+		case <-t.C():
 			fn()
 
-		// when context cancelled, exit immediately
+		// when context cancelled, exit immediately, flushing only to report the
+		// shutdown when lifecycle metrics are enabled
 		case <-q.ctx.Done():
-			stop()
+
+			if q.lifecycle != nil {
+				q.finalReport(ShutdownReasonContextCancelled)
+			}
+
+			q.mu.Lock()
+			q.running = false
+			q.mu.Unlock()
 			return
 
 		// when stop requested, stop gracefully
 		case <-q.stop:
-			stop()
 			return
 
 		}
@@ -182,91 +498,519 @@ func (q *Quantifier) runTicker(t *clock.Ticker, fn func()) {
 // words, what level of precision is required when tracking cumulative
 // amounts. This value represents seconds.
 //
-// CreateCounter will return an error if the provided name does not match
-// Google's Metric_Type specification, or if any of the provided label keys
-// under the labels parameter do not match Google's requirements. Refer to
-// this link for more information:
-// https://cloud.google.com/monitoring/api/v3/naming-conventions
-func (q *Quantifier) CreateCounter(name string, labels map[string]string, interval int64) (*Counter, error) {
+// options allow optional metadata, such as a display name, to be provided as a
+// list of MetricOptions.
+//
+// CreateCounter will return an error if the provided name is rejected by the
+// naming policy (see OptionWithNamePolicy), or if the Quantifier's Exporter
+// implements MetricValidator and rejects the provided name or labels.
+func (q *Quantifier) CreateCounter(name string, labels map[string]string, interval int64, options ...MetricOption) (*Counter, error) {
 
-	if !isMetricTypeValid(name) {
-		return nil, fmt.Errorf("invalid name parameter provided")
+	counter, err := q.createCounter(nil, name, labels, interval, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopCounter(), nil
 	}
 
-	for key := range labels {
-		if !isMetricLabelKeyValid(key) {
-			return nil, fmt.Errorf("invalid label key provided: %s", key)
-		}
+	return counter, err
+}
+
+// CreateChildCounter creates a Counter (see CreateCounter) that rolls up into the
+// provided parent Counter, so that each count of the child also counts towards the
+// parent, and any of the parent's own ancestors. Each Counter is published as its
+// own series, for example allowing per-endpoint counters to roll up into a service
+// total without counting twice at each call site.
+//
+// parent must have been created by this Quantifier.
+func (q *Quantifier) CreateChildCounter(parent *Counter, name string, labels map[string]string, interval int64, options ...MetricOption) (*Counter, error) {
+
+	var counter *Counter
+	var err error
+
+	if q.findCounter(parent) == nil {
+		err = ErrUnknownCounter
+	} else {
+		counter, err = q.createCounter(parent, name, labels, interval, options...)
+	}
+
+	if err != nil && q.noopInstead(err) {
+		return newNoopCounter(), nil
 	}
 
-	counter, err := newCounter(interval)
+	return counter, err
+}
+
+// createCounter creates and registers a Counter with an optional parent.
+func (q *Quantifier) createCounter(parent *Counter, name string, labels map[string]string, interval int64, options ...MetricOption) (*Counter, error) {
+
+	mc, err := q.newMetricCounter(parent, name, labels, interval, options...)
 	if err != nil {
 		return nil, err
 	}
 
-	mc := &metricCounter{
-		metric: &metricpb.Metric{
-			Type:   path.Join(customMetricRoot, name),
-			Labels: labels,
-		},
+	q.registerCounter(mc)
+	return mc.counter, nil
+}
+
+// registerCounter registers the provided counter to be reported.
+func (q *Quantifier) registerCounter(mc *metricCounter) {
+
+	q.countersMu.Lock()
+	defer q.countersMu.Unlock()
+
+	q.counters = append(q.counters, mc)
+}
+
+// registeredCounters returns the counters registered to be reported. The returned
+// slice is a snapshot, which is safe to use whilst counters are created or deleted.
+func (q *Quantifier) registeredCounters() []*metricCounter {
+
+	q.countersMu.Lock()
+	defer q.countersMu.Unlock()
+
+	return q.counters[:len(q.counters):len(q.counters)]
+}
+
+// newMetricCounter creates a Counter with an optional parent, tethered to its Metric,
+// without registering it to be reported.
+func (q *Quantifier) newMetricCounter(parent *Counter, name string, labels map[string]string, interval int64, options ...MetricOption) (*metricCounter, error) {
+
+	metric := &Metric{
+		Name:   name,
+		Labels: q.mergeCommonLabels(labels),
+	}
+
+	for _, option := range options {
+		option(metric)
+	}
+
+	err := q.validateMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	counter, err := newCounter(interval, q.clock)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.validateInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	counter.latency = q.countLatency
+	counter.parent = parent
+	counter.delay = q.reportingDelay
+	counter.unit = metric.Unit
+
+	if q.sampleAbove > 0 {
+		counter.sampler = newAdaptiveSampler(q.sampleAbove)
+	}
+
+	if metric.striped {
+		counter.stripes = newStripes(runtime.GOMAXPROCS(0))
+	}
+
+	if metric.bufferSize > 0 {
+		counter.startBuffer(metric.bufferSize)
+	}
+
+	if q.hooks.OnCreate != nil {
+		q.hooks.OnCreate(metric)
+	}
+
+	return &metricCounter{
+		metric:  metric,
 		counter: counter,
+	}, nil
+}
+
+// validateInterval checks the provided counter interval (in seconds) against the
+// minimum write interval of the Exporter (see WriteIntervalLimiter). When reporting
+// cumulative totals, several points of a series can be written in a single report
+// if the interval is shorter than the refresh interval, which exporters may
+// reject, so a warning is passed to the error handler.
+//
+// When publishing on close, valid intervals are tracked so that their close can be
+// published (see OptionWithPublishOnClose).
+func (q *Quantifier) validateInterval(interval int64) error {
+
+	duration := time.Duration(interval) * time.Second
+
+	if minInterval := minWriteInterval(q.exporter); duration < minInterval {
+		return &FieldError{
+			Path: "interval",
+			Err:  fmt.Errorf("%w: %s is below the minimum of %s", ErrIntervalTooShort, duration, minInterval),
+		}
 	}
 
-	q.counters = append(q.counters, mc)
-	return mc.counter, nil
+	if q.cumulative && !q.synchronous && duration < q.refreshInterval && q.errorHandler != nil {
+		q.errorHandler(q, &FieldError{
+			Path: "interval",
+			Err:  fmt.Errorf("%w: %s is below %s", ErrIntervalBelowRefresh, duration, q.refreshInterval),
+		})
+	}
+
+	// track the intervals in use, so that their close can be published
+	if q.publishOnClose {
+		q.intervalsMu.Lock()
+		if q.intervals == nil {
+			q.intervals = make(map[int64]bool)
+		}
+		q.intervals[interval] = true
+
+		// the new interval may close before the one scheduled
+		if end := q.closeAfter(q.clock.Now(), interval); q.nextClose.IsZero() || end.Before(q.nextClose) {
+			q.nextClose = end
+		}
+		q.intervalsMu.Unlock()
+	}
+
+	return nil
+}
+
+// validateMetric checks the provided metric against the naming policy (see
+// OptionWithNamePolicy) and, if the Exporter implements MetricValidator, the
+// Exporter's own rules. With OptionWithTruncation, over-long names and labels of the
+// metric are truncated before it's checked.
+func (q *Quantifier) validateMetric(metric *Metric) error {
+
+	if q.truncate {
+		q.truncateMetric(metric)
+	}
+
+	if q.namePolicy != nil {
+		err := q.namePolicy(metric.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	if validator, ok := q.exporter.(MetricValidator); ok {
+		err := validator.ValidateMetric(metric)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stats returns information about the internal operation of the Quantifier.
+func (q *Quantifier) Stats() *Stats {
+
+	stats := &Stats{
+		MemoryBytes: q.memoryUsage(),
+	}
+
+	if q.countLatency != nil {
+		stats.CountLatency = q.countLatency.snapshot()
+	}
+
+	return stats
+}
+
+// mergeCommonLabels returns the provided labels combined with any labels that are
+// common to all metrics of the Quantifier (see OptionWithInstanceLabels). Labels
+// provided by the caller take precedence.
+func (q *Quantifier) mergeCommonLabels(labels map[string]string) map[string]string {
+
+	if len(q.commonLabels) == 0 {
+		return labels
+	}
+
+	merged := make(map[string]string, len(labels)+len(q.commonLabels))
+
+	for key, value := range q.commonLabels {
+		merged[key] = value
+	}
+
+	for key, value := range labels {
+		merged[key] = value
+	}
+
+	return merged
 }
 
 // report flushes any metrics that can only be reported periodically,
-// like counters.
+// like counters, along with the current series of any collectors. Any
+// pollers (e.g. derived counters) are sampled first.
 //
 // current is used to specify the inclusion of any current intervals
-// within the tracked counters.
-func (q *Quantifier) report(current bool) {
+// within the tracked counters. When current is set, all outstanding points
+// are reported regardless of the configured maximum points per flush.
+//
+// Any error returned by the Exporter is passed to the error handler, and also
+// returned.
+func (q *Quantifier) report(current bool) error {
+
+	limit := q.maxPoints
+	if current {
+		limit = 0
+	}
+
+	_, err := q.reportLimited(current, limit, true)
+	return err
+}
 
-	// each request must only have one point per counter, this multidimensional array
-	// tracks a single point from each counter as multiple points can be submitted as
-	// long as they are from different counters.
-	series := make([][]*monitoringpb.TimeSeries, 0)
+// reportLimited reports up to limit points of each counter (see report), returning
+// the number of counter points taken. Pollers and collectors are only sampled when
+// sample is set.
+func (q *Quantifier) reportLimited(current bool, limit int, sample bool) (int, error) {
 
-	for _, mc := range q.counters {
+	now := q.clock.Now()
+	q.lastReport = now
 
-		pointCount := 0
+	// the backlog left after the report is what's held in memory
+	defer q.enforceMemoryLimit(now)
 
-		// generate request
-		for _, point := range mc.counter.takePoints(current) {
+	q.writeCatalog()
 
-			// if series[pointCount] is out of bounds
-			if len(series) <= pointCount {
-				series = append(series, make([]*monitoringpb.TimeSeries, 0))
+	if sample {
+		for _, p := range q.pollers {
+			p.poll(now)
+		}
+	}
+
+	// idle series are removed, with their remaining points reported as deleted
+	for _, source := range q.sources {
+		if evicter, ok := source.(idleEvicter); ok {
+			for _, mc := range evicter.evictIdle(now) {
+				q.retireCounter(mc)
 			}
+		}
+	}
 
-			// split points out so only on point per metric per request
-			series[pointCount] = append(series[pointCount], q.createTimeSeriesProto(mc.metric, countToMetricPointProto(point)))
-			pointCount++
+	counters := q.registeredCounters()
+	for _, source := range q.sources {
+		counters = append(counters[:len(counters):len(counters)], source.metricCounters()...)
+	}
+
+	// size for the previous report, which is typically the same as this one
+	series := make([]*Series, 0, q.lastReportSize)
+
+	taken := takeAllPoints(counters, current, limit)
+	alignGroups(counters, taken)
+	total := 0
+
+	// unpublished are the counters publishing points for the first time
+	var unpublished []*metricCounter
+
+	for i, mc := range counters {
+
+		points := taken[i]
+		if len(points) == 0 {
+			continue
 		}
+
+		total += len(points)
+
+		if !mc.published {
+			unpublished = append(unpublished, mc)
+		}
+
+		for _, observe := range mc.observers {
+			observe(points)
+		}
+
+		if q.cumulative && mc.metric.Kind != MetricKindDelta {
+			points = mc.accumulate(points)
+		}
+
+		series = append(series, &Series{
+			Metric: mc.metric,
+			Points: points,
+		})
+	}
+
+	for _, r := range q.recorders {
+
+		points := r.takePoints(current, limit)
+		if len(points) == 0 {
+			continue
+		}
+
+		total += len(points)
+
+		series = append(series, &Series{
+			Metric: r.recordedMetric(),
+			Points: points,
+		})
+	}
+
+	if sample {
+		for _, c := range q.collectors {
+			series = append(series, c.collect(now)...)
+		}
+	}
+
+	if q.final {
+		applyShutdownValues(series)
+	}
+
+	series = append(series, q.takeDeleted()...)
+
+	q.lastReportSize = len(series)
+
+	if q.compactor != nil {
+		series = q.compactor.compact(series)
 	}
 
-	// send requests
-	for _, series := range series {
-		err := q.client.CreateTimeSeries(context.Background(), q.createCreateTimeSeriesRequestProto(series))
+	if len(series) == 0 && len(q.pending) == 0 {
+		return total, nil
+	}
+
+	if q.checkpointStore != nil {
+
+		var err error
+
+		series, err = q.checkpoint(context.Background(), now, series)
 		if err != nil {
 			q.errorHandler(q, err)
+			return total, err
+		}
+		if len(series) == 0 {
+			return total, nil
+		}
+	}
+
+	err := q.exporter.Export(context.Background(), series)
+	if err != nil {
+
+		// checkpointed series are retried with the next report, so aren't dropped
+		// unless their attempts are exhausted
+		if q.checkpointStore == nil {
+			q.outage.recordFailure(now, series)
+		}
+
+		q.errorHandler(q, err)
+		return total, err
+	}
+
+	if q.checkpointStore != nil {
+		q.acknowledge(context.Background())
+	}
+
+	if gap := q.outage.recover(now); gap != nil && q.gapHandler != nil {
+		q.gapHandler(q, gap)
+	}
+
+	for _, mc := range unpublished {
+
+		mc.published = true
+
+		if q.hooks.OnFirstPublish != nil {
+			q.hooks.OnFirstPublish(mc.metric)
+		}
+	}
+
+	return total, nil
+}
+
+// takeAllPoints takes the points of each of the provided counters (see
+// Counter.takePoints), returning them in the same order as the counters. When there
+// are many counters, they're taken concurrently by a worker per CPU, so that the
+// points of the last counters aren't skewed by the time taken to walk the rest.
+func takeAllPoints(counters []*metricCounter, current bool, limit int) [][]*Point {
+
+	taken := make([][]*Point, len(counters))
+
+	// buffered children may count towards the parents of other counters, so all
+	// buffers are flushed before any points are taken
+	for _, mc := range counters {
+		mc.counter.flushBuffer()
+	}
+
+	if len(counters) <= parallelCollectionThreshold {
+		for i, mc := range counters {
+			taken[i] = mc.counter.takePoints(current, mc.limit(limit))
 		}
+		return taken
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	indexes := make(chan int, len(counters))
+
+	for i := range counters {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				taken[i] = counters[i].counter.takePoints(current, counters[i].limit(limit))
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return taken
+}
+
+// Flush reports all completed intervals, along with the current series of any
+// collectors, returning any error returned by the Exporter. The current interval
+// of each counter is reported by Stop once counting has finished.
+//
+// Flush may only be called on a Quantifier created with OptionSynchronous, whose
+// reports are driven by the caller, otherwise ErrNotSynchronous is returned.
+func (q *Quantifier) Flush() error {
+
+	if !q.synchronous {
+		return ErrNotSynchronous
 	}
+
+	return q.report(false)
 }
 
 // Stop can be used to gracefully terminate the Quantifier client. It will attempt
 // to push any remaining data that has already been recorded, and then cease
 // internal operations.
 //
+// Once the remaining data has been pushed, the Exporter is closed if it implements
+// io.Closer, releasing resources such as the API client. Exporters only close
+// resources they own (e.g. gcms doesn't close a client supplied to it), and an
+// Exporter shared between Quantifiers should be wrapped in a SharedExporter. Any
+// error from closing the Exporter is passed to the error handler.
+//
 // Note: calling count on any of Quantifier's child counters after this call is made
 // won't result in reported metrics as Quantifier will have ceased operations.
 func (q *Quantifier) Stop() {
 
 	q.terminate()
 
-	// flush any remaining counts
-	q.report(true)
+	q.finalReport(ShutdownReasonStop)
+
+	// counting has finished, so the goroutines of buffered counters can exit
+	q.stopBuffers()
+
+	q.closeOnce.Do(func() {
+
+		closer, ok := q.exporter.(io.Closer)
+		if !ok {
+			return
+		}
+
+		q.closeErr = closer.Close()
+		if q.closeErr != nil && q.errorHandler != nil {
+			q.errorHandler(q, q.closeErr)
+		}
+	})
+}
+
+// Close implements io.Closer, stopping the Quantifier (see Stop). Close is safe to
+// call multiple times, returning any error from closing the Exporter each time.
+func (q *Quantifier) Close() error {
+
+	q.Stop()
+
+	return q.closeErr
 }
 
 // terminate is the underlying close function used when the client needs to be stopped.
@@ -279,64 +1023,24 @@ func (q *Quantifier) terminate() {
 	}
 
 	// signal stop
-	q.stop <- struct{}{}
+	q.running = false
+	close(q.stop)
+	stopped := q.stopped
 	q.mu.Unlock()
 
 	// wait for stopped
-	<-q.stopped
-
+	<-stopped
 }
 
-// countToMetricPointProto converts a count into a monitoringpb.Point.
-//
-// note: the duration between the start and end times must be greater than
-// 2 milliseconds for a valid Point as countToMetricPointProto will take 1
-// millisecond from the end time.
-func countToMetricPointProto(count *count) *monitoringpb.Point {
-	return &monitoringpb.Point{
-		Interval: &monitoringpb.TimeInterval{
-			StartTime: timestamppb.New(count.start),
-
-			// minus millisecond because: "The new start time must be at least a
-			// millisecond after the end time of the previous interval."
-			EndTime: timestamppb.New(count.end.Add(time.Millisecond * -1)),
-		},
-		Value: &monitoringpb.TypedValue{
-			Value: &monitoringpb.TypedValue_Int64Value{
-				Int64Value: count.count,
-			},
-		},
-	}
-}
-
-// getGcpProjectPath takes a project id and returns the expected GCP project path.
-func getGcpProjectPath(projectId string) string {
-	return path.Join(projectPathPrefix, projectId)
-}
-
-// createTimeSeriesProto compiles a list of monitoringpb.TimeSeries protos
-// (one per provided point) that can be submitted to Google Cloud Monitoring
-// within a monitoringpb.CreateTimeSeriesRequest.
-func (q *Quantifier) createTimeSeriesProto(metric *metricpb.Metric, point *monitoringpb.Point) *monitoringpb.TimeSeries {
-
-	return &monitoringpb.TimeSeries{
-		Metric:     metric,
-		MetricKind: metricpb.MetricDescriptor_CUMULATIVE,
-		Resource: &monitoredres.MonitoredResource{
-			Type:   q.resourceName,
-			Labels: q.resourceLabels,
-		},
-		Points: []*monitoringpb.Point{
-			point,
-		},
-	}
-}
-
-// createCreateTimeSeriesRequestProto compiles a monitoringpb.CreateTimeSeriesRequest proto
-// within the Quantifiers project scope with the provided []*monitoringpb.TimeSeries.
-func (q *Quantifier) createCreateTimeSeriesRequestProto(series []*monitoringpb.TimeSeries) *monitoringpb.CreateTimeSeriesRequest {
-	return &monitoringpb.CreateTimeSeriesRequest{
-		Name:       getGcpProjectPath(q.resourceLabels[resourceLabelKeyProjectId]),
-		TimeSeries: series,
-	}
+// finalReport counts the shutdown for the provided reason, if lifecycle metrics
+// are enabled, and flushes any remaining counts, publishing the shutdown values of
+// gauges. The final report is only made once, whether the Quantifier is stopped or
+// its context is cancelled first.
+func (q *Quantifier) finalReport(reason string) {
+
+	q.finalOnce.Do(func() {
+		q.countShutdown(reason)
+		q.final = true
+		q.report(true)
+	})
 }
@@ -1,142 +1,28 @@
+//go:build !quantify_disabled
+
 package quantify
 
 import (
 	"context"
 	"errors"
+	"fmt"
+	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
-	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
-	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/assert"
-	metricpb "google.golang.org/genproto/googleapis/api/metric"
-	"google.golang.org/genproto/googleapis/api/monitoredres"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func TestQuantifier_countToMetricPointCounter(t *testing.T) {
-
-	tests := []struct {
-		name     string
-		input    *count
-		expected *monitoringpb.Point
-	}{
-		{
-			name: "normal count",
-			input: &count{
-				start: time.Unix(1672693348, 0), // 2023-01-02 21:02:28
-				end:   time.Unix(1672693408, 0), // 2023-01-02 21:03:28
-				count: 365,
-			},
-			expected: &monitoringpb.Point{
-				Interval: &monitoringpb.TimeInterval{
-					StartTime: &timestamppb.Timestamp{
-						Seconds: 1672693348,
-						Nanos:   0,
-					},
-					EndTime: &timestamppb.Timestamp{
-						Seconds: 1672693407,
-						Nanos:   999000000,
-					},
-				},
-				Value: &monitoringpb.TypedValue{
-					Value: &monitoringpb.TypedValue_Int64Value{
-						Int64Value: 365,
-					},
-				},
-			},
-		},
-	}
-
-	for _, test := range tests {
-		assert.Equalf(t, test.expected, countToMetricPointProto(test.input), "%s failed", test.name)
-	}
-}
-
-func TestQuantifier_createTimeSeriesProto(t *testing.T) {
-
-	tests := []struct {
-		name        string
-		pointsInput *monitoringpb.Point
-		metricInput *metricpb.Metric
-		client      *Quantifier
-		expected    *monitoringpb.TimeSeries
-	}{
-		{
-			name: "single point, normal",
-			pointsInput: &monitoringpb.Point{
-
-				Interval: &monitoringpb.TimeInterval{
-					StartTime: &timestamppb.Timestamp{
-						Seconds: 1672693348, // 2023-01-02 21:02:28
-						Nanos:   0,
-					},
-					EndTime: &timestamppb.Timestamp{
-						Seconds: 1672693407, // 2023-01-02 21:03:28
-						Nanos:   999000000,
-					},
-				},
-				Value: &monitoringpb.TypedValue{
-					Value: &monitoringpb.TypedValue_Int64Value{
-						Int64Value: 365,
-					},
-				},
-			},
-			metricInput: &metricpb.Metric{
-				Type: "custom.googleapis.com/test-metric",
-				Labels: map[string]string{
-					"colour": "red",
-				},
-			},
-			client: &Quantifier{
-				resourceName: "global",
-				resourceLabels: map[string]string{
-					"project_id": "quantify",
-				},
-			},
-			expected: &monitoringpb.TimeSeries{
-				Metric: &metricpb.Metric{
-					Type: "custom.googleapis.com/test-metric",
-					Labels: map[string]string{
-						"colour": "red",
-					},
-				},
-				MetricKind: metricpb.MetricDescriptor_CUMULATIVE,
-				Resource: &monitoredres.MonitoredResource{
-					Type: "global",
-					Labels: map[string]string{
-						"project_id": "quantify",
-					},
-				},
-				Points: []*monitoringpb.Point{
-					{
-						Interval: &monitoringpb.TimeInterval{
-							StartTime: &timestamppb.Timestamp{
-								Seconds: 1672693348, // 2023-01-02 21:02:28
-								Nanos:   0,
-							},
-							EndTime: &timestamppb.Timestamp{
-								Seconds: 1672693407, // 2023-01-02 21:03:28
-								Nanos:   999000000,
-							},
-						},
-						Value: &monitoringpb.TypedValue{
-							Value: &monitoringpb.TypedValue_Int64Value{
-								Int64Value: 365,
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-
-	for _, test := range tests {
-		result := test.client.createTimeSeriesProto(test.metricInput, test.pointsInput)
-		assert.Equalf(t, test.expected, result, "%s failed", test.name)
+// requirePrefixPolicy is a naming policy that requires names to be prefixed with
+// "team/".
+func requirePrefixPolicy(name string) error {
+	if !strings.HasPrefix(name, "team/") {
+		return errors.New("name must be prefixed with team/")
 	}
+	return nil
 }
 
 func TestQuantifier_CreateCounter(t *testing.T) {
@@ -153,6 +39,7 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 		{
 			name: "normal inputs, first counter",
 			client: &Quantifier{
+				clock:    systemClock{},
 				counters: make([]*metricCounter, 0),
 			},
 			inputName: "test_metric",
@@ -161,10 +48,11 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 			},
 			inputInterval: 10,
 			expectedQuantifier: &Quantifier{
+				clock: systemClock{},
 				counters: []*metricCounter{
 					{
-						metric: &metricpb.Metric{
-							Type: "custom.googleapis.com/test_metric",
+						metric: &Metric{
+							Name: "test_metric",
 							Labels: map[string]string{
 								"colour": "red",
 							},
@@ -173,7 +61,7 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 							interval: 10,
 							counts:   &sync.Map{},
 							mu:       &sync.Mutex{},
-							clock:    clock.New(),
+							clock:    systemClock{},
 						},
 					},
 				},
@@ -183,10 +71,11 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 		{
 			name: "normal inputs, appended counter",
 			client: &Quantifier{
+				clock: systemClock{},
 				counters: []*metricCounter{
 					{
-						metric: &metricpb.Metric{
-							Type: "custom.googleapis.com/test_metric",
+						metric: &Metric{
+							Name: "test_metric",
 							Labels: map[string]string{
 								"colour": "red",
 							},
@@ -195,7 +84,7 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 							interval: 10,
 							counts:   &sync.Map{},
 							mu:       &sync.Mutex{},
-							clock:    clock.New(),
+							clock:    systemClock{},
 						},
 					},
 				},
@@ -206,10 +95,11 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 			},
 			inputInterval: 52,
 			expectedQuantifier: &Quantifier{
+				clock: systemClock{},
 				counters: []*metricCounter{
 					{
-						metric: &metricpb.Metric{
-							Type: "custom.googleapis.com/test_metric",
+						metric: &Metric{
+							Name: "test_metric",
 							Labels: map[string]string{
 								"colour": "red",
 							},
@@ -218,12 +108,12 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 							interval: 10,
 							counts:   &sync.Map{},
 							mu:       &sync.Mutex{},
-							clock:    clock.New(),
+							clock:    systemClock{},
 						},
 					},
 					{
-						metric: &metricpb.Metric{
-							Type: "custom.googleapis.com/test_metric_shape",
+						metric: &Metric{
+							Name: "test_metric_shape",
 							Labels: map[string]string{
 								"shape": "square",
 							},
@@ -232,7 +122,7 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 							interval: 52,
 							counts:   &sync.Map{},
 							mu:       &sync.Mutex{},
-							clock:    clock.New(),
+							clock:    systemClock{},
 						},
 					},
 				},
@@ -242,6 +132,7 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 		{
 			name: "zero interval, first counter",
 			client: &Quantifier{
+				clock:    systemClock{},
 				counters: make([]*metricCounter, 0),
 			},
 			inputName: "test_metric",
@@ -250,6 +141,7 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 			},
 			inputInterval: 0,
 			expectedQuantifier: &Quantifier{
+				clock:    systemClock{},
 				counters: make([]*metricCounter, 0),
 			},
 			expectedError: errors.New("interval must be greater than 0"),
@@ -257,6 +149,7 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 		{
 			name: "negative interval, first counter",
 			client: &Quantifier{
+				clock:    systemClock{},
 				counters: make([]*metricCounter, 0),
 			},
 			inputName: "test_metric",
@@ -265,39 +158,47 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 			},
 			inputInterval: -10,
 			expectedQuantifier: &Quantifier{
+				clock:    systemClock{},
 				counters: make([]*metricCounter, 0),
 			},
 			expectedError: errors.New("interval must be greater than 0"),
 		},
 		{
-			name: "invalid metric type (name), first counter",
+			name: "rejected by exporter validation, first counter",
 			client: &Quantifier{
+				clock:    systemClock{},
+				exporter: &mockExporter{validationErr: errors.New("invalid name parameter provided")},
 				counters: make([]*metricCounter, 0),
 			},
-			inputName: "test_metric!!!",
+			inputName: "test_metric",
 			inputLabels: map[string]string{
 				"colour": "red",
 			},
 			inputInterval: 60,
 			expectedQuantifier: &Quantifier{
+				clock:    systemClock{},
+				exporter: &mockExporter{validationErr: errors.New("invalid name parameter provided")},
 				counters: make([]*metricCounter, 0),
 			},
 			expectedError: errors.New("invalid name parameter provided"),
 		},
 		{
-			name: "invalid metric type (name), first counter",
+			name: "rejected by name policy, first counter",
 			client: &Quantifier{
-				counters: make([]*metricCounter, 0),
+				clock:      systemClock{},
+				counters:   make([]*metricCounter, 0),
+				namePolicy: requirePrefixPolicy,
 			},
 			inputName: "test_metric",
 			inputLabels: map[string]string{
-				"@!blah": "red",
+				"colour": "red",
 			},
 			inputInterval: 60,
 			expectedQuantifier: &Quantifier{
+				clock:    systemClock{},
 				counters: make([]*metricCounter, 0),
 			},
-			expectedError: errors.New("invalid label key provided: @!blah"),
+			expectedError: errors.New("name must be prefixed with team/"),
 		},
 	}
 
@@ -305,6 +206,9 @@ func TestQuantifier_CreateCounter(t *testing.T) {
 
 		counter, err := test.client.CreateCounter(test.inputName, test.inputLabels, test.inputInterval)
 
+		// functions can't be compared, so exclude the policy from the assertion
+		test.client.namePolicy = nil
+
 		assert.Equalf(t, test.expectedQuantifier, test.client, "%s failed", test.name)
 		assert.Equalf(t, test.expectedError, err, "%s failed", test.name)
 
@@ -321,31 +225,31 @@ func TestQuantifier_runTicker(t *testing.T) {
 		name               string
 		iterations         int
 		expectedIterations int
-		terminate          func(quantifier *Quantifier)
+		terminate          func(quantifier *Quantifier, cancel context.CancelFunc)
 	}{
 		{
 			name:               "runTicker - zero iterations ctx cancelled",
 			iterations:         0,
 			expectedIterations: 0,
-			terminate: func(quantifier *Quantifier) {
-				_, cancel := context.WithCancel(quantifier.ctx)
+			terminate: func(quantifier *Quantifier, cancel context.CancelFunc) {
 				cancel()
+				<-quantifier.stopped
 			},
 		},
 		{
 			name:               "runTicker - multiple iterations ctx cancelled",
 			iterations:         52,
 			expectedIterations: 52,
-			terminate: func(quantifier *Quantifier) {
-				_, cancel := context.WithCancel(quantifier.ctx)
+			terminate: func(quantifier *Quantifier, cancel context.CancelFunc) {
 				cancel()
+				<-quantifier.stopped
 			},
 		},
 		{
 			name:               "runTicker - zero iterations terminated",
 			iterations:         0,
 			expectedIterations: 0,
-			terminate: func(quantifier *Quantifier) {
+			terminate: func(quantifier *Quantifier, cancel context.CancelFunc) {
 				quantifier.terminate()
 			},
 		},
@@ -353,7 +257,7 @@ func TestQuantifier_runTicker(t *testing.T) {
 			name:               "runTicker - multiple iterations terminated",
 			iterations:         365,
 			expectedIterations: 365,
-			terminate: func(quantifier *Quantifier) {
+			terminate: func(quantifier *Quantifier, cancel context.CancelFunc) {
 				quantifier.terminate()
 			},
 		},
@@ -361,12 +265,14 @@ func TestQuantifier_runTicker(t *testing.T) {
 
 	for _, test := range tests {
 
+		ctx, cancel := context.WithCancel(context.Background())
+
 		// initialise *Quantifier client
-		mockClock := clock.NewMock()
+		mockClock := newMockClock()
 		client := &Quantifier{
 			clock:           mockClock,
 			mu:              &sync.Mutex{},
-			ctx:             context.Background(),
+			ctx:             ctx,
 			stop:            make(chan struct{}),
 			stopped:         make(chan struct{}),
 			refreshInterval: time.Second * 10,
@@ -374,7 +280,7 @@ func TestQuantifier_runTicker(t *testing.T) {
 		}
 
 		count := int64(0)
-		ticker := client.clock.Ticker(client.refreshInterval)
+		ticker := client.clock.NewTicker(client.refreshInterval)
 
 		// start ticker listener
 		go func() {
@@ -389,9 +295,10 @@ func TestQuantifier_runTicker(t *testing.T) {
 		}
 
 		// terminate ticker as described by test
-		test.terminate(client)
+		test.terminate(client, cancel)
+		cancel()
 
-		assert.Equalf(t, test.expectedIterations, int(count), "%s failed", test.name)
+		assert.Equalf(t, test.expectedIterations, int(atomic.LoadInt64(&count)), "%s failed", test.name)
 	}
 }
 
@@ -402,7 +309,7 @@ func TestQuantifier_terminate(t *testing.T) {
 	stopped := make(chan struct{})
 
 	// initialise *Quantifier client
-	mockClock := clock.NewMock()
+	mockClock := newMockClock()
 	client := &Quantifier{
 		clock:           mockClock,
 		mu:              &sync.Mutex{},
@@ -413,7 +320,7 @@ func TestQuantifier_terminate(t *testing.T) {
 		running:         true,
 	}
 
-	ticker := client.clock.Ticker(client.refreshInterval)
+	ticker := client.clock.NewTicker(client.refreshInterval)
 
 	// start ticker listener
 	go func() {
@@ -435,3 +342,717 @@ func TestQuantifier_terminate(t *testing.T) {
 
 	assert.Equal(t, expected, client)
 }
+
+func TestQuantifier_report(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+	errs := make([]error, 0)
+
+	client := &Quantifier{
+		clock:    mockClock,
+		exporter: exporter,
+		errorHandler: func(q *Quantifier, err error) {
+			errs = append(errs, err)
+		},
+	}
+
+	active, err := client.CreateCounter("active", map[string]string{"colour": "red"}, 10)
+	assert.NoError(t, err)
+
+	_, err = client.CreateCounter("idle", map[string]string{"colour": "blue"}, 10)
+	assert.NoError(t, err)
+
+	active.Count()
+	active.Count()
+	mockClock.Add(time.Second * 10)
+
+	client.report(false)
+
+	// only the counter with completed points should be exported
+	assert.Equal(t, []*Series{
+		{
+			Metric: &Metric{
+				Name:   "active",
+				Labels: map[string]string{"colour": "red"},
+			},
+			Points: []*Point{
+				{
+					Start: time.Unix(1670681770, 0),
+					End:   time.Unix(1670681780, 0),
+					Count: 2,
+				},
+			},
+		},
+	}, exporter.series)
+
+	// export errors are passed to the error handler
+	exporter.exportErr = errors.New("export failed")
+	active.Count()
+	client.report(true)
+
+	assert.Equal(t, []error{errors.New("export failed")}, errs)
+}
+
+func TestQuantifier_report_gap(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{
+		exportErr: errors.New("export failed"),
+	}
+	gaps := make([]*Gap, 0)
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+		gapHandler: func(q *Quantifier, gap *Gap) {
+			gaps = append(gaps, gap)
+		},
+	}
+
+	red, _ := client.CreateCounter("planes", map[string]string{"colour": "red"}, 10)
+	blue, _ := client.CreateCounter("planes", map[string]string{"colour": "blue"}, 10)
+
+	// two failed reports, dropping 3 points across 2 series
+	red.Count()
+	blue.Count()
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	red.Count()
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	assert.Len(t, gaps, 0)
+
+	// recovery
+	exporter.exportErr = nil
+	blue.Count()
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	assert.Equal(t, []*Gap{
+		{
+			Start:          time.Unix(1670681780, 0),
+			End:            time.Unix(1670681800, 0),
+			Failures:       2,
+			SeriesAffected: 2,
+			PointsDropped:  3,
+		},
+	}, gaps)
+	assert.Equal(t, time.Second*20, gaps[0].Duration())
+}
+
+func TestQuantifier_CreateCounter_commonLabels(t *testing.T) {
+
+	client := &Quantifier{
+		clock: systemClock{},
+		commonLabels: map[string]string{
+			"hostname": "host-a",
+			"pid":      "1234",
+		},
+	}
+
+	labels := map[string]string{
+		"colour": "red",
+		"pid":    "override",
+	}
+
+	_, err := client.CreateCounter("planes", labels, 10)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"colour":   "red",
+		"hostname": "host-a",
+		"pid":      "override",
+	}, client.counters[0].metric.Labels)
+
+	// the caller's labels must not be modified
+	assert.Len(t, labels, 2)
+}
+
+func TestQuantifier_CreateChildCounter(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	_, err := client.CreateChildCounter(&Counter{}, "requests", nil, 10)
+	assert.Equal(t, ErrUnknownCounter, err)
+
+	service, _ := client.CreateCounter("requests", nil, 10)
+	endpoint, _ := client.CreateChildCounter(service, "requests", map[string]string{"endpoint": "users"}, 10)
+	method, _ := client.CreateChildCounter(endpoint, "requests", map[string]string{"endpoint": "users", "method": "get"}, 10)
+
+	service.Count()
+	endpoint.Count()
+	method.Count()
+
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	counts := make(map[int]int64)
+	for _, s := range exporter.series {
+		counts[len(s.Metric.Labels)] = s.Points[0].Count
+	}
+
+	assert.Equal(t, map[int]int64{0: 3, 1: 2, 2: 1}, counts)
+}
+
+func TestQuantifier_report_cumulative(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		cumulative:   true,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	counter, err := client.CreateCounter("requests", nil, 10)
+	assert.NoError(t, err)
+
+	observed := make([]*Point, 0)
+	client.counters[0].observers = append(client.counters[0].observers, func(points []*Point) {
+		observed = append(observed, points...)
+	})
+
+	counter.Count()
+	counter.Count()
+	mockClock.Add(time.Second * 10)
+	counter.Count()
+	mockClock.Add(time.Second * 10)
+
+	client.report(false)
+
+	// totals accumulate from the start of the first point
+	assert.Equal(t, []*Point{
+		{
+			Start: time.Unix(1670681770, 0),
+			End:   time.Unix(1670681780, 0),
+			Count: 2,
+		},
+		{
+			Start: time.Unix(1670681770, 0),
+			End:   time.Unix(1670681790, 0),
+			Count: 3,
+		},
+	}, exporter.series[0].Points)
+
+	// observers still receive the counts of each interval
+	assert.Equal(t, int64(2), observed[0].Count)
+	assert.Equal(t, int64(1), observed[1].Count)
+}
+
+func TestQuantifier_report_cumulative_delta(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		cumulative:   true,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	counter, err := client.CreateCounter("requests", nil, 10, MetricOptionWithDeltaKind())
+	assert.NoError(t, err)
+
+	counter.Count()
+	counter.Count()
+	mockClock.Add(time.Second * 10)
+	counter.Count()
+	mockClock.Add(time.Second * 10)
+
+	client.report(false)
+
+	// delta counters report the count of each interval
+	assert.Equal(t, MetricKindDelta, exporter.series[0].Metric.Kind)
+	assert.Equal(t, []*Point{
+		{
+			Start: time.Unix(1670681770, 0),
+			End:   time.Unix(1670681780, 0),
+			Count: 2,
+		},
+		{
+			Start: time.Unix(1670681780, 0),
+			End:   time.Unix(1670681790, 0),
+			Count: 1,
+		},
+	}, exporter.series[0].Points)
+}
+
+func TestNew_enableIf(t *testing.T) {
+
+	tests := []struct {
+		name             string
+		enabled          bool
+		exporter         Exporter
+		expectedExporter Exporter
+		expectedError    error
+	}{
+		{
+			name:             "enabled",
+			enabled:          true,
+			exporter:         &mockExporter{},
+			expectedExporter: &mockExporter{},
+			expectedError:    nil,
+		},
+		{
+			name:             "enabled without exporter",
+			enabled:          true,
+			exporter:         nil,
+			expectedExporter: nil,
+			expectedError:    ErrNoExporter,
+		},
+		{
+			name:             "disabled without exporter",
+			enabled:          false,
+			exporter:         nil,
+			expectedExporter: discardExporter{},
+			expectedError:    nil,
+		},
+	}
+
+	for _, test := range tests {
+
+		enabled := test.enabled
+
+		q, err := New(
+			context.Background(),
+			OptionWithClock(newMockClock()),
+			OptionWithExporter(test.exporter),
+			OptionWithEnableIf(func() bool { return enabled }),
+		)
+
+		if test.expectedError != nil {
+			assert.ErrorIsf(t, err, test.expectedError, "%s failed", test.name)
+			continue
+		}
+
+		assert.NoErrorf(t, err, "%s failed", test.name)
+		assert.Equalf(t, test.expectedExporter, q.exporter, "%s failed", test.name)
+		q.Stop()
+	}
+}
+
+func TestQuantifier_report_parallel(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	// enough counters to be collected concurrently
+	for i := 0; i < parallelCollectionThreshold*3; i++ {
+
+		counter, err := client.CreateCounter(fmt.Sprintf("counter_%d", i), nil, 10)
+		assert.NoError(t, err)
+
+		assert.NoError(t, counter.AddAt(mockClock.Now(), int64(i+1)))
+	}
+
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	// series remain in the order their counters were created
+	if assert.Len(t, exporter.series, parallelCollectionThreshold*3) {
+		for i, s := range exporter.series {
+			assert.Equal(t, fmt.Sprintf("counter_%d", i), s.Metric.Name)
+			assert.Equal(t, int64(i+1), s.Points[0].Count)
+		}
+	}
+}
+
+func TestQuantifier_Flush(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	q, err := New(
+		context.Background(),
+		OptionWithClock(mockClock),
+		OptionWithExporter(exporter),
+		OptionSynchronous(),
+	)
+	assert.NoError(t, err)
+
+	counter, err := q.CreateCounter("planes", nil, 10)
+	assert.NoError(t, err)
+
+	counter.Count()
+	counter.Count()
+
+	// nothing is reported until flushed, however much time passes
+	mockClock.Add(time.Minute)
+	assert.Empty(t, exporter.series)
+
+	assert.NoError(t, q.Flush())
+	if assert.Len(t, exporter.series, 1) {
+		assert.Equal(t, int64(2), exporter.series[0].Points[0].Count)
+	}
+
+	// the current interval is reported on Stop
+	counter.Count()
+	assert.NoError(t, q.Flush())
+	assert.Len(t, exporter.series, 1)
+
+	q.Stop()
+	assert.Len(t, exporter.series, 2)
+
+	// export errors are returned
+	exporter.exportErr = errors.New("unavailable")
+	counter.Count()
+	mockClock.Add(time.Minute)
+	assert.EqualError(t, q.Flush(), "unavailable")
+
+	// only synchronous quantifiers can be flushed
+	async := &Quantifier{clock: mockClock, exporter: exporter}
+	assert.Equal(t, ErrNotSynchronous, async.Flush())
+}
+
+func TestQuantifier_tick_resume(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:           mockClock,
+		exporter:        exporter,
+		errorHandler:    func(q *Quantifier, err error) {},
+		refreshInterval: time.Second * 10,
+	}
+
+	counter, err := client.CreateCounter("planes", nil, 10)
+	assert.NoError(t, err)
+
+	gauge, err := client.CreateGauge("queue_depth", nil, GaugeAggregationLast)
+	assert.NoError(t, err)
+
+	client.tick()
+	assert.Empty(t, exporter.series)
+
+	// a pause of 25 intervals, with a point in each
+	start := mockClock.Now()
+	for i := 0; i < 25; i++ {
+		assert.NoError(t, counter.AddAt(start.Add(time.Second*time.Duration(i*10)), int64(i+1)))
+	}
+	gauge.Set(3)
+	mockClock.Add(time.Second * 250)
+
+	client.tick()
+
+	// the backlog is reported oldest first in chunks, with the gauge sampled once
+	var counts []int64
+	var chunks, gauges int
+	for _, s := range exporter.series {
+
+		if s.Metric.Name == "queue_depth" {
+			gauges++
+			continue
+		}
+
+		chunks++
+		assert.LessOrEqual(t, len(s.Points), backlogChunkSize)
+
+		for _, p := range s.Points {
+			counts = append(counts, p.Count)
+		}
+	}
+
+	assert.Equal(t, 3, chunks)
+	assert.Equal(t, 1, gauges)
+	if assert.Len(t, counts, 25) {
+		for i, count := range counts {
+			assert.Equal(t, int64(i+1), count)
+		}
+	}
+
+	// ticks fired in a burst on resuming are collapsed
+	reported := len(exporter.series)
+	counter.Count()
+	mockClock.Add(time.Second)
+	client.tick()
+	assert.Len(t, exporter.series, reported)
+
+	// regular ticks report as normal
+	mockClock.Add(time.Second * 9)
+	client.tick()
+	assert.Len(t, exporter.series, reported+2)
+}
+
+// limitedExporter implements Exporter and WriteIntervalLimiter.
+type limitedExporter struct {
+	mockExporter
+	min time.Duration
+}
+
+func (le *limitedExporter) MinWriteInterval() time.Duration {
+	return le.min
+}
+
+func TestNew_minWriteInterval(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		options       []Option
+		expectedError error
+	}{
+		{
+			name: "refresh interval above minimum",
+			options: []Option{
+				OptionWithRefreshInterval(time.Second * 5),
+			},
+		},
+		{
+			name: "refresh interval below minimum",
+			options: []Option{
+				OptionWithRefreshInterval(time.Second),
+			},
+			expectedError: ErrIntervalTooShort,
+		},
+		{
+			name: "synchronous",
+			options: []Option{
+				OptionWithRefreshInterval(time.Second),
+				OptionSynchronous(),
+			},
+		},
+	}
+
+	for _, test := range tests {
+
+		options := append([]Option{OptionWithExporter(&limitedExporter{min: time.Second * 5})}, test.options...)
+
+		q, err := New(context.Background(), options...)
+		assert.ErrorIs(t, err, test.expectedError, "%s failed", test.name)
+
+		if q != nil {
+			q.Stop()
+		}
+	}
+}
+
+func TestQuantifier_CreateCounter_interval(t *testing.T) {
+
+	errs := make([]error, 0)
+
+	q := &Quantifier{
+		clock:           systemClock{},
+		exporter:        &limitedExporter{min: time.Second * 5},
+		refreshInterval: time.Second * 30,
+		cumulative:      true,
+		errorHandler: func(q *Quantifier, err error) {
+			errs = append(errs, err)
+		},
+	}
+
+	// intervals below the exporter's minimum are rejected
+	_, err := q.CreateCounter("planes", nil, 1)
+	assert.ErrorIs(t, err, ErrIntervalTooShort)
+
+	// and intervals below the refresh interval warned about when cumulative
+	_, err = q.CreateCounter("planes", nil, 10)
+	assert.NoError(t, err)
+
+	_, err = q.CreateCounter("boats", nil, 30)
+	assert.NoError(t, err)
+
+	if assert.Len(t, errs, 1) {
+		assert.ErrorIs(t, errs[0], ErrIntervalBelowRefresh)
+		assert.EqualError(t, errs[0], "interval: counter interval is shorter than the refresh interval: 10s is below 30s")
+	}
+}
+
+// closerExporter implements Exporter and io.Closer, counting calls to Close.
+type closerExporter struct {
+	mockExporter
+	closes   int
+	closeErr error
+}
+
+func (ce *closerExporter) Close() error {
+	ce.closes++
+	return ce.closeErr
+}
+
+// waitForGoroutines waits for the number of running goroutines to fall to at most
+// n, returning false if it doesn't within a second.
+func waitForGoroutines(n int) bool {
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	return true
+}
+
+func TestQuantifier_Close(t *testing.T) {
+
+	baseline := runtime.NumGoroutine()
+
+	exporter := &closerExporter{closeErr: errors.New("already closed")}
+
+	q, err := New(context.Background(), OptionWithExporter(exporter))
+	assert.NoError(t, err)
+
+	counter, err := q.CreateCounter("planes", nil, 10)
+	assert.NoError(t, err)
+	counter.Count()
+
+	// subsequent calls return the result of the first
+	assert.EqualError(t, q.Close(), "already closed")
+	assert.EqualError(t, q.Close(), "already closed")
+
+	assert.Equal(t, 1, exporter.closes)
+	assert.Len(t, exporter.series, 1)
+
+	assert.True(t, waitForGoroutines(baseline), "goroutines leaked")
+}
+
+func TestQuantifier_Stop_closesExporter(t *testing.T) {
+
+	var handled error
+
+	exporter := &closerExporter{closeErr: errors.New("already closed")}
+
+	q, err := New(
+		context.Background(),
+		OptionWithExporter(exporter),
+		OptionWithErrorHandler(func(q *Quantifier, err error) { handled = err }),
+	)
+	assert.NoError(t, err)
+
+	q.Stop()
+	q.Stop()
+
+	assert.Equal(t, 1, exporter.closes)
+	assert.EqualError(t, handled, "already closed")
+}
+
+func TestQuantifier_tickOnClose(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681761, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:           mockClock,
+		exporter:        exporter,
+		errorHandler:    func(q *Quantifier, err error) {},
+		refreshInterval: time.Minute,
+		reportingDelay:  time.Second,
+		publishOnClose:  true,
+	}
+
+	counter, err := client.CreateCounter("planes", nil, 10)
+	assert.NoError(t, err)
+
+	gauge, err := client.CreateGauge("queue_depth", nil, GaugeAggregationLast)
+	assert.NoError(t, err)
+
+	// the first tick refreshes
+	client.tickOnClose()
+	assert.Empty(t, exporter.series)
+
+	counter.Count()
+	gauge.Set(3)
+
+	// the interval hasn't closed, after the reporting delay
+	mockClock.Add(time.Second * 9)
+	client.tickOnClose()
+	assert.Empty(t, exporter.series)
+
+	// the interval is published once closed, without sampling the gauge
+	mockClock.Add(time.Second)
+	client.tickOnClose()
+
+	if assert.Len(t, exporter.series, 1) {
+		assert.Equal(t, "planes", exporter.series[0].Metric.Name)
+		assert.Equal(t, time.Unix(1670681760, 0), exporter.series[0].Points[0].Start)
+	}
+
+	mockClock.Add(time.Second)
+	client.tickOnClose()
+	assert.Len(t, exporter.series, 1)
+
+	// the gauge is published with the next refresh
+	mockClock.Add(time.Second * 50)
+	client.tickOnClose()
+
+	if assert.Len(t, exporter.series, 2) {
+		assert.Equal(t, "queue_depth", exporter.series[1].Metric.Name)
+	}
+}
+
+func TestQuantifier_scheduleClose(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681761, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:           mockClock,
+		exporter:        exporter,
+		errorHandler:    func(q *Quantifier, err error) {},
+		refreshInterval: time.Minute,
+		publishOnClose:  true,
+	}
+
+	planes, err := client.CreateCounter("planes", nil, 15)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1670681775, 0), client.nextClose)
+
+	// a shorter interval is scheduled before the one already scheduled
+	boats, err := client.CreateCounter("boats", nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1670681770, 0), client.nextClose)
+
+	client.tickOnClose()
+	assert.Equal(t, time.Unix(1670681770, 0), client.nextClose)
+
+	planes.Count()
+	boats.Count()
+
+	// intervals closing at the same boundary are published together
+	mockClock.Set(time.Unix(1670681790, 0))
+	client.tickOnClose()
+
+	assert.Len(t, exporter.series, 2)
+	assert.Equal(t, time.Unix(1670681800, 0), client.nextClose)
+}
@@ -0,0 +1,51 @@
+package quantify
+
+import "time"
+
+// Clock provides the time source used by a Quantifier and its counters. A
+// custom Clock can be supplied with OptionWithClock, for example to control
+// time within tests.
+type Clock interface {
+
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTicker returns a Ticker that delivers the time at the provided interval.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks at intervals, mirroring time.Ticker.
+type Ticker interface {
+
+	// C returns the channel on which ticks are delivered.
+	C() <-chan time.Time
+
+	// Stop turns off the Ticker, no more ticks will be sent after Stop is called.
+	Stop()
+}
+
+// systemClock implements Clock using the time package.
+type systemClock struct{}
+
+// systemTicker implements Ticker by wrapping a time.Ticker.
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (sc systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (sc systemClock) NewTicker(d time.Duration) Ticker {
+	return &systemTicker{
+		ticker: time.NewTicker(d),
+	}
+}
+
+func (st *systemTicker) C() <-chan time.Time {
+	return st.ticker.C
+}
+
+func (st *systemTicker) Stop() {
+	st.ticker.Stop()
+}
@@ -0,0 +1,38 @@
+package quantify
+
+import (
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+// mockClock adapts clock.Mock to the Clock interface so that time can be
+// controlled within tests.
+type mockClock struct {
+	*clock.Mock
+}
+
+// mockTicker adapts clock.Ticker to the Ticker interface.
+type mockTicker struct {
+	ticker *clock.Ticker
+}
+
+func newMockClock() *mockClock {
+	return &mockClock{
+		Mock: clock.NewMock(),
+	}
+}
+
+func (mc *mockClock) NewTicker(d time.Duration) Ticker {
+	return &mockTicker{
+		ticker: mc.Mock.Ticker(d),
+	}
+}
+
+func (mt *mockTicker) C() <-chan time.Time {
+	return mt.ticker.C
+}
+
+func (mt *mockTicker) Stop() {
+	mt.ticker.Stop()
+}
@@ -0,0 +1,94 @@
+package quantify
+
+// compactor removes points from series whose value is unchanged from the previous
+// point published for the same series, reducing the number of points written for
+// mostly idle metrics.
+type compactor struct {
+
+	// maxSkipped is the number of consecutive unchanged points that can be skipped
+	// for a series before one is published regardless, so that the series doesn't
+	// appear to have stopped reporting.
+	maxSkipped int
+
+	// last holds the most recently published point of each series, by metricKey.
+	last map[string]*compactedSeries
+}
+
+// compactedSeries tracks the state of a single series being compacted.
+type compactedSeries struct {
+	count        int64
+	value        float64
+	distribution *DistributionValue
+	skipped      int
+}
+
+func newCompactor(maxSkipped int) *compactor {
+	return &compactor{
+		maxSkipped: maxSkipped,
+		last:       make(map[string]*compactedSeries),
+	}
+}
+
+// compact returns the provided series with any unchanged points removed. Series
+// left with no points are omitted.
+func (c *compactor) compact(series []*Series) []*Series {
+
+	compacted := make([]*Series, 0, len(series))
+
+	for _, s := range series {
+
+		key := metricKey(s.Metric)
+		points := make([]*Point, 0, len(s.Points))
+
+		for _, point := range s.Points {
+
+			last, ok := c.last[key]
+			if ok && last.count == point.Count && last.value == point.Value &&
+				distributionsEqual(last.distribution, point.Distribution) && last.skipped < c.maxSkipped {
+				last.skipped++
+				continue
+			}
+
+			c.last[key] = &compactedSeries{
+				count:        point.Count,
+				value:        point.Value,
+				distribution: point.Distribution,
+			}
+			points = append(points, point)
+		}
+
+		if len(points) == 0 {
+			continue
+		}
+
+		compacted = append(compacted, &Series{
+			Metric: s.Metric,
+			Points: points,
+		})
+	}
+
+	return compacted
+}
+
+// distributionsEqual returns whether the provided distributions summarise the same
+// observations, by their count, mean, sum of squared deviation, range and bucket
+// counts. Either may be nil.
+func distributionsEqual(a, b *DistributionValue) bool {
+
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if a.Count != b.Count || a.Mean != b.Mean || a.SumOfSquaredDeviation != b.SumOfSquaredDeviation ||
+		a.Min != b.Min || a.Max != b.Max || len(a.BucketCounts) != len(b.BucketCounts) {
+		return false
+	}
+
+	for i := range a.BucketCounts {
+		if a.BucketCounts[i] != b.BucketCounts[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,136 @@
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactor_compact(t *testing.T) {
+
+	metric := &Metric{
+		Name:   "queue_depth",
+		Labels: map[string]string{"queue": "orders"},
+	}
+
+	point := func(offset int64, count int64) *Point {
+		return &Point{
+			Start: time.Unix(1670681770+offset, 0),
+			End:   time.Unix(1670681780+offset, 0),
+			Count: count,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		input    [][]*Point
+		expected [][]*Point
+	}{
+		{
+			name: "changed values are published",
+			input: [][]*Point{
+				{point(0, 1), point(10, 2)},
+				{point(20, 3)},
+			},
+			expected: [][]*Point{
+				{point(0, 1), point(10, 2)},
+				{point(20, 3)},
+			},
+		},
+		{
+			name: "unchanged values are skipped",
+			input: [][]*Point{
+				{point(0, 1), point(10, 1)},
+				{point(20, 1)},
+				{point(30, 2)},
+			},
+			expected: [][]*Point{
+				{point(0, 1)},
+				nil,
+				{point(30, 2)},
+			},
+		},
+		{
+			name: "unchanged value is published after max skipped",
+			input: [][]*Point{
+				{point(0, 1), point(10, 1), point(20, 1), point(30, 1)},
+			},
+			expected: [][]*Point{
+				{point(0, 1), point(30, 1)},
+			},
+		},
+	}
+
+	for _, test := range tests {
+
+		c := newCompactor(2)
+
+		for i, points := range test.input {
+
+			result := c.compact([]*Series{{Metric: metric, Points: points}})
+
+			if test.expected[i] == nil {
+				assert.Emptyf(t, result, "%s failed", test.name)
+				continue
+			}
+
+			assert.Equalf(t, []*Series{{Metric: metric, Points: test.expected[i]}}, result, "%s failed", test.name)
+		}
+	}
+}
+
+func TestCompactor_compact_distributions(t *testing.T) {
+
+	metric := &Metric{
+		Name:      "latency",
+		ValueType: ValueTypeDistribution,
+	}
+
+	buckets := ExplicitBuckets(10)
+
+	point := func(offset int64, count int64, mean float64) *Point {
+		return &Point{
+			Start: time.Unix(1670681770+offset, 0),
+			End:   time.Unix(1670681780+offset, 0),
+			Distribution: &DistributionValue{
+				Count:        count,
+				Mean:         mean,
+				Min:          mean,
+				Max:          mean,
+				Buckets:      buckets,
+				BucketCounts: []int64{count, 0},
+			},
+		}
+	}
+
+	c := newCompactor(2)
+
+	// unchanged distributions are skipped, but changed ones are published
+	result := c.compact([]*Series{{Metric: metric, Points: []*Point{point(0, 1, 5), point(10, 1, 5), point(20, 1, 7), point(30, 2, 7)}}})
+
+	assert.Equal(t, []*Series{{Metric: metric, Points: []*Point{point(0, 1, 5), point(20, 1, 7), point(30, 2, 7)}}}, result)
+}
+
+func TestDistributionsEqual(t *testing.T) {
+
+	a := &DistributionValue{Count: 2, Mean: 3, BucketCounts: []int64{1, 1}}
+
+	tests := []struct {
+		name     string
+		input    *DistributionValue
+		expected bool
+	}{
+		{name: "equal", input: &DistributionValue{Count: 2, Mean: 3, BucketCounts: []int64{1, 1}}, expected: true},
+		{name: "nil", input: nil, expected: false},
+		{name: "count", input: &DistributionValue{Count: 3, Mean: 3, BucketCounts: []int64{1, 1}}, expected: false},
+		{name: "mean", input: &DistributionValue{Count: 2, Mean: 4, BucketCounts: []int64{1, 1}}, expected: false},
+		{name: "bucket counts", input: &DistributionValue{Count: 2, Mean: 3, BucketCounts: []int64{2, 0}}, expected: false},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, distributionsEqual(a, test.input), "%s failed", test.name)
+	}
+
+	assert.True(t, distributionsEqual(nil, nil))
+}
@@ -4,23 +4,20 @@ import (
 	"errors"
 	"sort"
 	"sync"
-	"sync/atomic"
 	"time"
-
-	"github.com/benbjohnson/clock"
 )
 
-// count represents a tally over a duration of time.
-type count struct {
-
-	// start is used to mark the count's duration start time (inclusive)
-	start time.Time
-
-	// end is used to mark the count's duration end time (exclusive)
-	end time.Time
+var (
+	ErrNegativeValue = errors.New("value can't be negative")
+)
 
-	// count is the total recorded within the specified duration.
-	count int64
+// keysPool holds the slices used by takePoints to gather interval keys, so that
+// they're reused across reports rather than allocated for every counter.
+var keysPool = sync.Pool{
+	New: func() any {
+		keys := make([]int64, 0, 8)
+		return &keys
+	},
 }
 
 // Counter implements a thread-safe Counter that can be used to record a tally which is
@@ -39,39 +36,134 @@ type Counter struct {
 	mu *sync.Mutex
 
 	// clock used to retrieve time.
-	clock clock.Clock
+	clock Clock
+
+	// latency, when set, records the time taken by each call to Count.
+	latency *latencyRecorder
+
+	// sampler, when set, samples calls to Count as volume increases.
+	sampler *adaptiveSampler
+
+	// parent, when set, is also incremented whenever this Counter is.
+	parent *Counter
+
+	// delay is how long after an interval ends before it is considered complete.
+	delay time.Duration
+
+	// buffer, when set, receives counts to be aggregated by a single goroutine
+	// (see MetricOptionWithBufferedCounting).
+	buffer chan countEvent
+
+	// bufferStopped is closed to stop the goroutine aggregating the buffer, once,
+	// with bufferStop (see stopBuffer).
+	bufferStopped chan struct{}
+	bufferStop    sync.Once
+
+	// stripes, when set, hold new counts until they're folded into counts as
+	// points are taken (see MetricOptionWithStripedCounting).
+	stripes *stripes
+
+	// calendar, when set, aligns intervals to calendar windows in place of interval
+	// (see CreateCalendarCounter).
+	calendar *Calendar
+
+	// unit is the unit of the Counter's metric, used to convert values recorded with
+	// RecordDuration and RecordBytes.
+	unit string
+
+	// noop is set for Counters that discard counts (see
+	// OptionWithBestEffortInstruments).
+	noop bool
 }
 
 // newCounter returns an instantiated Counter, storing the provided metric information
-// for reporting later.
-func newCounter(interval int64) (*Counter, error) {
+// for reporting later. The provided Clock is used to determine the current interval.
+func newCounter(interval int64, clock Clock) (*Counter, error) {
 
 	if interval <= 0 {
 		return nil, errors.New("interval must be greater than 0")
 	}
 
 	return &Counter{
-		clock:    clock.New(),
+		clock:    clock,
 		interval: interval,
 		counts:   &sync.Map{},
 		mu:       &sync.Mutex{},
 	}, nil
 }
 
-// Count adds 1 to the running total of this Counter.
-func (c *Counter) Count() {
+// CountAt adds 1 to the total of the interval containing the provided time, rather
+// than the current interval. This allows events to be attributed to the interval
+// in which they occurred, for example when processing a delayed event stream or
+// backfilling historical data.
+//
+// Note: intervals which have already passed are reported on the next refresh, and
+// exporters may reject points older than those already reported for a series (as
+// Google Cloud Monitoring does), so events should be counted in time order.
+func (c *Counter) CountAt(t time.Time) {
+	c.add(t, 1)
+}
 
-	var zero int64
+// Add adds n to the running total of this Counter, for incrementing by a computed
+// amount in a single call. An error is returned if n is negative.
+func (c *Counter) Add(n int64) error {
+	return c.AddAt(c.clock.Now(), n)
+}
 
-	count, _ := c.counts.LoadOrStore(c.getKey(), &zero)
+// AddAt adds n to the total of the interval containing the provided time (see
+// CountAt). An error is returned if n is negative.
+func (c *Counter) AddAt(t time.Time, n int64) error {
 
-	atomic.AddInt64(count.(*int64), 1)
+	if n < 0 {
+		return ErrNegativeValue
+	}
+
+	c.add(t, n)
+	return nil
+}
+
+// RecordEvents counts each of the provided event times towards the interval that
+// contains it (see CountAt), for example when draining a batch of messages that
+// carry the time of their event. Events are bucketed by interval first, so each
+// interval is only added to once per call, however many of the events it contains.
+func (c *Counter) RecordEvents(times []time.Time) {
+
+	buckets := make(map[int64]int64)
+
+	for _, t := range times {
+		buckets[c.getKeyAt(t)]++
+	}
+
+	for key, n := range buckets {
+		c.add(time.Unix(key, 0), n)
+	}
 }
 
 // getKey returns a unique key for the current time period using time.Now. The key
 // represents the starting time of the period as seconds since epoch.
 func (c *Counter) getKey() int64 {
-	return c.clock.Now().Truncate(time.Second * time.Duration(c.interval)).Unix()
+	return c.getKeyAt(c.clock.Now())
+}
+
+// getKeyAt returns a unique key for the time period containing the provided time.
+// The key represents the starting time of the period as seconds since epoch.
+func (c *Counter) getKeyAt(t time.Time) int64 {
+
+	if c.calendar != nil {
+		return c.calendar.start(t).Unix()
+	}
+
+	return t.Truncate(time.Second * time.Duration(c.interval)).Unix()
+}
+
+// getEnd returns the end of the interval with the provided key.
+func (c *Counter) getEnd(key int64) time.Time {
+
+	if c.calendar != nil {
+		return time.Unix(c.calendar.end(time.Unix(key, 0)).Unix(), 0)
+	}
+
+	return time.Unix(key+c.interval, 0)
 }
 
 // takePoints retrieves any outstanding counts for time intervals that have already
@@ -83,45 +175,73 @@ func (c *Counter) getKey() int64 {
 //
 // The current parameter is used to request the current interval (when set to true) as
 // well as already completed intervals (if available).
-func (c *Counter) takePoints(current bool) []*count {
+//
+// limit caps the number of points returned, leaving any newer intervals in place to
+// be taken by a later call. A limit of 0 or less returns all available points.
+func (c *Counter) takePoints(current bool, limit int) []*Point {
+
+	// counts still buffered may belong to the intervals being taken
+	c.flushBuffer()
 
 	c.mu.Lock()
 
-	currentFrame := c.getKey()
+	// intervals are only complete once the reporting delay has also passed
+	currentFrame := c.getKeyAt(c.clock.Now().Add(-c.delay))
+
+	if c.stripes != nil {
+		c.stripes.fold(c.counts, current, currentFrame)
+	}
 
-	completedCounts := make(map[int64]int64)
+	pooled := keysPool.Get().(*[]int64)
+	keys := (*pooled)[:0]
 
 	c.counts.Range(func(key, value any) bool {
 
 		keyInt := key.(int64)
-		valueInt := *value.(*int64)
 
 		// if current interval wasn't requested, and currentFrame is current interval, skip
 		if !current && keyInt >= currentFrame {
 			return true // continue
 		}
 
-		completedCounts[keyInt] = valueInt
-		c.counts.Delete(keyInt)
+		keys = append(keys, keyInt)
 		return true
 	})
 
-	c.mu.Unlock()
+	// sort keys so that the oldest intervals are taken first
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
 
-	response := make([]*count, 0)
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
 
-	for k, v := range completedCounts {
-		response = append(response, &count{
-			start: time.Unix(k, 0),
-			end:   time.Unix(k+c.interval, 0),
-			count: v,
+	response := make([]*Point, 0, len(keys))
+
+	for _, key := range keys {
+
+		value, ok := c.counts.LoadAndDelete(key)
+		if !ok {
+			continue
+		}
+
+		response = append(response, &Point{
+			Start: time.Unix(key, 0),
+			End:   c.getEnd(key),
+			Count: *value.(*int64),
 		})
 	}
 
-	// sort responses
-	sort.Slice(response, func(i, j int) bool {
-		return response[i].start.Before(response[j].start)
-	})
+	c.mu.Unlock()
+
+	*pooled = keys
+	keysPool.Put(pooled)
+
+	// adjust sampling to the volume of the most recently completed interval
+	if c.sampler != nil && !current && len(response) > 0 {
+		c.sampler.adjust(response[len(response)-1].Count)
+	}
 
 	return response
 }
@@ -0,0 +1,28 @@
+//go:build quantify_disabled
+
+package quantify
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter_disabled(t *testing.T) {
+
+	counter := &Counter{
+		clock:    newMockClock(),
+		interval: 10,
+		counts:   &sync.Map{},
+		mu:       &sync.Mutex{},
+	}
+
+	counter.Count()
+	counter.CountAt(time.Unix(1670681770, 0))
+	assert.NoError(t, counter.AddAt(time.Unix(1670681770, 0), 5))
+	assert.NoError(t, counter.Add(5))
+
+	assert.Len(t, counter.takePoints(true, 0), 0)
+}
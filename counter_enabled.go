@@ -0,0 +1,63 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Count adds 1 to the running total of this Counter.
+func (c *Counter) Count() {
+
+	if c.latency != nil {
+		defer c.latency.record(time.Now())
+	}
+
+	weight := int64(1)
+
+	if c.sampler != nil {
+
+		var sampled bool
+		weight, sampled = c.sampler.sample()
+		if !sampled {
+			return
+		}
+	}
+
+	if c.buffer != nil {
+
+		// counts made once the buffer has stopped are discarded
+		select {
+		case c.buffer <- countEvent{t: c.clock.Now(), n: weight}:
+		case <-c.bufferStopped:
+		}
+		return
+	}
+
+	c.add(c.clock.Now(), weight)
+}
+
+// add adds n to the total of the interval containing the provided time, and to
+// the totals of any ancestors of the Counter.
+func (c *Counter) add(t time.Time, n int64) {
+
+	if c.noop {
+		return
+	}
+
+	if c.stripes != nil {
+		c.stripes.add(c.getKeyAt(t), n)
+	} else {
+
+		var zero int64
+
+		count, _ := c.counts.LoadOrStore(c.getKeyAt(t), &zero)
+
+		atomic.AddInt64(count.(*int64), n)
+	}
+
+	if c.parent != nil {
+		c.parent.add(t, n)
+	}
+}
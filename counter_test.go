@@ -1,3 +1,5 @@
+//go:build !quantify_disabled
+
 package quantify
 
 import (
@@ -6,7 +8,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/benbjohnson/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,7 +41,7 @@ func TestGetKey(t *testing.T) {
 
 	for _, test := range tests {
 
-		clock := clock.NewMock()
+		clock := newMockClock()
 		clock.Set(test.time)
 
 		counter := &Counter{
@@ -98,7 +99,7 @@ func TestCount(t *testing.T) {
 	for _, test := range tests {
 
 		counter := &Counter{
-			clock:  clock.NewMock(),
+			clock:  newMockClock(),
 			counts: &sync.Map{},
 			mu:     &sync.Mutex{},
 		}
@@ -120,7 +121,7 @@ func TestTakePoints(t *testing.T) {
 		counterInterval int64
 		startTime       time.Time
 		setup           []func(*Counter)
-		expectedResult  []*count
+		expectedResult  []*Point
 	}{
 		{
 			name:            "Single Thread, Multiple Instances",
@@ -138,7 +139,7 @@ func TestTakePoints(t *testing.T) {
 
 				// increment time (10 seconds)
 				func(c *Counter) {
-					newTime := clock.NewMock()
+					newTime := newMockClock()
 					newTime.Set(c.clock.Now().Add(time.Second * 10))
 					c.clock = newTime
 				},
@@ -152,7 +153,7 @@ func TestTakePoints(t *testing.T) {
 
 				// increment time (10 seconds)
 				func(c *Counter) {
-					newTime := clock.NewMock()
+					newTime := newMockClock()
 					newTime.Set(c.clock.Now().Add(time.Second * 10))
 					c.clock = newTime
 				},
@@ -164,16 +165,16 @@ func TestTakePoints(t *testing.T) {
 					}
 				},
 			},
-			expectedResult: []*count{
+			expectedResult: []*Point{
 				{
-					start: time.Unix(1670681770, 0),
-					end:   time.Unix(1670681780, 0),
-					count: 10,
+					Start: time.Unix(1670681770, 0),
+					End:   time.Unix(1670681780, 0),
+					Count: 10,
 				},
 				{
-					start: time.Unix(1670681780, 0),
-					end:   time.Unix(1670681790, 0),
-					count: 25,
+					Start: time.Unix(1670681780, 0),
+					End:   time.Unix(1670681790, 0),
+					Count: 25,
 				},
 			},
 		},
@@ -193,7 +194,7 @@ func TestTakePoints(t *testing.T) {
 
 				// increment time (10 seconds)
 				func(c *Counter) {
-					newTime := clock.NewMock()
+					newTime := newMockClock()
 					newTime.Set(c.clock.Now().Add(time.Second * 10))
 					c.clock = newTime
 				},
@@ -207,7 +208,7 @@ func TestTakePoints(t *testing.T) {
 
 				// increment time (10 seconds)
 				func(c *Counter) {
-					newTime := clock.NewMock()
+					newTime := newMockClock()
 					newTime.Set(c.clock.Now().Add(time.Second * 10))
 					c.clock = newTime
 				},
@@ -219,21 +220,21 @@ func TestTakePoints(t *testing.T) {
 					}
 				},
 			},
-			expectedResult: []*count{
+			expectedResult: []*Point{
 				{
-					start: time.Unix(1670681770, 0),
-					end:   time.Unix(1670681780, 0),
-					count: 10,
+					Start: time.Unix(1670681770, 0),
+					End:   time.Unix(1670681780, 0),
+					Count: 10,
 				},
 				{
-					start: time.Unix(1670681780, 0),
-					end:   time.Unix(1670681790, 0),
-					count: 25,
+					Start: time.Unix(1670681780, 0),
+					End:   time.Unix(1670681790, 0),
+					Count: 25,
 				},
 				{
-					start: time.Unix(1670681790, 0),
-					end:   time.Unix(1670681800, 0),
-					count: 15,
+					Start: time.Unix(1670681790, 0),
+					End:   time.Unix(1670681800, 0),
+					Count: 15,
 				},
 			},
 		},
@@ -266,7 +267,7 @@ func TestTakePoints(t *testing.T) {
 
 				// increment time (60 seconds)
 				func(c *Counter) {
-					newTime := clock.NewMock()
+					newTime := newMockClock()
 					newTime.Set(c.clock.Now().Add(time.Second * 60))
 					c.clock = newTime
 				},
@@ -293,7 +294,7 @@ func TestTakePoints(t *testing.T) {
 
 				// increment time (60 seconds)
 				func(c *Counter) {
-					newTime := clock.NewMock()
+					newTime := newMockClock()
 					newTime.Set(c.clock.Now().Add(time.Second * 60))
 					c.clock = newTime
 				},
@@ -318,16 +319,16 @@ func TestTakePoints(t *testing.T) {
 					}
 				},
 			},
-			expectedResult: []*count{
+			expectedResult: []*Point{
 				{
-					start: time.Unix(1670681760, 0),
-					end:   time.Unix(1670681820, 0),
-					count: 250,
+					Start: time.Unix(1670681760, 0),
+					End:   time.Unix(1670681820, 0),
+					Count: 250,
 				},
 				{
-					start: time.Unix(1670681820, 0),
-					end:   time.Unix(1670681880, 0),
-					count: 50,
+					Start: time.Unix(1670681820, 0),
+					End:   time.Unix(1670681880, 0),
+					Count: 50,
 				},
 			},
 		},
@@ -360,7 +361,7 @@ func TestTakePoints(t *testing.T) {
 
 				// increment time (60 seconds)
 				func(c *Counter) {
-					newTime := clock.NewMock()
+					newTime := newMockClock()
 					newTime.Set(c.clock.Now().Add(time.Second * 60))
 					c.clock = newTime
 				},
@@ -387,7 +388,7 @@ func TestTakePoints(t *testing.T) {
 
 				// increment time (60 seconds)
 				func(c *Counter) {
-					newTime := clock.NewMock()
+					newTime := newMockClock()
 					newTime.Set(c.clock.Now().Add(time.Second * 60))
 					c.clock = newTime
 				},
@@ -412,21 +413,21 @@ func TestTakePoints(t *testing.T) {
 					}
 				},
 			},
-			expectedResult: []*count{
+			expectedResult: []*Point{
 				{
-					start: time.Unix(1670681760, 0),
-					end:   time.Unix(1670681820, 0),
-					count: 250,
+					Start: time.Unix(1670681760, 0),
+					End:   time.Unix(1670681820, 0),
+					Count: 250,
 				},
 				{
-					start: time.Unix(1670681820, 0),
-					end:   time.Unix(1670681880, 0),
-					count: 50,
+					Start: time.Unix(1670681820, 0),
+					End:   time.Unix(1670681880, 0),
+					Count: 50,
 				},
 				{
-					start: time.Unix(1670681880, 0),
-					end:   time.Unix(1670681940, 0),
-					count: 80,
+					Start: time.Unix(1670681880, 0),
+					End:   time.Unix(1670681940, 0),
+					Count: 80,
 				},
 			},
 		},
@@ -434,7 +435,7 @@ func TestTakePoints(t *testing.T) {
 
 	for _, test := range tests {
 
-		clock := clock.NewMock()
+		clock := newMockClock()
 		clock.Set(test.startTime)
 
 		counter := &Counter{
@@ -449,10 +450,10 @@ func TestTakePoints(t *testing.T) {
 		}
 
 		// check counts match
-		assert.ElementsMatchf(t, test.expectedResult, counter.takePoints(test.current), "%s: unexpected counts response", test.name)
+		assert.ElementsMatchf(t, test.expectedResult, counter.takePoints(test.current, 0), "%s: unexpected counts response", test.name)
 
 		// check that no counts remain after last takeCounts
-		assert.ElementsMatchf(t, make([]*count, 0), counter.takePoints(test.current), "%s: unexpected empty counts response", test.name)
+		assert.ElementsMatchf(t, make([]*Point, 0), counter.takePoints(test.current, 0), "%s: unexpected empty counts response", test.name)
 	}
 
 }
@@ -469,7 +470,7 @@ func TestCounter_newCounter(t *testing.T) {
 			name:     "newCounter - normal interval",
 			interval: 10,
 			expectedCounter: &Counter{
-				clock:    clock.New(),
+				clock:    systemClock{},
 				interval: 10,
 				counts:   &sync.Map{},
 				mu:       &sync.Mutex{},
@@ -492,9 +493,178 @@ func TestCounter_newCounter(t *testing.T) {
 
 	for _, test := range tests {
 
-		counter, err := newCounter(test.interval)
+		counter, err := newCounter(test.interval, systemClock{})
 
 		assert.Equalf(t, test.expectedCounter, counter, "%s failed", test.name)
 		assert.Equalf(t, test.expectedError, err, "%s failed", test.name)
 	}
 }
+
+func TestTakePoints_limit(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	counter := &Counter{
+		clock:    mockClock,
+		interval: 10,
+		counts:   &sync.Map{},
+		mu:       &sync.Mutex{},
+	}
+
+	// count across 4 completed intervals
+	for i := 0; i < 4; i++ {
+		counter.Count()
+		mockClock.Add(time.Second * 10)
+	}
+
+	first := counter.takePoints(false, 3)
+	assert.Len(t, first, 3)
+	assert.Equal(t, time.Unix(1670681770, 0), first[0].Start)
+	assert.Equal(t, time.Unix(1670681790, 0), first[2].Start)
+
+	second := counter.takePoints(false, 3)
+	assert.Len(t, second, 1)
+	assert.Equal(t, time.Unix(1670681800, 0), second[0].Start)
+}
+
+func TestCounter_CountAt(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681795, 0))
+
+	counter := &Counter{
+		clock:    mockClock,
+		interval: 10,
+		counts:   &sync.Map{},
+		mu:       &sync.Mutex{},
+	}
+
+	// events attributed to past intervals
+	counter.CountAt(time.Unix(1670681771, 0))
+	counter.CountAt(time.Unix(1670681779, 999))
+	assert.NoError(t, counter.AddAt(time.Unix(1670681785, 0), 5))
+	assert.Equal(t, ErrNegativeValue, counter.AddAt(time.Unix(1670681785, 0), -1))
+
+	// event within the current interval
+	counter.Count()
+
+	assert.Equal(t, []*Point{
+		{
+			Start: time.Unix(1670681770, 0),
+			End:   time.Unix(1670681780, 0),
+			Count: 2,
+		},
+		{
+			Start: time.Unix(1670681780, 0),
+			End:   time.Unix(1670681790, 0),
+			Count: 5,
+		},
+	}, counter.takePoints(false, 0))
+}
+
+func TestCounter_Add(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681775, 0))
+
+	parent := &Counter{
+		clock:    mockClock,
+		interval: 10,
+		counts:   &sync.Map{},
+		mu:       &sync.Mutex{},
+	}
+
+	counter := &Counter{
+		clock:    mockClock,
+		interval: 10,
+		counts:   &sync.Map{},
+		mu:       &sync.Mutex{},
+		parent:   parent,
+	}
+
+	assert.NoError(t, counter.Add(40))
+	assert.NoError(t, counter.Add(2))
+	assert.NoError(t, counter.Add(0))
+	assert.Equal(t, ErrNegativeValue, counter.Add(-3))
+
+	expected := []*Point{
+		{
+			Start: time.Unix(1670681770, 0),
+			End:   time.Unix(1670681780, 0),
+			Count: 42,
+		},
+	}
+
+	// the delta is also added to the parent
+	assert.Equal(t, expected, counter.takePoints(true, 0))
+	assert.Equal(t, expected, parent.takePoints(true, 0))
+}
+
+func TestCounter_RecordEvents(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681795, 0))
+
+	counter := &Counter{
+		clock:    mockClock,
+		interval: 10,
+		counts:   &sync.Map{},
+		mu:       &sync.Mutex{},
+	}
+
+	// events out of order, spanning two completed intervals and the current one
+	counter.RecordEvents([]time.Time{
+		time.Unix(1670681785, 0),
+		time.Unix(1670681771, 0),
+		time.Unix(1670681779, 999),
+		time.Unix(1670681792, 0),
+		time.Unix(1670681780, 0),
+	})
+
+	assert.Equal(t, []*Point{
+		{
+			Start: time.Unix(1670681770, 0),
+			End:   time.Unix(1670681780, 0),
+			Count: 2,
+		},
+		{
+			Start: time.Unix(1670681780, 0),
+			End:   time.Unix(1670681790, 0),
+			Count: 2,
+		},
+		{
+			Start: time.Unix(1670681790, 0),
+			End:   time.Unix(1670681800, 0),
+			Count: 1,
+		},
+	}, counter.takePoints(true, 0))
+}
+
+func TestTakePoints_delay(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	counter := &Counter{
+		clock:    mockClock,
+		interval: 10,
+		counts:   &sync.Map{},
+		mu:       &sync.Mutex{},
+		delay:    time.Second * 5,
+	}
+
+	counter.Count()
+
+	// interval has ended, but delay hasn't passed
+	mockClock.Add(time.Second * 14)
+	assert.Len(t, counter.takePoints(false, 0), 0)
+
+	// late count for the ended interval
+	counter.CountAt(time.Unix(1670681775, 0))
+
+	mockClock.Add(time.Second)
+	points := counter.takePoints(false, 0)
+	assert.Len(t, points, 1)
+	assert.Equal(t, int64(2), points[0].Count)
+}
@@ -0,0 +1,75 @@
+package quantify
+
+import "time"
+
+// poller is implemented by instruments that sample an external value at report
+// time, before the counters are reported.
+type poller interface {
+
+	// poll samples the instrument's value at the provided time.
+	poll(now time.Time)
+}
+
+// derivedCounter records the increase of a monotonically increasing external value
+// into a Counter.
+type derivedCounter struct {
+	counter *Counter
+	fn      func() int64
+	last    int64
+}
+
+// CreateDerivedCounter creates a Counter (see CreateCounter) whose counts are
+// derived from a monotonically increasing external value, such as the number of
+// rows processed reported by a database driver. The value is sampled with fn on
+// each refresh, and the increase since the previous sample is counted.
+//
+// The first sample is taken when the counter is created, so only increases after
+// creation are counted. If a sample is lower than the previous (e.g. because the
+// upstream value was reset by a restart), the upstream value is assumed to have
+// counted up from zero since the reset, and the sample itself is counted.
+//
+// The returned Counter can be used like any other, for example as the parent of a
+// child counter, but shouldn't be counted directly.
+func (q *Quantifier) CreateDerivedCounter(name string, labels map[string]string, interval int64, fn func() int64, options ...MetricOption) (*Counter, error) {
+
+	instrument, err := q.createDerivedCounter(name, labels, interval, fn, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopCounter(), nil
+	}
+
+	return instrument, err
+}
+
+// createDerivedCounter creates a Counter (see CreateDerivedCounter).
+func (q *Quantifier) createDerivedCounter(name string, labels map[string]string, interval int64, fn func() int64, options ...MetricOption) (*Counter, error) {
+
+	counter, err := q.createCounter(nil, name, labels, interval, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	q.pollers = append(q.pollers, &derivedCounter{
+		counter: counter,
+		fn:      fn,
+		last:    fn(),
+	})
+
+	return counter, nil
+}
+
+func (dc *derivedCounter) poll(now time.Time) {
+
+	value := dc.fn()
+
+	delta := value - dc.last
+	if delta < 0 {
+		// upstream value was reset, so has counted up from zero since
+		delta = value
+	}
+
+	dc.last = value
+
+	if delta > 0 {
+		dc.counter.add(now, delta)
+	}
+}
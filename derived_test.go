@@ -0,0 +1,60 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_CreateDerivedCounter(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	upstream := int64(100)
+
+	_, err := client.CreateDerivedCounter("rows_processed", nil, 10, func() int64 {
+		return upstream
+	})
+	assert.NoError(t, err)
+
+	samples := []struct {
+		upstream      int64
+		expectedCount int64
+	}{
+		{upstream: 130, expectedCount: 30},
+		{upstream: 130, expectedCount: 0},
+		{upstream: 145, expectedCount: 15},
+		{upstream: 20, expectedCount: 20}, // upstream reset
+	}
+
+	for _, sample := range samples {
+
+		exporter.series = nil
+		upstream = sample.upstream
+
+		client.report(true)
+
+		var total int64
+		for _, s := range exporter.series {
+			for _, point := range s.Points {
+				total += point.Count
+			}
+		}
+
+		assert.Equalf(t, sample.expectedCount, total, "sample of %d failed", sample.upstream)
+
+		mockClock.Add(time.Second * 10)
+	}
+}
@@ -0,0 +1,146 @@
+// Package distributed provides a mode in which multiple replicas forward their
+// series to a shared backend, from which a single publisher exports the combined
+// series. This avoids a series per replica when a per-instance breakdown isn't
+// wanted.
+//
+// Each replica configures its Quantifier with a Forwarder as its Exporter, and a
+// single replica (e.g. one elected as leader, see Lead) runs a Publisher that
+// exports the forwarded series, summing the counts of points of the same series
+// and interval.
+//
+// The package doesn't depend on a particular backend. Instead, a Transport
+// wrapping the backend of choice (e.g. Redis or Pub/Sub) must be provided.
+//
+// Note: replicas shouldn't attach per-instance labels to their metrics (e.g. with
+// quantify.OptionWithInstanceLabels), or their series won't be combined.
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+)
+
+var (
+	ErrNoTransport = errors.New("no transport provided")
+)
+
+// Transport moves messages from replicas to the publisher, and is implemented by
+// wrapping a shared backend such as a Redis list or a Pub/Sub topic.
+type Transport interface {
+
+	// Publish sends the provided message to the backend.
+	Publish(ctx context.Context, message []byte) error
+
+	// Subscribe calls handler with each message received from the backend until
+	// ctx is cancelled, or an error occurs.
+	Subscribe(ctx context.Context, handler func(message []byte)) error
+}
+
+// Forwarder implements quantify.Exporter, forwarding series to a Transport.
+type Forwarder struct {
+	transport Transport
+}
+
+// NewForwarder returns a Forwarder that forwards series to the provided Transport.
+func NewForwarder(transport Transport) (*Forwarder, error) {
+
+	if transport == nil {
+		return nil, ErrNoTransport
+	}
+
+	return &Forwarder{
+		transport: transport,
+	}, nil
+}
+
+// Export implements quantify.Exporter, publishing the provided series to the
+// Transport as a single message.
+func (f *Forwarder) Export(ctx context.Context, series []*quantify.Series) error {
+
+	message, err := json.Marshal(series)
+	if err != nil {
+		return err
+	}
+
+	return f.transport.Publish(ctx, message)
+}
+
+// Publisher receives the series forwarded by replicas, and periodically exports
+// their combined series.
+type Publisher struct {
+	shared       *quantify.SharedExporter
+	errorHandler func(error)
+	cancel       context.CancelFunc
+	done         chan struct{}
+}
+
+// NewPublisher returns a Publisher that subscribes to the provided Transport, and
+// exports the combined series to the provided Exporter at the provided interval,
+// until Stop is called or ctx is cancelled.
+//
+// errorHandler is called with any error encountered receiving or exporting series,
+// and may be nil if errors should be ignored. Series that fail to export are
+// retried with the next exports, until they're dropped (see
+// quantify.SharedExporter).
+func NewPublisher(ctx context.Context, transport Transport, exporter quantify.Exporter, interval time.Duration, errorHandler func(error)) (*Publisher, error) {
+
+	if transport == nil {
+		return nil, ErrNoTransport
+	}
+
+	if errorHandler == nil {
+		errorHandler = func(error) {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	publisher := &Publisher{
+		shared:       quantify.NewSharedExporter(ctx, exporter, interval, errorHandler),
+		errorHandler: errorHandler,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+
+	go publisher.run(ctx, transport)
+
+	return publisher, nil
+}
+
+// run receives messages from the Transport until ctx is cancelled.
+func (p *Publisher) run(ctx context.Context, transport Transport) {
+
+	defer close(p.done)
+
+	err := transport.Subscribe(ctx, p.receive)
+	if err != nil && ctx.Err() == nil {
+		p.errorHandler(err)
+	}
+}
+
+// receive buffers the series of a single message to be exported.
+func (p *Publisher) receive(message []byte) {
+
+	series := make([]*quantify.Series, 0)
+
+	err := json.Unmarshal(message, &series)
+	if err != nil {
+		p.errorHandler(err)
+		return
+	}
+
+	_ = p.shared.Export(context.Background(), series)
+}
+
+// Stop ceases receiving messages, and exports any series that have already been
+// received.
+func (p *Publisher) Stop() {
+
+	p.cancel()
+	<-p.done
+
+	p.shared.Stop()
+}
@@ -0,0 +1,91 @@
+package distributed
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// mockTransport delivers published messages to its subscriber in memory.
+type mockTransport struct {
+	messages chan []byte
+}
+
+func (mt *mockTransport) Publish(ctx context.Context, message []byte) error {
+	mt.messages <- message
+	return nil
+}
+
+func (mt *mockTransport) Subscribe(ctx context.Context, handler func(message []byte)) error {
+	for {
+		select {
+		case message := <-mt.messages:
+			handler(message)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+type mockExporter struct {
+	mu     sync.Mutex
+	series []*quantify.Series
+}
+
+func (me *mockExporter) Export(ctx context.Context, series []*quantify.Series) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	me.series = append(me.series, series...)
+	return nil
+}
+
+func TestPublisher(t *testing.T) {
+
+	transport := &mockTransport{messages: make(chan []byte)}
+	exporter := &mockExporter{}
+
+	publisher, err := NewPublisher(context.Background(), transport, exporter, time.Hour, nil)
+	assert.NoError(t, err)
+
+	forwarder, err := NewForwarder(transport)
+	assert.NoError(t, err)
+
+	// two replicas forward points for the same series and interval
+	for _, count := range []int64{2, 3} {
+		err = forwarder.Export(context.Background(), []*quantify.Series{
+			{
+				Metric: &quantify.Metric{Name: "jobs", Labels: map[string]string{"queue": "email"}},
+				Points: []*quantify.Point{
+					{Start: time.Unix(1670681770, 0).UTC(), End: time.Unix(1670681780, 0).UTC(), Count: count},
+				},
+			},
+		})
+		assert.NoError(t, err)
+	}
+
+	publisher.Stop()
+
+	assert.Equal(t, []*quantify.Series{
+		{
+			Metric: &quantify.Metric{Name: "jobs", Labels: map[string]string{"queue": "email"}},
+			Points: []*quantify.Point{
+				{Start: time.Unix(1670681770, 0).UTC(), End: time.Unix(1670681780, 0).UTC(), Count: 5},
+			},
+		},
+	}, exporter.series)
+}
+
+func TestNew_noTransport(t *testing.T) {
+
+	_, err := NewForwarder(nil)
+	assert.Equal(t, ErrNoTransport, err)
+
+	_, err = NewPublisher(context.Background(), nil, &mockExporter{}, time.Minute, nil)
+	assert.Equal(t, ErrNoTransport, err)
+}
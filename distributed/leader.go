@@ -0,0 +1,42 @@
+package distributed
+
+import (
+	"context"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Lead returns a function that runs a Publisher (see NewPublisher) until the ctx
+// it is called with is cancelled, exporting any series already received before
+// returning.
+//
+// The returned function matches the OnStartedLeading callback of Kubernetes
+// lease-based leader election (k8s.io/client-go/tools/leaderelection), which
+// cancels the ctx when leadership is lost, so that only the replica holding the
+// lease publishes the combined series whilst all replicas keep counting and
+// forwarding:
+//
+//	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+//		Lock: lock,
+//		Callbacks: leaderelection.LeaderCallbacks{
+//			OnStartedLeading: distributed.Lead(transport, exporter, time.Minute, nil),
+//			OnStoppedLeading: func() {},
+//		},
+//		...
+//	})
+func Lead(transport Transport, exporter quantify.Exporter, interval time.Duration, errorHandler func(error)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+
+		publisher, err := NewPublisher(ctx, transport, exporter, interval, errorHandler)
+		if err != nil {
+			if errorHandler != nil {
+				errorHandler(err)
+			}
+			return
+		}
+
+		<-ctx.Done()
+		publisher.Stop()
+	}
+}
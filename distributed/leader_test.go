@@ -0,0 +1,44 @@
+package distributed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestLead(t *testing.T) {
+
+	transport := &mockTransport{messages: make(chan []byte)}
+	exporter := &mockExporter{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		Lead(transport, exporter, time.Hour, nil)(ctx)
+		close(done)
+	}()
+
+	forwarder, err := NewForwarder(transport)
+	assert.NoError(t, err)
+
+	err = forwarder.Export(context.Background(), []*quantify.Series{
+		{
+			Metric: &quantify.Metric{Name: "jobs"},
+			Points: []*quantify.Point{
+				{Start: time.Unix(1670681770, 0).UTC(), End: time.Unix(1670681780, 0).UTC(), Count: 1},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	// losing leadership publishes the received series
+	cancel()
+	<-done
+
+	assert.Len(t, exporter.series, 1)
+}
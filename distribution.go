@@ -0,0 +1,259 @@
+package quantify
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DistributionValue summarises the observations of a Distribution within the
+// interval of a point.
+type DistributionValue struct {
+
+	// Count is the number of observations, with Mean their mean and
+	// SumOfSquaredDeviation the sum of the squared deviation of each from the mean.
+	Count                 int64
+	Mean                  float64
+	SumOfSquaredDeviation float64
+
+	// Min and Max are the lowest and highest observations.
+	Min float64
+	Max float64
+
+	// Buckets is the bucket layout of the Distribution, and BucketCounts the number
+	// of observations counted into each bucket, starting with the underflow bucket
+	// and ending with the overflow bucket.
+	Buckets      *Buckets
+	BucketCounts []int64
+}
+
+// Distribution is an instrument that records observations, such as request
+// latencies, publishing the distribution of the observations within each interval:
+// their count, mean and the number that fell into each of a set of buckets (see
+// LinearBuckets, ExponentialBuckets and ExplicitBuckets).
+//
+// Note: distributions are published per interval, even when reporting cumulative
+// totals (see OptionWithCumulativeTotals).
+type Distribution struct {
+	mu       *sync.Mutex
+	metric   *Metric
+	clock    Clock
+	interval int64
+	delay    time.Duration
+	buckets  *Buckets
+	bounds   []float64
+
+	// values tracks the distribution of each interval, keyed by its start time
+	// (unix seconds).
+	values map[int64]*DistributionValue
+
+	// noop is set for Distributions that discard observations (see
+	// OptionWithBestEffortInstruments).
+	noop bool
+}
+
+// CreateDistribution creates a Distribution that records observations into the
+// provided buckets over intervals of the provided number of seconds.
+//
+// options allow optional metadata, such as a unit, to be provided as a list of
+// MetricOptions.
+func (q *Quantifier) CreateDistribution(name string, labels map[string]string, interval int64, buckets *Buckets, options ...MetricOption) (*Distribution, error) {
+
+	instrument, err := q.createDistribution(name, labels, interval, buckets, options...)
+	if err != nil && q.noopInstead(err) {
+		return &Distribution{noop: true}, nil
+	}
+
+	return instrument, err
+}
+
+// createDistribution creates a Distribution (see CreateDistribution).
+func (q *Quantifier) createDistribution(name string, labels map[string]string, interval int64, buckets *Buckets, options ...MetricOption) (*Distribution, error) {
+
+	if interval <= 0 {
+		return nil, errors.New("interval must be greater than 0")
+	}
+
+	if buckets == nil {
+		return nil, ErrInvalidBuckets
+	}
+
+	if err := buckets.validate(); err != nil {
+		return nil, err
+	}
+
+	metric := &Metric{
+		Name:      name,
+		Labels:    q.mergeCommonLabels(labels),
+		ValueType: ValueTypeDistribution,
+	}
+
+	for _, option := range options {
+		option(metric)
+	}
+
+	err := q.validateMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.validateInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Distribution{
+		mu:       &sync.Mutex{},
+		metric:   metric,
+		clock:    q.clock,
+		interval: interval,
+		delay:    q.reportingDelay,
+		buckets:  buckets,
+		bounds:   buckets.Boundaries(),
+		values:   make(map[int64]*DistributionValue),
+	}
+
+	q.recorders = append(q.recorders, d)
+
+	return d, nil
+}
+
+// Record records an observation of the provided value in the current interval.
+func (d *Distribution) Record(value float64) {
+
+	if d.noop {
+		return
+	}
+
+	key := d.clock.Now().Truncate(time.Second * time.Duration(d.interval)).Unix()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	dv, ok := d.values[key]
+	if !ok {
+		dv = &DistributionValue{
+			Min:          value,
+			Max:          value,
+			Buckets:      d.buckets,
+			BucketCounts: make([]int64, len(d.bounds)+1),
+		}
+		d.values[key] = dv
+	}
+
+	// update the mean and sum of squared deviation incrementally (Welford)
+	dv.Count++
+	delta := value - dv.Mean
+	dv.Mean += delta / float64(dv.Count)
+	dv.SumOfSquaredDeviation += delta * (value - dv.Mean)
+
+	if value < dv.Min {
+		dv.Min = value
+	}
+
+	if value > dv.Max {
+		dv.Max = value
+	}
+
+	dv.BucketCounts[bucketIndex(d.bounds, value)]++
+}
+
+// recordedMetric implements recorder, returning the metric of the Distribution.
+func (d *Distribution) recordedMetric() *Metric {
+	return d.metric
+}
+
+// takePoints retrieves the distributions of intervals that have passed, and removes
+// them from the Distribution (see Counter.takePoints).
+func (d *Distribution) takePoints(current bool, limit int) []*Point {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// intervals are only complete once the reporting delay has also passed
+	currentFrame := d.clock.Now().Add(-d.delay).Truncate(time.Second * time.Duration(d.interval)).Unix()
+
+	keys := make([]int64, 0, len(d.values))
+	for key := range d.values {
+		if current || key < currentFrame {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	points := make([]*Point, 0, len(keys))
+
+	for _, key := range keys {
+
+		points = append(points, &Point{
+			Start:        time.Unix(key, 0),
+			End:          time.Unix(key+d.interval, 0),
+			Distribution: d.values[key],
+		})
+
+		delete(d.values, key)
+	}
+
+	return points
+}
+
+// mergeDistributions returns the distribution of the observations of both of the
+// provided distributions, as if they'd been recorded into one, without modifying
+// either. Either may be nil, and bucket counts are summed bucket by bucket, so
+// both should share the same buckets.
+func mergeDistributions(a, b *DistributionValue) *DistributionValue {
+
+	if a == nil && b == nil {
+		return nil
+	}
+
+	if a == nil || a.Count == 0 && b != nil && b.Count > 0 {
+		a, b = b, a
+	}
+
+	merged := *a
+	merged.BucketCounts = append([]int64(nil), a.BucketCounts...)
+
+	if b == nil || b.Count == 0 {
+		return &merged
+	}
+
+	// combine the means and sums of squared deviation of the two sets (Chan et al.)
+	count := a.Count + b.Count
+	delta := b.Mean - a.Mean
+
+	merged.Count = count
+	merged.Mean = a.Mean + delta*float64(b.Count)/float64(count)
+	merged.SumOfSquaredDeviation = a.SumOfSquaredDeviation + b.SumOfSquaredDeviation +
+		delta*delta*float64(a.Count)*float64(b.Count)/float64(count)
+
+	if b.Min < merged.Min {
+		merged.Min = b.Min
+	}
+
+	if b.Max > merged.Max {
+		merged.Max = b.Max
+	}
+
+	for i, n := range b.BucketCounts {
+		if i < len(merged.BucketCounts) {
+			merged.BucketCounts[i] += n
+		} else {
+			merged.BucketCounts = append(merged.BucketCounts, n)
+		}
+	}
+
+	if merged.Buckets == nil {
+		merged.Buckets = b.Buckets
+	}
+
+	return &merged
+}
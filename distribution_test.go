@@ -0,0 +1,117 @@
+package quantify
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_CreateDistribution(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		interval    int64
+		buckets     *Buckets
+		expectedErr error
+	}{
+		{
+			name:     "valid",
+			interval: 60,
+			buckets:  ExponentialBuckets(10, 2, 1),
+		},
+		{
+			name:        "no buckets",
+			interval:    60,
+			buckets:     nil,
+			expectedErr: ErrInvalidBuckets,
+		},
+		{
+			name:        "invalid buckets",
+			interval:    60,
+			buckets:     LinearBuckets(-1, 10, 0),
+			expectedErr: ErrInvalidBuckets,
+		},
+	}
+
+	for _, test := range tests {
+
+		client := &Quantifier{
+			clock:    newMockClock(),
+			exporter: &mockExporter{},
+		}
+
+		_, err := client.CreateDistribution("latency", nil, test.interval, test.buckets)
+		assert.Equalf(t, test.expectedErr, err, "%s failed", test.name)
+	}
+}
+
+func TestDistribution_report(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681765, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	buckets := ExplicitBuckets(10, 50, 100)
+
+	distribution, err := client.CreateDistribution("latency", nil, 60, buckets, MetricOptionWithUnit("ms"))
+	assert.NoError(t, err)
+
+	for _, value := range []float64{5, 20, 40, 60, 125} {
+		distribution.Record(value)
+	}
+
+	// the interval in progress isn't reported
+	assert.NoError(t, client.report(false))
+	assert.Empty(t, exporter.series)
+
+	mockClock.Add(time.Minute)
+	assert.NoError(t, client.report(false))
+
+	if assert.Len(t, exporter.series, 1) && assert.Len(t, exporter.series[0].Points, 1) {
+
+		assert.Equal(t, ValueTypeDistribution, exporter.series[0].Metric.ValueType)
+		assert.Equal(t, "ms", exporter.series[0].Metric.Unit)
+
+		point := exporter.series[0].Points[0]
+		assert.Equal(t, time.Unix(1670681760, 0), point.Start)
+		assert.Equal(t, time.Unix(1670681820, 0), point.End)
+
+		dv := point.Distribution
+		if assert.NotNil(t, dv) {
+			assert.Equal(t, int64(5), dv.Count)
+			assert.InDelta(t, 50, dv.Mean, 1e-9)
+			assert.InDelta(t, 8750, dv.SumOfSquaredDeviation, 1e-9)
+			assert.Equal(t, 5.0, dv.Min)
+			assert.Equal(t, 125.0, dv.Max)
+			assert.Equal(t, buckets, dv.Buckets)
+			assert.Equal(t, []int64{1, 2, 1, 1}, dv.BucketCounts)
+		}
+	}
+}
+
+func TestDistribution_bestEffort(t *testing.T) {
+
+	client := &Quantifier{
+		clock:        newMockClock(),
+		exporter:     &mockExporter{},
+		errorHandler: func(q *Quantifier, err error) {},
+		mu:           &sync.Mutex{},
+		bestEffort:   true,
+	}
+
+	distribution, err := client.CreateDistribution("latency", nil, 60, nil)
+	assert.NoError(t, err)
+
+	// observations are discarded
+	distribution.Record(10)
+	assert.Empty(t, client.recorders)
+}
@@ -0,0 +1,119 @@
+package quantify
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+const (
+
+	// ErrorTypeLabelKey is the label key that CountError records the type of each
+	// error with.
+	ErrorTypeLabelKey = "error_type"
+
+	// ErrorTypeOther is the type of errors that don't match any registered type.
+	ErrorTypeOther = "other"
+)
+
+var (
+	ErrInvalidErrorType = errors.New("error type must be a non-nil pointer to a type implementing error")
+)
+
+var (
+	errorTypesMu sync.RWMutex
+
+	// errorTypes are the registered types that errors are classified by, in order
+	// of registration (see ClassifyError).
+	errorTypes []errorType
+)
+
+// errorType is a named type of error, matched by match.
+type errorType struct {
+	name  string
+	match func(err error) bool
+}
+
+// RegisterErrorType registers the type of error pointed to by target, which is
+// matched with errors.As, under the provided name, e.g.
+//
+//	quantify.RegisterErrorType("network", new(*net.OpError))
+//
+// Types are typically registered when a program starts. Errors are classified by
+// the first registered type they match, so more specific types should be
+// registered first.
+func RegisterErrorType(name string, target any) error {
+
+	if target == nil {
+		return ErrInvalidErrorType
+	}
+
+	typ := reflect.TypeOf(target)
+	if typ.Kind() != reflect.Pointer || reflect.ValueOf(target).IsNil() {
+		return ErrInvalidErrorType
+	}
+
+	if !typ.Elem().Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		return ErrInvalidErrorType
+	}
+
+	registerErrorType(name, func(err error) bool {
+		return errors.As(err, reflect.New(typ.Elem()).Interface())
+	})
+
+	return nil
+}
+
+// RegisterError registers an error value, such as io.EOF or
+// context.DeadlineExceeded, which is matched with errors.Is, under the provided
+// name (see RegisterErrorType).
+func RegisterError(name string, target error) {
+	registerErrorType(name, func(err error) bool {
+		return errors.Is(err, target)
+	})
+}
+
+// registerErrorType adds a type of error to those errors are classified by.
+func registerErrorType(name string, match func(err error) bool) {
+
+	errorTypesMu.Lock()
+	defer errorTypesMu.Unlock()
+
+	errorTypes = append(errorTypes, errorType{
+		name:  name,
+		match: match,
+	})
+}
+
+// ClassifyError returns the name of the first registered type that the provided
+// error matches (see RegisterErrorType and RegisterError), or ErrorTypeOther if
+// it matches none. As errors are classified by a fixed set of types, the result is
+// safe to use as a label value without creating unbounded numbers of series.
+func ClassifyError(err error) string {
+
+	errorTypesMu.RLock()
+	defer errorTypesMu.RUnlock()
+
+	for _, et := range errorTypes {
+		if et.match(err) {
+			return et.name
+		}
+	}
+
+	return ErrorTypeOther
+}
+
+// CountError counts the provided error towards the series of the CounterVec for
+// its type (see ClassifyError), given by the ErrorTypeLabelKey label. Any other
+// label keys of the CounterVec must be given values as key/value pairs (see
+// CounterVec.With). Nil errors aren't counted.
+func CountError(vec *CounterVec, err error, keysAndValues ...string) {
+
+	if err == nil {
+		return
+	}
+
+	keysAndValues = append(keysAndValues[:len(keysAndValues):len(keysAndValues)], ErrorTypeLabelKey, ClassifyError(err))
+
+	vec.With(keysAndValues...).Count()
+}
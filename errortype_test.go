@@ -0,0 +1,143 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resetErrorTypes clears the registered error types, restoring them once the test
+// has completed.
+func resetErrorTypes(t *testing.T) {
+
+	registered := errorTypes
+	errorTypes = nil
+
+	t.Cleanup(func() {
+		errorTypes = registered
+	})
+}
+
+func TestRegisterErrorType(t *testing.T) {
+
+	resetErrorTypes(t)
+
+	var nilTarget *fs.PathError
+
+	tests := []struct {
+		name          string
+		target        any
+		expectedError error
+	}{
+		{
+			name:          "pointer to error type",
+			target:        new(*fs.PathError),
+			expectedError: nil,
+		},
+		{
+			name:          "nil",
+			target:        nil,
+			expectedError: ErrInvalidErrorType,
+		},
+		{
+			name:          "nil pointer",
+			target:        nilTarget,
+			expectedError: ErrInvalidErrorType,
+		},
+		{
+			name:          "not a pointer",
+			target:        fs.PathError{},
+			expectedError: ErrInvalidErrorType,
+		},
+		{
+			name:          "pointer to non-error type",
+			target:        new(string),
+			expectedError: ErrInvalidErrorType,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expectedError, RegisterErrorType(test.name, test.target), "%s failed", test.name)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+
+	resetErrorTypes(t)
+
+	RegisterError("timeout", context.DeadlineExceeded)
+	assert.NoError(t, RegisterErrorType("path", new(*fs.PathError)))
+
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "sentinel",
+			err:      context.DeadlineExceeded,
+			expected: "timeout",
+		},
+		{
+			name:     "wrapped sentinel",
+			err:      fmt.Errorf("fetching planes: %w", context.DeadlineExceeded),
+			expected: "timeout",
+		},
+		{
+			name:     "type",
+			err:      fmt.Errorf("loading: %w", &fs.PathError{Op: "open", Path: "planes.json", Err: fs.ErrNotExist}),
+			expected: "path",
+		},
+		{
+			name:     "unregistered",
+			err:      errors.New("engine failure"),
+			expected: ErrorTypeOther,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, ClassifyError(test.err), "%s failed", test.name)
+	}
+}
+
+func TestCountError(t *testing.T) {
+
+	resetErrorTypes(t)
+	RegisterError("timeout", context.DeadlineExceeded)
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681775, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	vec, err := client.CreateCounterVec("errors", []string{"operation", ErrorTypeLabelKey}, 10)
+	assert.NoError(t, err)
+
+	CountError(vec, context.DeadlineExceeded, "operation", "fetch")
+	CountError(vec, fmt.Errorf("fetching: %w", context.DeadlineExceeded), "operation", "fetch")
+	CountError(vec, errors.New("engine failure"), "operation", "fetch")
+	CountError(vec, nil, "operation", "fetch")
+
+	mockClock.Add(time.Second * 10)
+	assert.NoError(t, client.report(false))
+
+	counts := make(map[string]int64)
+	for _, s := range exporter.series {
+		counts[s.Metric.Labels[ErrorTypeLabelKey]] = s.Points[0].Count
+	}
+
+	assert.Equal(t, map[string]int64{"timeout": 2, ErrorTypeOther: 1}, counts)
+}
@@ -10,6 +10,7 @@ import (
 	"google.golang.org/api/option"
 
 	"github.com/rustedturnip/quantify"
+	"github.com/rustedturnip/quantify/gcms"
 )
 
 func main() {
@@ -19,13 +20,22 @@ func main() {
 	// google cloud monitoring client
 	m, err := monitoring.NewMetricClient(ctx, option.WithCredentialsFile("/path/to/file.json"))
 
-	// Quantify client
-	cli, err := quantify.New(
+	// Google Cloud Monitoring exporter
+	exporter, err := gcms.New(
 		ctx,
-		quantify.OptionWithCloudMetricsClient(m),
-		quantify.OptionWithResourceType(&quantify.ResourceGlobal{
+		gcms.OptionWithCloudMetricsClient(m),
+		gcms.OptionWithResourceType(&gcms.ResourceGlobal{
 			ProjectId: "quantify",
 		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	// Quantify client
+	cli, err := quantify.New(
+		ctx,
+		quantify.OptionWithExporter(exporter),
 		quantify.OptionWithErrorHandler(func(quantifier *quantify.Quantifier, err error) {
 			log.Fatal(err)
 		}),
@@ -0,0 +1,168 @@
+package quantify
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Exporter defines a destination that a Quantifier pushes its recorded metrics
+// to, for example Google Cloud Monitoring (see the gcms subpackage).
+type Exporter interface {
+
+	// Export pushes the provided series to the exporter's backend. Each series
+	// contains the points that have been collected since the last export.
+	Export(ctx context.Context, series []*Series) error
+}
+
+// MetricValidator can optionally be implemented by an Exporter to validate a
+// Metric against the exporter's naming rules when it is created, rather than
+// when it is first exported.
+type MetricValidator interface {
+
+	// ValidateMetric returns an error if the provided Metric can't be exported.
+	ValidateMetric(metric *Metric) error
+}
+
+// WriteIntervalLimiter can optionally be implemented by an Exporter whose backend
+// rejects points written to a series more frequently than a minimum interval, so
+// that intervals that would be rejected are caught when the Quantifier and its
+// counters are created, rather than when points are exported.
+type WriteIntervalLimiter interface {
+
+	// MinWriteInterval returns the minimum interval between the points of a series.
+	MinWriteInterval() time.Duration
+}
+
+// minWriteInterval returns the minimum interval between the points of a series
+// written to the provided exporter, or 0 if it has none.
+func minWriteInterval(exporter Exporter) time.Duration {
+
+	if limiter, ok := exporter.(WriteIntervalLimiter); ok {
+		return limiter.MinWriteInterval()
+	}
+
+	return 0
+}
+
+// CountSummer can optionally be implemented by an Exporter whose backend sums the
+// points of counters (e.g. into per-minute aggregates), and so must be sent the
+// count of each interval rather than running totals, which it would count again
+// with every report (see OptionWithCumulativeTotals).
+type CountSummer interface {
+
+	// SumsCounts returns whether the backend sums the points of counters.
+	SumsCounts() bool
+}
+
+// sumsCounts returns whether the backend of the provided exporter sums the points
+// of counters (see CountSummer).
+func sumsCounts(exporter Exporter) bool {
+
+	if summer, ok := exporter.(CountSummer); ok {
+		return summer.SumsCounts()
+	}
+
+	return false
+}
+
+// LengthLimits are the maximum lengths, in bytes, of the names and labels of the
+// metrics accepted by an Exporter's backend. A limit of 0 means there is none.
+type LengthLimits struct {
+
+	// Name is the maximum length of a metric's name.
+	Name int
+
+	// LabelKey is the maximum length of a label key.
+	LabelKey int
+
+	// LabelValue is the maximum length of a label value.
+	LabelValue int
+}
+
+// LengthLimiter can optionally be implemented by an Exporter whose backend limits
+// the lengths of metric names and labels, so that over-long names and labels can
+// be truncated rather than rejected (see OptionWithTruncation).
+type LengthLimiter interface {
+
+	// LengthLimits returns the maximum lengths of names and labels.
+	LengthLimits() LengthLimits
+}
+
+// ProjectScoped can optionally be implemented by an Exporter that writes to a
+// single project of its backend (see Quantifier.ProjectPath).
+type ProjectScoped interface {
+
+	// ProjectPath returns the resource name of the project, e.g.
+	// "projects/my-project".
+	ProjectPath() string
+}
+
+// ProjectPath returns the resource name of the project that the Quantifier's
+// Exporter writes to (e.g. "projects/my-project"), so that callers can verify the
+// target of their metrics, or an empty string if the Exporter doesn't implement
+// ProjectScoped.
+func (q *Quantifier) ProjectPath() string {
+
+	if scoped, ok := q.exporter.(ProjectScoped); ok {
+		return scoped.ProjectPath()
+	}
+
+	return ""
+}
+
+// discardExporter implements Exporter, discarding all series.
+type discardExporter struct{}
+
+func (de discardExporter) Export(ctx context.Context, series []*Series) error {
+	return nil
+}
+
+// Point represents a tally over a duration of time. For metrics of MetricKindGauge
+// the Start and End are equal, representing a measurement at a single instant.
+type Point struct {
+
+	// Start is used to mark the point's duration start time (inclusive).
+	Start time.Time
+
+	// End is used to mark the point's duration end time (exclusive).
+	End time.Time
+
+	// Count is the total recorded within the specified duration, used by metrics
+	// of ValueTypeInt64.
+	Count int64
+
+	// Value is the value of the point, used by metrics of ValueTypeDouble.
+	Value float64
+
+	// Distribution summarises the observations within the specified duration, used
+	// by metrics of ValueTypeDistribution.
+	Distribution *DistributionValue
+}
+
+// Series pairs a Metric with the points that have been recorded for it.
+type Series struct {
+	Metric *Metric
+	Points []*Point
+}
+
+// SortSeries sorts the provided series by metric name and then labels, so that
+// exporters can produce requests that are identical across flushes of the same
+// series (e.g. for request diffing or caching by proxies). Series of a CounterGroup
+// are sorted after ungrouped series, adjacent to the rest of their group.
+func SortSeries(series []*Series) {
+
+	keys := make(map[*Series]string, len(series))
+	for _, s := range series {
+		keys[s] = metricKey(s.Metric)
+	}
+
+	sort.SliceStable(series, func(i, j int) bool {
+
+		if series[i].Metric.Group != series[j].Metric.Group {
+			return series[i].Metric.Group < series[j].Metric.Group
+		}
+
+		return keys[series[i]] < keys[series[j]]
+	})
+}
@@ -0,0 +1,95 @@
+package quantify
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockExporter implements Exporter and MetricValidator, recording any series
+// that are exported to it.
+type mockExporter struct {
+	mu            sync.Mutex
+	series        []*Series
+	exportErr     error
+	validationErr error
+}
+
+func (me *mockExporter) Export(ctx context.Context, series []*Series) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	me.series = append(me.series, series...)
+	return me.exportErr
+}
+
+func (me *mockExporter) ValidateMetric(metric *Metric) error {
+	return me.validationErr
+}
+
+func TestSortSeries(t *testing.T) {
+
+	series := []*Series{
+		{Metric: &Metric{Name: "planes", Labels: map[string]string{"model": "737-900"}}},
+		{Metric: &Metric{Name: "cache_hits", Group: "cache"}},
+		{Metric: &Metric{Name: "boats"}},
+		{Metric: &Metric{Name: "planes", Labels: map[string]string{"model": "737-800", "airline": "ba"}}},
+		{Metric: &Metric{Name: "planes", Labels: map[string]string{"model": "737-800"}}},
+		{Metric: &Metric{Name: "cache_misses", Group: "cache"}},
+	}
+
+	SortSeries(series)
+
+	// series of a group follow ungrouped series, adjacent to one another
+	assert.Equal(t, []*Series{
+		{Metric: &Metric{Name: "boats"}},
+		{Metric: &Metric{Name: "planes", Labels: map[string]string{"model": "737-800", "airline": "ba"}}},
+		{Metric: &Metric{Name: "planes", Labels: map[string]string{"model": "737-800"}}},
+		{Metric: &Metric{Name: "planes", Labels: map[string]string{"model": "737-900"}}},
+		{Metric: &Metric{Name: "cache_hits", Group: "cache"}},
+		{Metric: &Metric{Name: "cache_misses", Group: "cache"}},
+	}, series)
+}
+
+// projectExporter implements Exporter and ProjectScoped.
+type projectExporter struct {
+	mockExporter
+}
+
+func (pe *projectExporter) ProjectPath() string {
+	return "projects/quantify"
+}
+
+func TestQuantifier_ProjectPath(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		exporter     Exporter
+		expectedPath string
+	}{
+		{
+			name:         "project scoped",
+			exporter:     &projectExporter{},
+			expectedPath: "projects/quantify",
+		},
+		{
+			name:         "shared",
+			exporter:     &SharedExporter{exporter: &projectExporter{}},
+			expectedPath: "projects/quantify",
+		},
+		{
+			name:         "unscoped",
+			exporter:     &mockExporter{},
+			expectedPath: "",
+		},
+	}
+
+	for _, test := range tests {
+
+		client := &Quantifier{exporter: test.exporter}
+
+		assert.Equalf(t, test.expectedPath, client.ProjectPath(), "%s failed", test.name)
+	}
+}
@@ -0,0 +1,216 @@
+// Package file provides a quantify.Exporter that appends each exported point to a
+// file as newline-delimited JSON, for air-gapped environments, or for points to be
+// uploaded in batches later.
+//
+// Each line is a single record:
+//
+//	{"metric":"planes","labels":{"manufacturer":"boeing"},"kind":"CUMULATIVE",...}
+//
+// The file can be rotated once it reaches a maximum size (see OptionWithMaxSize)
+// or age (see OptionWithMaxAge), renaming it with the time of rotation, e.g.
+// "metrics.ndjson" becomes "metrics-20230102T210230.000000000.ndjson", and
+// starting a new file at the original path. Rotated files are never removed.
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+	"github.com/rustedturnip/quantify/internal/record"
+)
+
+const (
+
+	// rotatedTimeFormat is the format of the time of rotation within the names of
+	// rotated files, which sort in the order they were rotated.
+	rotatedTimeFormat = "20060102T150405.000000000"
+
+	filePerm = 0o644
+)
+
+var (
+	ErrNoPath = errors.New("no path provided")
+	ErrClosed = errors.New("exporter is closed")
+)
+
+// Exporter implements quantify.Exporter, appending points to a file.
+type Exporter struct {
+	mu      *sync.Mutex
+	path    string
+	now     func() time.Time
+	maxSize int64
+	maxAge  time.Duration
+
+	// file is the file being written to, with size bytes, which was opened at
+	// opened. file is nil once the Exporter has been closed.
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// New returns an instantiated Exporter that appends to the file at the provided
+// path, creating it if it doesn't exist, or returns an error if instantiation
+// fails.
+//
+// options allow the user to provide custom configurations as a list of Options.
+// If any options are invalid, a quantify.ValidationErrors describing every
+// problem is returned.
+func New(path string, options ...Option) (*Exporter, error) {
+
+	if path == "" {
+		return nil, ErrNoPath
+	}
+
+	exporter := &Exporter{
+		mu:   &sync.Mutex{},
+		path: path,
+		now:  time.Now,
+	}
+
+	// apply every option, so that all problems are reported together
+	var errs quantify.ValidationErrors
+
+	for _, option := range options {
+		err := option(exporter)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := exporter.open(); err != nil {
+		return nil, err
+	}
+
+	return exporter, nil
+}
+
+// Export implements quantify.Exporter, appending a line for each point of the
+// provided series, rotating the file first if the line would take it over its
+// maximum size, or if it has reached its maximum age.
+func (e *Exporter) Export(ctx context.Context, series []*quantify.Series) error {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.file == nil {
+		return ErrClosed
+	}
+
+	for _, r := range record.FromSeries(series) {
+
+		encoded, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, '\n')
+
+		if e.shouldRotate(int64(len(encoded))) {
+			if err := e.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := e.file.Write(encoded)
+		e.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close implements io.Closer, closing the file. Close is safe to call multiple
+// times, and any points exported afterwards are rejected with ErrClosed.
+func (e *Exporter) Close() error {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.file == nil {
+		return nil
+	}
+
+	err := e.file.Close()
+	e.file = nil
+
+	return err
+}
+
+// open opens the file at the Exporter's path for appending, creating it if it
+// doesn't exist.
+func (e *Exporter) open() error {
+
+	file, err := os.OpenFile(e.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, filePerm)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	e.file = file
+	e.size = info.Size()
+	e.opened = e.now()
+
+	return nil
+}
+
+// shouldRotate returns whether the file should be rotated before writing a line
+// of the provided length. Empty files are never rotated, so that a line larger
+// than the maximum size is still written.
+func (e *Exporter) shouldRotate(length int64) bool {
+
+	if e.size == 0 {
+		return false
+	}
+
+	if e.maxSize > 0 && e.size+length > e.maxSize {
+		return true
+	}
+
+	return e.maxAge > 0 && e.now().Sub(e.opened) >= e.maxAge
+}
+
+// rotate closes the file, renames it with the time of rotation, and opens a new
+// file at the Exporter's path.
+func (e *Exporter) rotate() error {
+
+	if err := e.file.Close(); err != nil {
+		return err
+	}
+	e.file = nil
+
+	// if the file can't be renamed, keep appending to it rather than losing points
+	if err := os.Rename(e.path, rotatedPath(e.path, e.now())); err != nil {
+		if openErr := e.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+
+	return e.open()
+}
+
+// rotatedPath returns the path that the file at the provided path is renamed to
+// when rotated at the provided time, with the time inserted before the extension.
+func rotatedPath(path string, at time.Time) string {
+
+	ext := filepath.Ext(path)
+
+	return strings.TrimSuffix(path, ext) + "-" + at.UTC().Format(rotatedTimeFormat) + ext
+}
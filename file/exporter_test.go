@@ -0,0 +1,196 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// pointSeries returns a series with a single point of the provided count.
+func pointSeries(count int64) []*quantify.Series {
+	return []*quantify.Series{
+		{
+			Metric: &quantify.Metric{
+				Name:   "planes",
+				Labels: map[string]string{"manufacturer": "boeing"},
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: count,
+				},
+			},
+		},
+	}
+}
+
+// readFiles returns the contents of each file within the provided directory, in
+// order of name.
+func readFiles(t *testing.T, dir string) []string {
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	contents := make([]string, 0, len(names))
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		contents = append(contents, string(content))
+	}
+
+	return contents
+}
+
+func TestExporter_Export(t *testing.T) {
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metrics.ndjson")
+
+	exporter, err := New(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, exporter.Export(context.Background(), pointSeries(3)))
+	assert.NoError(t, exporter.Close())
+
+	// appended to when reopened
+	exporter, err = New(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, exporter.Export(context.Background(), pointSeries(5)))
+	assert.NoError(t, exporter.Close())
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.JSONEq(t, `{
+			"metric": "planes",
+			"labels": {"manufacturer": "boeing"},
+			"kind": "CUMULATIVE",
+			"value_type": "INT64",
+			"start": "2023-01-02T21:02:20Z",
+			"end": "2023-01-02T21:02:30Z",
+			"count": 5
+		}`, lines[1])
+	}
+}
+
+func TestExporter_Export_rotateSize(t *testing.T) {
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metrics.ndjson")
+
+	line := `{"metric":"planes","labels":{"manufacturer":"boeing"},"kind":"CUMULATIVE","value_type":"INT64",` +
+		`"start":"2023-01-02T21:02:20Z","end":"2023-01-02T21:02:30Z","count":1}` + "\n"
+
+	// room for 2 lines per file
+	exporter, err := New(path, OptionWithMaxSize(int64(len(line)*2)))
+	assert.NoError(t, err)
+
+	now := time.Unix(1672693351, 0)
+	exporter.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, exporter.Export(context.Background(), pointSeries(1)))
+	}
+	assert.NoError(t, exporter.Close())
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	// rotated files sort before the current file, oldest first
+	assert.Equal(t, []string{line + line, line + line, line}, readFiles(t, dir))
+}
+
+func TestExporter_Export_rotateAge(t *testing.T) {
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metrics.ndjson")
+
+	now := time.Unix(1672693351, 0)
+
+	exporter, err := New(path, OptionWithMaxAge(time.Hour))
+	assert.NoError(t, err)
+
+	exporter.now = func() time.Time {
+		return now
+	}
+	exporter.opened = now
+
+	assert.NoError(t, exporter.Export(context.Background(), pointSeries(1)))
+
+	now = now.Add(time.Minute * 59)
+	assert.NoError(t, exporter.Export(context.Background(), pointSeries(2)))
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, exporter.Export(context.Background(), pointSeries(3)))
+	assert.NoError(t, exporter.Close())
+
+	_, err = os.Stat(filepath.Join(dir, "metrics-20230102T220231.000000000.ndjson"))
+	assert.NoError(t, err)
+
+	contents := readFiles(t, dir)
+	if assert.Len(t, contents, 2) {
+		assert.Equal(t, 2, strings.Count(contents[0], "\n"))
+		assert.Equal(t, 1, strings.Count(contents[1], "\n"))
+	}
+}
+
+func TestExporter_Close(t *testing.T) {
+
+	exporter, err := New(filepath.Join(t.TempDir(), "metrics.ndjson"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, exporter.Close())
+	assert.NoError(t, exporter.Close())
+
+	assert.Equal(t, ErrClosed, exporter.Export(context.Background(), pointSeries(1)))
+}
+
+func TestRotatedPath(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "extension", input: "/var/metrics.ndjson", expected: "/var/metrics-20230102T210231.000000000.ndjson"},
+		{name: "no extension", input: "/var/metrics", expected: "/var/metrics-20230102T210231.000000000"},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, rotatedPath(test.input, time.Unix(1672693351, 0)), "%s failed", test.name)
+	}
+}
+
+func TestNew(t *testing.T) {
+
+	_, err := New("")
+	assert.Equal(t, ErrNoPath, err)
+
+	_, err = New(filepath.Join(t.TempDir(), "metrics.ndjson"), OptionWithMaxSize(0), OptionWithMaxAge(-time.Hour))
+	assert.EqualError(t, err, "2 configuration problems: max_size: must be greater than 0; max_age: must be greater than 0")
+
+	_, err = New(filepath.Join(t.TempDir(), "missing", "metrics.ndjson"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package file
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Option defines a function for supplying the Exporter constructor with certain
+// configurations.
+type Option func(*Exporter) error
+
+// OptionWithMaxSize sets the maximum size of the file in bytes, beyond which it's
+// rotated. By default, files aren't rotated by size.
+func OptionWithMaxSize(bytes int64) Option {
+	return func(exporter *Exporter) error {
+		if bytes <= 0 {
+			return &quantify.FieldError{Path: "max_size", Err: errors.New("must be greater than 0")}
+		}
+		exporter.maxSize = bytes
+		return nil
+	}
+}
+
+// OptionWithMaxAge sets the maximum time that points are appended to the file
+// before it's rotated, e.g. time.Hour for hourly files. The file is rotated when
+// points are exported after it has reached the age, so a file isn't created for
+// periods without any points. By default, files aren't rotated by age.
+func OptionWithMaxAge(age time.Duration) Option {
+	return func(exporter *Exporter) error {
+		if age <= 0 {
+			return &quantify.FieldError{Path: "max_age", Err: errors.New("must be greater than 0")}
+		}
+		exporter.maxAge = age
+		return nil
+	}
+}
@@ -0,0 +1,174 @@
+package quantify
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FloatCounter is a thread-safe counter of fractional quantities, such as dollars
+// or seconds, publishing the total added within each interval as a point of
+// ValueTypeDouble.
+type FloatCounter struct {
+	mu       *sync.Mutex
+	metric   *Metric
+	clock    Clock
+	interval int64
+	delay    time.Duration
+
+	// totals tracks the total of each interval, keyed by its start time (unix
+	// seconds).
+	totals map[int64]float64
+
+	// cumulative, when set, publishes running totals since start, the start of the
+	// first interval published (see OptionWithCumulativeTotals).
+	cumulative bool
+	start      time.Time
+	total      float64
+
+	// noop is set for FloatCounters that discard values (see
+	// OptionWithBestEffortInstruments).
+	noop bool
+}
+
+// CreateFloatCounter creates a FloatCounter that totals the values added to it over
+// intervals of the provided number of seconds (see CreateCounter).
+//
+// options allow optional metadata, such as a unit, to be provided as a list of
+// MetricOptions.
+func (q *Quantifier) CreateFloatCounter(name string, labels map[string]string, interval int64, options ...MetricOption) (*FloatCounter, error) {
+
+	instrument, err := q.createFloatCounter(name, labels, interval, options...)
+	if err != nil && q.noopInstead(err) {
+		return &FloatCounter{clock: systemClock{}, noop: true}, nil
+	}
+
+	return instrument, err
+}
+
+// createFloatCounter creates a FloatCounter (see CreateFloatCounter).
+func (q *Quantifier) createFloatCounter(name string, labels map[string]string, interval int64, options ...MetricOption) (*FloatCounter, error) {
+
+	if interval <= 0 {
+		return nil, errors.New("interval must be greater than 0")
+	}
+
+	metric := &Metric{
+		Name:      name,
+		Labels:    q.mergeCommonLabels(labels),
+		ValueType: ValueTypeDouble,
+	}
+
+	for _, option := range options {
+		option(metric)
+	}
+
+	err := q.validateMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.validateInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	fc := &FloatCounter{
+		mu:         &sync.Mutex{},
+		metric:     metric,
+		clock:      q.clock,
+		interval:   interval,
+		delay:      q.reportingDelay,
+		totals:     make(map[int64]float64),
+		cumulative: q.cumulative && metric.Kind != MetricKindDelta,
+	}
+
+	q.recorders = append(q.recorders, fc)
+
+	return fc, nil
+}
+
+// Add adds value to the total of the current interval. An error is returned if
+// value is negative.
+func (fc *FloatCounter) Add(value float64) error {
+	return fc.AddAt(fc.clock.Now(), value)
+}
+
+// AddAt adds value to the total of the interval containing the provided time (see
+// Counter.CountAt). An error is returned if value is negative.
+func (fc *FloatCounter) AddAt(t time.Time, value float64) error {
+
+	if value < 0 {
+		return ErrNegativeValue
+	}
+
+	if fc.noop {
+		return nil
+	}
+
+	key := t.Truncate(time.Second * time.Duration(fc.interval)).Unix()
+
+	fc.mu.Lock()
+	fc.totals[key] += value
+	fc.mu.Unlock()
+
+	return nil
+}
+
+// recordedMetric implements recorder, returning the metric of the FloatCounter.
+func (fc *FloatCounter) recordedMetric() *Metric {
+	return fc.metric
+}
+
+// takePoints retrieves the totals of intervals that have passed, and removes them
+// from the FloatCounter (see Counter.takePoints).
+func (fc *FloatCounter) takePoints(current bool, limit int) []*Point {
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	// intervals are only complete once the reporting delay has also passed
+	currentFrame := fc.clock.Now().Add(-fc.delay).Truncate(time.Second * time.Duration(fc.interval)).Unix()
+
+	keys := make([]int64, 0, len(fc.totals))
+	for key := range fc.totals {
+		if current || key < currentFrame {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	points := make([]*Point, 0, len(keys))
+
+	for _, key := range keys {
+
+		point := &Point{
+			Start: time.Unix(key, 0),
+			End:   time.Unix(key+fc.interval, 0),
+			Value: fc.totals[key],
+		}
+
+		if fc.cumulative {
+
+			if fc.start.IsZero() {
+				fc.start = point.Start
+			}
+
+			fc.total += point.Value
+			point.Start, point.Value = fc.start, fc.total
+		}
+
+		points = append(points, point)
+		delete(fc.totals, key)
+	}
+
+	return points
+}
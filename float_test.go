@@ -0,0 +1,66 @@
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFloatCounter_report(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		cumulative     bool
+		expectedPoints []*Point
+	}{
+		{
+			name: "delta",
+			expectedPoints: []*Point{
+				{Start: time.Unix(1670681760, 0), End: time.Unix(1670681770, 0), Value: 1.75},
+				{Start: time.Unix(1670681770, 0), End: time.Unix(1670681780, 0), Value: 0.5},
+			},
+		},
+		{
+			name:       "cumulative",
+			cumulative: true,
+			expectedPoints: []*Point{
+				{Start: time.Unix(1670681760, 0), End: time.Unix(1670681770, 0), Value: 1.75},
+				{Start: time.Unix(1670681760, 0), End: time.Unix(1670681780, 0), Value: 2.25},
+			},
+		},
+	}
+
+	for _, test := range tests {
+
+		mockClock := newMockClock()
+		mockClock.Set(time.Unix(1670681765, 0))
+
+		exporter := &mockExporter{}
+
+		client := &Quantifier{
+			clock:        mockClock,
+			exporter:     exporter,
+			errorHandler: func(q *Quantifier, err error) {},
+			cumulative:   test.cumulative,
+		}
+
+		revenue, err := client.CreateFloatCounter("revenue", nil, 10, MetricOptionWithCurrency("usd"))
+		assert.NoErrorf(t, err, "%s failed", test.name)
+
+		assert.NoErrorf(t, revenue.Add(1.25), "%s failed", test.name)
+		assert.NoErrorf(t, revenue.Add(0.5), "%s failed", test.name)
+		assert.Equalf(t, ErrNegativeValue, revenue.Add(-1), "%s failed", test.name)
+
+		mockClock.Add(time.Second * 10)
+		assert.NoErrorf(t, revenue.Add(0.5), "%s failed", test.name)
+
+		mockClock.Add(time.Second * 10)
+		assert.NoErrorf(t, client.report(false), "%s failed", test.name)
+
+		if assert.Lenf(t, exporter.series, 1, "%s failed", test.name) {
+			assert.Equalf(t, ValueTypeDouble, exporter.series[0].Metric.ValueType, "%s failed", test.name)
+			assert.Equalf(t, test.expectedPoints, exporter.series[0].Points, "%s failed", test.name)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package quantify
+
+import "time"
+
+// Gap describes a period in which exports to the Exporter failed, or points were
+// dropped to stay within the memory limit (see OptionWithMemoryLimit), and therefore
+// the points that were collected during that period were dropped.
+type Gap struct {
+
+	// Start is the time of the first failed export.
+	Start time.Time
+
+	// End is the time of the first successful export after the failures.
+	End time.Time
+
+	// Failures is the number of failed exports within the gap, which is 0 if points
+	// were only dropped to stay within the memory limit.
+	Failures int
+
+	// SeriesAffected is the number of distinct series that had points dropped.
+	SeriesAffected int
+
+	// PointsDropped is the total number of points that were dropped.
+	PointsDropped int
+}
+
+// Duration returns the length of time between the start and end of the Gap.
+func (g *Gap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+// outage tracks an ongoing Gap whilst exports are failing or points are dropped.
+type outage struct {
+	gap    *Gap
+	series map[string]struct{}
+}
+
+// recordFailure adds the provided series, which failed to export at the provided
+// time, to the outage, starting a new outage if one isn't in progress.
+func (o *outage) recordFailure(at time.Time, series []*Series) {
+
+	o.recordDropped(at, series)
+	o.gap.Failures++
+}
+
+// recordDropped adds the provided series, whose points were dropped at the provided
+// time, to the outage, starting a new outage if one isn't in progress.
+func (o *outage) recordDropped(at time.Time, series []*Series) {
+
+	if o.gap == nil {
+		o.gap = &Gap{
+			Start: at,
+		}
+		o.series = make(map[string]struct{})
+	}
+
+	for _, s := range series {
+		o.series[metricKey(s.Metric)] = struct{}{}
+		o.gap.PointsDropped += len(s.Points)
+	}
+
+	o.gap.SeriesAffected = len(o.series)
+}
+
+// recover ends any ongoing outage at the provided time, returning the completed
+// Gap, or nil if there was no outage in progress.
+func (o *outage) recover(at time.Time) *Gap {
+
+	if o.gap == nil {
+		return nil
+	}
+
+	gap := o.gap
+	gap.End = at
+
+	o.gap = nil
+	o.series = nil
+
+	return gap
+}
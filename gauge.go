@@ -0,0 +1,163 @@
+package quantify
+
+import (
+	"sync"
+	"time"
+)
+
+// GaugeAggregation defines how the values provided to a Gauge between reports are
+// combined into the single point published by each report.
+type GaugeAggregation int
+
+const (
+	// GaugeAggregationLast publishes the most recently set value.
+	GaugeAggregationLast GaugeAggregation = iota
+
+	// GaugeAggregationMin publishes the lowest value set.
+	GaugeAggregationMin
+
+	// GaugeAggregationMax publishes the highest value set.
+	GaugeAggregationMax
+
+	// GaugeAggregationMean publishes the mean of the values set.
+	GaugeAggregationMean
+)
+
+// Gauge implements a thread-safe Gauge that can be used to record a value measured
+// at an instant in time, such as a queue depth or temperature, through calling
+// Gauge.Set.
+type Gauge struct {
+	mu          *sync.Mutex
+	metric      *Metric
+	aggregation GaugeAggregation
+
+	// samples, sum, min and max summarise the values set since the previous report.
+	samples int64
+	sum     float64
+	min     float64
+	max     float64
+
+	// last is the value most recently set, and published is the value most recently
+	// published (valid once hasPublished is set).
+	last         float64
+	published    float64
+	hasPublished bool
+}
+
+// CreateGauge creates a Gauge that can be used to track a value measured at an
+// instant in time. The Gauge publishes a single point with each refresh, combining
+// any values set since the previous refresh with the provided aggregation (e.g.
+// GaugeAggregationMax for a peak queue depth).
+//
+// If no values are set between refreshes, the previously published value is
+// published again. Nothing is published until the first value is set.
+//
+// options allow optional metadata, such as a display name, to be provided as a
+// list of MetricOptions.
+func (q *Quantifier) CreateGauge(name string, labels map[string]string, aggregation GaugeAggregation, options ...MetricOption) (*Gauge, error) {
+
+	instrument, err := q.createGauge(name, labels, aggregation, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopGauge(), nil
+	}
+
+	return instrument, err
+}
+
+// createGauge creates a Gauge (see CreateGauge).
+func (q *Quantifier) createGauge(name string, labels map[string]string, aggregation GaugeAggregation, options ...MetricOption) (*Gauge, error) {
+
+	metric := &Metric{
+		Name:      name,
+		Labels:    q.mergeCommonLabels(labels),
+		Kind:      MetricKindGauge,
+		ValueType: ValueTypeDouble,
+	}
+
+	for _, option := range options {
+		option(metric)
+	}
+
+	err := q.validateMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	gauge := &Gauge{
+		mu:          &sync.Mutex{},
+		metric:      metric,
+		aggregation: aggregation,
+	}
+
+	q.collectors = append(q.collectors, gauge)
+
+	return gauge, nil
+}
+
+// Set records the current value of the Gauge.
+func (g *Gauge) Set(value float64) {
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.samples == 0 || value < g.min {
+		g.min = value
+	}
+
+	if g.samples == 0 || value > g.max {
+		g.max = value
+	}
+
+	g.samples++
+	g.sum += value
+	g.last = value
+}
+
+// collect implements collector, publishing the aggregation of the values set since
+// the previous report.
+func (g *Gauge) collect(now time.Time) []*Series {
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.samples > 0 {
+		g.published = g.aggregate()
+		g.hasPublished = true
+	}
+
+	g.samples = 0
+	g.sum = 0
+
+	if !g.hasPublished {
+		return nil
+	}
+
+	return []*Series{
+		{
+			Metric: g.metric,
+			Points: []*Point{
+				{
+					Start: now,
+					End:   now,
+					Value: g.published,
+				},
+			},
+		},
+	}
+}
+
+// aggregate returns the value to publish for the values set since the previous
+// report. There must have been at least one.
+func (g *Gauge) aggregate() float64 {
+
+	switch g.aggregation {
+	case GaugeAggregationMin:
+		return g.min
+	case GaugeAggregationMax:
+		return g.max
+	case GaugeAggregationMean:
+		return g.sum / float64(g.samples)
+	default:
+		return g.last
+	}
+}
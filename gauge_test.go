@@ -0,0 +1,99 @@
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGauge_collect(t *testing.T) {
+
+	now := time.Unix(1670681770, 0)
+
+	tests := []struct {
+		name          string
+		aggregation   GaugeAggregation
+		values        []float64
+		expectedValue float64
+	}{
+		{
+			name:          "last",
+			aggregation:   GaugeAggregationLast,
+			values:        []float64{4, 9, 2, 5},
+			expectedValue: 5,
+		},
+		{
+			name:          "min",
+			aggregation:   GaugeAggregationMin,
+			values:        []float64{4, 9, 2, 5},
+			expectedValue: 2,
+		},
+		{
+			name:          "max",
+			aggregation:   GaugeAggregationMax,
+			values:        []float64{4, 9, 2, 5},
+			expectedValue: 9,
+		},
+		{
+			name:          "mean",
+			aggregation:   GaugeAggregationMean,
+			values:        []float64{4, 9, 2, 5},
+			expectedValue: 5,
+		},
+		{
+			name:          "negative min",
+			aggregation:   GaugeAggregationMin,
+			values:        []float64{-1, -3},
+			expectedValue: -3,
+		},
+	}
+
+	for _, test := range tests {
+
+		client := &Quantifier{
+			clock:    newMockClock(),
+			exporter: &mockExporter{},
+		}
+
+		gauge, err := client.CreateGauge("queue_depth", nil, test.aggregation)
+		assert.NoErrorf(t, err, "%s failed", test.name)
+
+		for _, value := range test.values {
+			gauge.Set(value)
+		}
+
+		series := gauge.collect(now)
+		if assert.Lenf(t, series, 1, "%s failed", test.name) {
+			assert.Equalf(t, MetricKindGauge, series[0].Metric.Kind, "%s failed", test.name)
+			assert.Equalf(t, []*Point{{Start: now, End: now, Value: test.expectedValue}}, series[0].Points, "%s failed", test.name)
+		}
+	}
+}
+
+func TestGauge_collect_unset(t *testing.T) {
+
+	now := time.Unix(1670681770, 0)
+
+	client := &Quantifier{
+		clock:    newMockClock(),
+		exporter: &mockExporter{},
+	}
+
+	gauge, err := client.CreateGauge("queue_depth", nil, GaugeAggregationMax)
+	assert.NoError(t, err)
+
+	// nothing is published before the first value is set
+	assert.Empty(t, gauge.collect(now))
+
+	gauge.Set(3)
+	gauge.Set(7)
+	assert.Equal(t, 7.0, gauge.collect(now)[0].Points[0].Value)
+
+	// the previous value is published again whilst no values are set
+	assert.Equal(t, 7.0, gauge.collect(now.Add(time.Minute))[0].Points[0].Value)
+
+	// aggregation restarts with each report
+	gauge.Set(1)
+	assert.Equal(t, 1.0, gauge.collect(now.Add(time.Minute * 2))[0].Points[0].Value)
+}
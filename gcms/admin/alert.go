@@ -0,0 +1,345 @@
+// Package admin provides optional helpers for provisioning Google Cloud
+// Monitoring resources, such as alert policies, for metrics reported with the
+// gcms exporter.
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3"
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/rustedturnip/quantify/gcms"
+)
+
+const (
+	defaultAlignmentPeriod = time.Minute
+
+	documentationMimeType = "text/markdown"
+)
+
+// Comparison defines how a metric's value is compared with an AlertPolicy's
+// threshold.
+type Comparison int
+
+const (
+	ComparisonGreaterThan Comparison = iota
+	ComparisonLessThan
+)
+
+var (
+	// comparisons maps Comparison values to their Google Cloud equivalent.
+	comparisons = map[Comparison]monitoringpb.ComparisonType{
+		ComparisonGreaterThan: monitoringpb.ComparisonType_COMPARISON_GT,
+		ComparisonLessThan:    monitoringpb.ComparisonType_COMPARISON_LT,
+	}
+)
+
+// NotificationChannel declares a channel that alerts can be sent to. Channels are
+// identified by their DisplayName, which must be unique within the project.
+type NotificationChannel struct {
+
+	// DisplayName identifies the channel, and is referenced by AlertPolicy.
+	DisplayName string
+
+	// Type is the type of the channel, e.g. "email" or "slack".
+	Type string
+
+	// Labels configure the channel, e.g. {"email_address": "team@example.com"}.
+	Labels map[string]string
+}
+
+// AlertPolicy declares a threshold alert on the rate of a metric reported by the
+// gcms exporter. Policies are identified by their DisplayName, which must be
+// unique within the project.
+type AlertPolicy struct {
+
+	// DisplayName identifies the policy.
+	DisplayName string
+
+	// Metric is the name of the metric, as provided to quantify.
+	Metric string
+
+	// Filter optionally narrows the series the policy applies to, e.g.
+	// `metric.label.status = "500"`.
+	Filter string
+
+	// Comparison and Threshold define when the policy is violated, using the rate
+	// (per second) of the metric over AlignmentPeriod.
+	Comparison Comparison
+	Threshold  float64
+
+	// AlignmentPeriod is the period the rate is calculated over, defaulting to a
+	// minute if not set.
+	AlignmentPeriod time.Duration
+
+	// Duration is how long the threshold must be violated for before alerting.
+	Duration time.Duration
+
+	// Documentation is optional markdown included with notifications.
+	Documentation string
+
+	// NotificationChannels are the display names of the channels to notify.
+	NotificationChannels []string
+}
+
+// Provisioner creates, or updates, declared alert policies and notification
+// channels within a project.
+type Provisioner struct {
+	projectId string
+	alerts    *monitoring.AlertPolicyClient
+	channels  *monitoring.NotificationChannelClient
+}
+
+// NewProvisioner returns a Provisioner for the provided project using the provided
+// clients.
+func NewProvisioner(projectId string, alerts *monitoring.AlertPolicyClient, channels *monitoring.NotificationChannelClient) *Provisioner {
+	return &Provisioner{
+		projectId: projectId,
+		alerts:    alerts,
+		channels:  channels,
+	}
+}
+
+// Provision creates, or updates, the provided notification channels and alert
+// policies so that they match their declarations, for example when a service is
+// deployed. Existing resources are matched by display name, and resources that
+// aren't declared are left untouched.
+func (p *Provisioner) Provision(ctx context.Context, channels []*NotificationChannel, policies []*AlertPolicy) error {
+
+	if _, err := gcms.ProjectPath(p.projectId); err != nil {
+		return err
+	}
+
+	channelNames, err := p.provisionChannels(ctx, channels)
+	if err != nil {
+		return err
+	}
+
+	existing, err := p.listAlertPolicies(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+
+		proto, err := p.alertPolicyToProto(policy, channelNames)
+		if err != nil {
+			return err
+		}
+
+		// update if exists
+		if name, ok := existing[policy.DisplayName]; ok {
+
+			proto.Name = name
+
+			_, err := p.alerts.UpdateAlertPolicy(ctx, &monitoringpb.UpdateAlertPolicyRequest{
+				AlertPolicy: proto,
+			})
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		_, err = p.alerts.CreateAlertPolicy(ctx, &monitoringpb.CreateAlertPolicyRequest{
+			Name:        p.projectPath(),
+			AlertPolicy: proto,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// provisionChannels creates, or updates, the provided notification channels,
+// returning a map of each channel's display name to its resource name.
+func (p *Provisioner) provisionChannels(ctx context.Context, channels []*NotificationChannel) (map[string]string, error) {
+
+	existing, err := p.listNotificationChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+
+	for _, channel := range channels {
+
+		proto := notificationChannelToProto(channel)
+
+		// update if exists
+		if name, ok := existing[channel.DisplayName]; ok {
+
+			proto.Name = name
+
+			_, err := p.channels.UpdateNotificationChannel(ctx, &monitoringpb.UpdateNotificationChannelRequest{
+				NotificationChannel: proto,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			names[channel.DisplayName] = name
+			continue
+		}
+
+		created, err := p.channels.CreateNotificationChannel(ctx, &monitoringpb.CreateNotificationChannelRequest{
+			Name:                p.projectPath(),
+			NotificationChannel: proto,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		names[channel.DisplayName] = created.GetName()
+	}
+
+	// include existing channels so policies can reference channels that aren't
+	// declared by this service
+	for displayName, name := range existing {
+		if _, ok := names[displayName]; !ok {
+			names[displayName] = name
+		}
+	}
+
+	return names, nil
+}
+
+// listNotificationChannels returns a map of the display names of the project's
+// existing notification channels to their resource names.
+func (p *Provisioner) listNotificationChannels(ctx context.Context) (map[string]string, error) {
+
+	it := p.channels.ListNotificationChannels(ctx, &monitoringpb.ListNotificationChannelsRequest{
+		Name: p.projectPath(),
+	})
+
+	names := make(map[string]string)
+
+	for {
+		channel, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		names[channel.GetDisplayName()] = channel.GetName()
+	}
+
+	return names, nil
+}
+
+// listAlertPolicies returns a map of the display names of the project's existing
+// alert policies to their resource names.
+func (p *Provisioner) listAlertPolicies(ctx context.Context) (map[string]string, error) {
+
+	it := p.alerts.ListAlertPolicies(ctx, &monitoringpb.ListAlertPoliciesRequest{
+		Name: p.projectPath(),
+	})
+
+	names := make(map[string]string)
+
+	for {
+		policy, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		names[policy.GetDisplayName()] = policy.GetName()
+	}
+
+	return names, nil
+}
+
+// projectPath returns the resource name of the Provisioner's project, which is
+// validated by Provision.
+func (p *Provisioner) projectPath() string {
+
+	projectPath, _ := gcms.ProjectPath(p.projectId)
+	return projectPath
+}
+
+// notificationChannelToProto converts a NotificationChannel into a
+// monitoringpb.NotificationChannel.
+func notificationChannelToProto(channel *NotificationChannel) *monitoringpb.NotificationChannel {
+	return &monitoringpb.NotificationChannel{
+		Type:        channel.Type,
+		DisplayName: channel.DisplayName,
+		Labels:      channel.Labels,
+	}
+}
+
+// alertPolicyToProto converts an AlertPolicy into a monitoringpb.AlertPolicy,
+// resolving its notification channels using the provided map of display names to
+// resource names.
+func (p *Provisioner) alertPolicyToProto(policy *AlertPolicy, channelNames map[string]string) (*monitoringpb.AlertPolicy, error) {
+
+	channels := make([]string, 0, len(policy.NotificationChannels))
+
+	for _, displayName := range policy.NotificationChannels {
+
+		name, ok := channelNames[displayName]
+		if !ok {
+			return nil, fmt.Errorf("unknown notification channel: %s", displayName)
+		}
+
+		channels = append(channels, name)
+	}
+
+	alignmentPeriod := policy.AlignmentPeriod
+	if alignmentPeriod == 0 {
+		alignmentPeriod = defaultAlignmentPeriod
+	}
+
+	filter := fmt.Sprintf("metric.type = %q", gcms.MetricType(policy.Metric))
+	if policy.Filter != "" {
+		filter = fmt.Sprintf("%s AND %s", filter, policy.Filter)
+	}
+
+	proto := &monitoringpb.AlertPolicy{
+		DisplayName: policy.DisplayName,
+		Combiner:    monitoringpb.AlertPolicy_OR,
+		Conditions: []*monitoringpb.AlertPolicy_Condition{
+			{
+				DisplayName: policy.DisplayName,
+				Condition: &monitoringpb.AlertPolicy_Condition_ConditionThreshold{
+					ConditionThreshold: &monitoringpb.AlertPolicy_Condition_MetricThreshold{
+						Filter: filter,
+						Aggregations: []*monitoringpb.Aggregation{
+							{
+								AlignmentPeriod:  durationpb.New(alignmentPeriod),
+								PerSeriesAligner: monitoringpb.Aggregation_ALIGN_RATE,
+							},
+						},
+						Comparison:     comparisons[policy.Comparison],
+						ThresholdValue: policy.Threshold,
+						Duration:       durationpb.New(policy.Duration),
+					},
+				},
+			},
+		},
+		NotificationChannels: channels,
+		Enabled:              wrapperspb.Bool(true),
+	}
+
+	if policy.Documentation != "" {
+		proto.Documentation = &monitoringpb.AlertPolicy_Documentation{
+			Content:  policy.Documentation,
+			MimeType: documentationMimeType,
+		}
+	}
+
+	return proto, nil
+}
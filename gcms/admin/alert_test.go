@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProvisioner_alertPolicyToProto(t *testing.T) {
+
+	provisioner := NewProvisioner("quantify", nil, nil)
+
+	channelNames := map[string]string{
+		"team-email": "projects/quantify/notificationChannels/123",
+	}
+
+	tests := []struct {
+		name          string
+		input         *AlertPolicy
+		expected      *monitoringpb.AlertPolicy
+		expectedError error
+	}{
+		{
+			name: "error rate",
+			input: &AlertPolicy{
+				DisplayName:          "High error rate",
+				Metric:               "errors",
+				Filter:               `metric.label.status = "500"`,
+				Comparison:           ComparisonGreaterThan,
+				Threshold:            5,
+				Duration:             time.Minute * 5,
+				Documentation:        "Errors are high",
+				NotificationChannels: []string{"team-email"},
+			},
+			expected: &monitoringpb.AlertPolicy{
+				DisplayName: "High error rate",
+				Documentation: &monitoringpb.AlertPolicy_Documentation{
+					Content:  "Errors are high",
+					MimeType: "text/markdown",
+				},
+				Combiner: monitoringpb.AlertPolicy_OR,
+				Conditions: []*monitoringpb.AlertPolicy_Condition{
+					{
+						DisplayName: "High error rate",
+						Condition: &monitoringpb.AlertPolicy_Condition_ConditionThreshold{
+							ConditionThreshold: &monitoringpb.AlertPolicy_Condition_MetricThreshold{
+								Filter: `metric.type = "custom.googleapis.com/errors" AND metric.label.status = "500"`,
+								Aggregations: []*monitoringpb.Aggregation{
+									{
+										AlignmentPeriod:  durationpb.New(time.Minute),
+										PerSeriesAligner: monitoringpb.Aggregation_ALIGN_RATE,
+									},
+								},
+								Comparison:     monitoringpb.ComparisonType_COMPARISON_GT,
+								ThresholdValue: 5,
+								Duration:       durationpb.New(time.Minute * 5),
+							},
+						},
+					},
+				},
+				NotificationChannels: []string{"projects/quantify/notificationChannels/123"},
+				Enabled:              wrapperspb.Bool(true),
+			},
+		},
+		{
+			name: "unknown channel",
+			input: &AlertPolicy{
+				DisplayName:          "High error rate",
+				Metric:               "errors",
+				NotificationChannels: []string{"missing"},
+			},
+			expectedError: errors.New("unknown notification channel: missing"),
+		},
+	}
+
+	for _, test := range tests {
+
+		result, err := provisioner.alertPolicyToProto(test.input, channelNames)
+
+		assert.Equalf(t, test.expectedError, err, "%s failed", test.name)
+		assert.Equalf(t, test.expected, result, "%s failed", test.name)
+	}
+}
+
+func TestNotificationChannelToProto(t *testing.T) {
+
+	input := &NotificationChannel{
+		DisplayName: "team-email",
+		Type:        "email",
+		Labels: map[string]string{
+			"email_address": "team@example.com",
+		},
+	}
+
+	expected := &monitoringpb.NotificationChannel{
+		Type:        "email",
+		DisplayName: "team-email",
+		Labels: map[string]string{
+			"email_address": "team@example.com",
+		},
+	}
+
+	assert.Equal(t, expected, notificationChannelToProto(input))
+}
@@ -0,0 +1,233 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/rustedturnip/quantify/gcms"
+)
+
+const (
+	dashboardsEndpoint = "https://monitoring.googleapis.com/v1/%s/dashboards"
+
+	dashboardColumns = 12
+	chartWidth       = 6
+	chartHeight      = 4
+
+	// defaultScope is the scope used for metrics without a "/" in their name.
+	defaultScope = "metrics"
+)
+
+// Dashboard is a Cloud Monitoring dashboard, serialisable to the JSON format
+// accepted by the Dashboards API and the "Import dashboard" console option.
+//
+// see: https://cloud.google.com/monitoring/api/ref_v3/rest/v1/projects.dashboards
+type Dashboard struct {
+	DisplayName  string        `json:"displayName"`
+	MosaicLayout *MosaicLayout `json:"mosaicLayout"`
+}
+
+// MosaicLayout arranges the dashboard's tiles on a grid.
+type MosaicLayout struct {
+	Columns int     `json:"columns"`
+	Tiles   []*Tile `json:"tiles"`
+}
+
+// Tile positions a widget within a MosaicLayout.
+type Tile struct {
+	XPos   int     `json:"xPos"`
+	YPos   int     `json:"yPos"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Widget *Widget `json:"widget"`
+}
+
+// Widget is either a chart of a metric, or a collapsible group of charts.
+type Widget struct {
+	Title            string            `json:"title"`
+	XyChart          *XyChart          `json:"xyChart,omitempty"`
+	CollapsibleGroup *CollapsibleGroup `json:"collapsibleGroup,omitempty"`
+}
+
+// CollapsibleGroup groups the tiles it overlaps within a MosaicLayout.
+type CollapsibleGroup struct {
+	Collapsed bool `json:"collapsed"`
+}
+
+// XyChart charts one or more time series.
+type XyChart struct {
+	DataSets []*DataSet `json:"dataSets"`
+}
+
+// DataSet is a single query plotted within an XyChart.
+type DataSet struct {
+	TimeSeriesQuery *TimeSeriesQuery `json:"timeSeriesQuery"`
+	PlotType        string           `json:"plotType"`
+}
+
+// TimeSeriesQuery selects the time series plotted by a DataSet.
+type TimeSeriesQuery struct {
+	TimeSeriesFilter *TimeSeriesFilter `json:"timeSeriesFilter"`
+}
+
+// TimeSeriesFilter filters and aligns time series.
+type TimeSeriesFilter struct {
+	Filter      string       `json:"filter"`
+	Aggregation *Aggregation `json:"aggregation"`
+}
+
+// Aggregation describes how time series are aligned.
+type Aggregation struct {
+	AlignmentPeriod  string `json:"alignmentPeriod"`
+	PerSeriesAligner string `json:"perSeriesAligner"`
+}
+
+// NewDashboard returns a Dashboard with one chart per provided metric name (as
+// provided to quantify), charting each metric's rate.
+//
+// Charts are grouped by scope, the part of the metric name before its first "/"
+// (e.g. "payments/requests" has the scope "payments"), with metrics without a
+// scope grouped under "metrics".
+func NewDashboard(displayName string, metrics []string) *Dashboard {
+
+	// group metrics by scope
+	scopes := make(map[string][]string)
+	for _, metric := range metrics {
+		scope := metricScope(metric)
+		scopes[scope] = append(scopes[scope], metric)
+	}
+
+	scopeNames := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		scopeNames = append(scopeNames, scope)
+	}
+	sort.Strings(scopeNames)
+
+	tiles := make([]*Tile, 0)
+	y := 0
+
+	for _, scope := range scopeNames {
+
+		names := scopes[scope]
+		sort.Strings(names)
+
+		rows := (len(names)*chartWidth + dashboardColumns - 1) / dashboardColumns
+
+		// group tile overlaps all of the scope's charts
+		tiles = append(tiles, &Tile{
+			XPos:   0,
+			YPos:   y,
+			Width:  dashboardColumns,
+			Height: rows * chartHeight,
+			Widget: &Widget{
+				Title:            scope,
+				CollapsibleGroup: &CollapsibleGroup{},
+			},
+		})
+
+		for i, name := range names {
+
+			offset := i * chartWidth
+
+			tiles = append(tiles, &Tile{
+				XPos:   offset % dashboardColumns,
+				YPos:   y + (offset/dashboardColumns)*chartHeight,
+				Width:  chartWidth,
+				Height: chartHeight,
+				Widget: newRateChart(name),
+			})
+		}
+
+		y += rows * chartHeight
+	}
+
+	return &Dashboard{
+		DisplayName: displayName,
+		MosaicLayout: &MosaicLayout{
+			Columns: dashboardColumns,
+			Tiles:   tiles,
+		},
+	}
+}
+
+// JSON returns the Dashboard serialised in the format accepted by the Dashboards
+// API.
+func (d *Dashboard) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// CreateDashboard creates the provided Dashboard within the provided project using
+// the Dashboards API. client must be authorised to call the API, for example one
+// created with golang.org/x/oauth2/google.DefaultClient.
+func CreateDashboard(ctx context.Context, client *http.Client, projectId string, dashboard *Dashboard) error {
+
+	projectPath, err := gcms.ProjectPath(projectId)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(dashboard)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(dashboardsEndpoint, projectPath), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("failed to create dashboard: %s: %s", response.Status, message)
+	}
+
+	return nil
+}
+
+// newRateChart returns a Widget charting the rate of the named metric.
+func newRateChart(metric string) *Widget {
+	return &Widget{
+		Title: metric,
+		XyChart: &XyChart{
+			DataSets: []*DataSet{
+				{
+					TimeSeriesQuery: &TimeSeriesQuery{
+						TimeSeriesFilter: &TimeSeriesFilter{
+							Filter: fmt.Sprintf("metric.type = %q", gcms.MetricType(metric)),
+							Aggregation: &Aggregation{
+								AlignmentPeriod:  "60s",
+								PerSeriesAligner: "ALIGN_RATE",
+							},
+						},
+					},
+					PlotType: "LINE",
+				},
+			},
+		},
+	}
+}
+
+// metricScope returns the scope of the named metric, being the part of its name
+// before the first "/".
+func metricScope(metric string) string {
+
+	scope, _, found := strings.Cut(metric, "/")
+	if !found {
+		return defaultScope
+	}
+
+	return scope
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDashboard(t *testing.T) {
+
+	dashboard := NewDashboard("Service", []string{
+		"payments/requests",
+		"payments/errors",
+		"payments/refunds",
+		"uptime",
+	})
+
+	tiles := dashboard.MosaicLayout.Tiles
+
+	// default group (1 chart) followed by payments group (3 charts over 2 rows)
+	assert.Len(t, tiles, 6)
+
+	assert.Equal(t, "metrics", tiles[0].Widget.Title)
+	assert.NotNil(t, tiles[0].Widget.CollapsibleGroup)
+	assert.Equal(t, 4, tiles[0].Height)
+	assert.Equal(t, `metric.type = "custom.googleapis.com/uptime"`, tiles[1].Widget.XyChart.DataSets[0].TimeSeriesQuery.TimeSeriesFilter.Filter)
+
+	assert.Equal(t, "payments", tiles[2].Widget.Title)
+	assert.Equal(t, 4, tiles[2].YPos)
+	assert.Equal(t, 8, tiles[2].Height)
+
+	assert.Equal(t, "payments/errors", tiles[3].Widget.Title)
+	assert.Equal(t, [2]int{0, 4}, [2]int{tiles[3].XPos, tiles[3].YPos})
+	assert.Equal(t, [2]int{6, 4}, [2]int{tiles[4].XPos, tiles[4].YPos})
+	assert.Equal(t, [2]int{0, 8}, [2]int{tiles[5].XPos, tiles[5].YPos})
+
+	_, err := dashboard.JSON()
+	assert.NoError(t, err)
+}
+
+func TestCreateDashboard(t *testing.T) {
+
+	var received *Dashboard
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = &Dashboard{}
+		_ = json.Unmarshal(body, received)
+	}))
+	defer server.Close()
+
+	// redirect requests to the test server
+	client := server.Client()
+	client.Transport = rewriteTransport{target: server.URL, base: http.DefaultTransport}
+
+	dashboard := NewDashboard("Service", []string{"uptime"})
+
+	assert.NoError(t, CreateDashboard(context.Background(), client, "quantify", dashboard))
+	assert.Equal(t, dashboard, received)
+}
+
+// rewriteTransport sends all requests to target.
+type rewriteTransport struct {
+	target string
+	base   http.RoundTripper
+}
+
+func (rt rewriteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	target, _ := http.NewRequestWithContext(r.Context(), r.Method, rt.target+r.URL.Path, r.Body)
+	target.Header = r.Header
+	return rt.base.RoundTrip(target)
+}
@@ -0,0 +1,152 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/rustedturnip/quantify"
+	"github.com/rustedturnip/quantify/gcms"
+	"github.com/rustedturnip/quantify/structlog"
+)
+
+const (
+	logMetricsEndpoint = "https://logging.googleapis.com/v2/%s/metrics"
+
+	// logMetricBuckets is the number of exponential buckets of each log-based
+	// metric's distribution.
+	logMetricBuckets = 64
+)
+
+// LogMetric is a Cloud Logging log-based metric, serialisable to the JSON format
+// accepted by the Logging API.
+//
+// see: https://cloud.google.com/logging/docs/reference/v2/rest/v2/projects.metrics
+type LogMetric struct {
+	Name             string                  `json:"name"`
+	Description      string                  `json:"description,omitempty"`
+	Filter           string                  `json:"filter"`
+	MetricDescriptor *LogMetricDescriptor    `json:"metricDescriptor"`
+	ValueExtractor   string                  `json:"valueExtractor"`
+	LabelExtractors  map[string]string       `json:"labelExtractors,omitempty"`
+	BucketOptions    *LogMetricBucketOptions `json:"bucketOptions"`
+}
+
+// LogMetricDescriptor describes the metric created from matching log entries.
+type LogMetricDescriptor struct {
+	MetricKind string            `json:"metricKind"`
+	ValueType  string            `json:"valueType"`
+	Unit       string            `json:"unit,omitempty"`
+	Labels     []*LogMetricLabel `json:"labels,omitempty"`
+}
+
+// LogMetricLabel describes a label extracted from matching log entries.
+type LogMetricLabel struct {
+	Key       string `json:"key"`
+	ValueType string `json:"valueType"`
+}
+
+// LogMetricBucketOptions describes the buckets of the metric's distribution.
+type LogMetricBucketOptions struct {
+	ExponentialBuckets *ExponentialBuckets `json:"exponentialBuckets"`
+}
+
+// ExponentialBuckets describes buckets whose upper bounds grow exponentially.
+type ExponentialBuckets struct {
+	NumFiniteBuckets int     `json:"numFiniteBuckets"`
+	GrowthFactor     float64 `json:"growthFactor"`
+	Scale            float64 `json:"scale"`
+}
+
+// NewLogMetric returns a LogMetric derived from the log lines written for the
+// provided metric by a structlog Exporter (using the default message), as an
+// alternative to pushing the metric to Cloud Monitoring directly.
+//
+// As each log line holds the count of an interval, the log-based metric is a
+// distribution of the counts, extracted from each line, with each of the metric's
+// labels extracted as a label. The total count is the distribution's mean
+// multiplied by its count, or can be charted with the ALIGN_SUM aligner.
+func NewLogMetric(metric *quantify.Metric) *LogMetric {
+
+	keys := make([]string, 0, len(metric.Labels))
+	for key := range metric.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	labels := make([]*LogMetricLabel, 0, len(keys))
+	extractors := make(map[string]string, len(keys))
+
+	for _, key := range keys {
+		labels = append(labels, &LogMetricLabel{
+			Key:       key,
+			ValueType: "STRING",
+		})
+		extractors[key] = fmt.Sprintf("EXTRACT(jsonPayload.labels.%s)", key)
+	}
+
+	valueField := "count"
+	if metric.ValueType == quantify.ValueTypeDouble {
+		valueField = "value"
+	}
+
+	return &LogMetric{
+		Name:        metric.Name,
+		Description: metric.Description,
+		Filter:      fmt.Sprintf("jsonPayload.msg = %q AND jsonPayload.metric = %q", structlog.DefaultMessage, metric.Name),
+		MetricDescriptor: &LogMetricDescriptor{
+			MetricKind: "DELTA",
+			ValueType:  "DISTRIBUTION",
+			Unit:       metric.Unit,
+			Labels:     labels,
+		},
+		ValueExtractor:  fmt.Sprintf("EXTRACT(jsonPayload.%s)", valueField),
+		LabelExtractors: extractors,
+		BucketOptions: &LogMetricBucketOptions{
+			ExponentialBuckets: &ExponentialBuckets{
+				NumFiniteBuckets: logMetricBuckets,
+				GrowthFactor:     2,
+				Scale:            1,
+			},
+		},
+	}
+}
+
+// CreateLogMetric creates the provided LogMetric within the provided project using
+// the Logging API. client must be authorised to call the API, for example one
+// created with golang.org/x/oauth2/google.DefaultClient.
+func CreateLogMetric(ctx context.Context, client *http.Client, projectId string, metric *LogMetric) error {
+
+	projectPath, err := gcms.ProjectPath(projectId)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(metric)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(logMetricsEndpoint, projectPath), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("failed to create log metric: %s: %s", response.Status, message)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestNewLogMetric(t *testing.T) {
+
+	metric := &quantify.Metric{
+		Name:        "planes",
+		Labels:      map[string]string{"model": "737", "manufacturer": "boeing"},
+		Description: "Planes counted",
+	}
+
+	logMetric := NewLogMetric(metric)
+
+	assert.Equal(t, &LogMetric{
+		Name:        "planes",
+		Description: "Planes counted",
+		Filter:      `jsonPayload.msg = "metric point" AND jsonPayload.metric = "planes"`,
+		MetricDescriptor: &LogMetricDescriptor{
+			MetricKind: "DELTA",
+			ValueType:  "DISTRIBUTION",
+			Labels: []*LogMetricLabel{
+				{Key: "manufacturer", ValueType: "STRING"},
+				{Key: "model", ValueType: "STRING"},
+			},
+		},
+		ValueExtractor: "EXTRACT(jsonPayload.count)",
+		LabelExtractors: map[string]string{
+			"manufacturer": "EXTRACT(jsonPayload.labels.manufacturer)",
+			"model":        "EXTRACT(jsonPayload.labels.model)",
+		},
+		BucketOptions: &LogMetricBucketOptions{
+			ExponentialBuckets: &ExponentialBuckets{
+				NumFiniteBuckets: 64,
+				GrowthFactor:     2,
+				Scale:            1,
+			},
+		},
+	}, logMetric)
+}
+
+func TestCreateLogMetric(t *testing.T) {
+
+	var path string
+	var received *LogMetric
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		received = &LogMetric{}
+		_ = json.Unmarshal(body, received)
+	}))
+	defer server.Close()
+
+	// redirect requests to the test server
+	client := server.Client()
+	client.Transport = rewriteTransport{target: server.URL, base: http.DefaultTransport}
+
+	logMetric := NewLogMetric(&quantify.Metric{Name: "planes", Labels: map[string]string{"model": "737"}})
+
+	assert.NoError(t, CreateLogMetric(context.Background(), client, "quantify", logMetric))
+	assert.Equal(t, "/v2/projects/quantify/metrics", path)
+	assert.Equal(t, logMetric, received)
+}
@@ -0,0 +1,187 @@
+package gcms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"google.golang.org/genproto/googleapis/api"
+	"google.golang.org/genproto/googleapis/api/label"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+
+	"github.com/rustedturnip/quantify"
+)
+
+var (
+	ErrLabelNotInDescriptor = errors.New("label key not in metric descriptor")
+
+	// launchStages maps quantify.LaunchStage values to their Google Cloud equivalent.
+	launchStages = map[quantify.LaunchStage]api.LaunchStage{
+		quantify.LaunchStageUnspecified: api.LaunchStage_LAUNCH_STAGE_UNSPECIFIED,
+		quantify.LaunchStagePrelaunch:   api.LaunchStage_PRELAUNCH,
+		quantify.LaunchStageEarlyAccess: api.LaunchStage_EARLY_ACCESS,
+		quantify.LaunchStageAlpha:       api.LaunchStage_ALPHA,
+		quantify.LaunchStageBeta:        api.LaunchStage_BETA,
+		quantify.LaunchStageGA:          api.LaunchStage_GA,
+		quantify.LaunchStageDeprecated:  api.LaunchStage_DEPRECATED,
+	}
+)
+
+// createMetricDescriptors creates a metric descriptor for each metric within the
+// provided series that carries metadata (e.g. a display name), and that hasn't
+// already had a descriptor created by this Exporter. Each descriptor describes the
+// label keys of every series of its metric within the provided series.
+//
+// Metrics without metadata are left for Google Cloud Monitoring to create
+// automatically when their first point is written.
+//
+// The caller must hold clientMu for reading.
+func (e *Exporter) createMetricDescriptors(ctx context.Context, series []*quantify.Series) error {
+
+	var firstErr error
+
+	// gather the label keys of each metric, as its series may have differing keys
+	keys := make(map[string]map[string]bool)
+	for _, s := range series {
+
+		if keys[s.Metric.Name] == nil {
+			keys[s.Metric.Name] = make(map[string]bool)
+		}
+
+		for key := range s.Metric.Labels {
+			keys[s.Metric.Name][key] = true
+		}
+	}
+
+	for _, s := range series {
+
+		if !s.Metric.HasMetadata() {
+			continue
+		}
+
+		e.mu.Lock()
+		_, described := e.described[s.Metric.Name]
+		e.mu.Unlock()
+
+		if described {
+			continue
+		}
+
+		_, err := e.client.CreateMetricDescriptor(ctx, e.createCreateMetricDescriptorRequestProto(s.Metric, sortedKeys(keys[s.Metric.Name])))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		e.mu.Lock()
+		e.described[s.Metric.Name] = keys[s.Metric.Name]
+		e.mu.Unlock()
+	}
+
+	return firstErr
+}
+
+// conformLabels returns the provided series with the labels of each conformed to
+// the label keys of its metric descriptor, where one has been created by this
+// Exporter, as Google Cloud Monitoring rejects a whole request with INVALID_ARGUMENT
+// if any of its time series don't match their descriptor.
+//
+// Label keys of the descriptor that are missing from a series are added with empty
+// values. Series with label keys that aren't in the descriptor can't be written, so
+// are left out, with ErrLabelNotInDescriptor returned for the first of them.
+func (e *Exporter) conformLabels(series []*quantify.Series) ([]*quantify.Series, error) {
+
+	var firstErr error
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	conformed := make([]*quantify.Series, 0, len(series))
+
+	for _, s := range series {
+
+		keys, described := e.described[s.Metric.Name]
+		if !described {
+			conformed = append(conformed, s)
+			continue
+		}
+
+		var unknown string
+		for key := range s.Metric.Labels {
+			if !keys[key] {
+				unknown = key
+				break
+			}
+		}
+
+		if unknown != "" {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%w: metric %s has label %s", ErrLabelNotInDescriptor, s.Metric.Name, unknown)
+			}
+			continue
+		}
+
+		if len(s.Metric.Labels) == len(keys) {
+			conformed = append(conformed, s)
+			continue
+		}
+
+		// copy the metric, rather than modifying the caller's
+		metric := *s.Metric
+		metric.Labels = make(map[string]string, len(keys))
+		for key := range keys {
+			metric.Labels[key] = s.Metric.Labels[key]
+		}
+
+		conformed = append(conformed, &quantify.Series{
+			Metric: &metric,
+			Points: s.Points,
+		})
+	}
+
+	return conformed, firstErr
+}
+
+// createCreateMetricDescriptorRequestProto compiles a monitoringpb.CreateMetricDescriptorRequest
+// proto describing the provided metric, with the provided label keys, within the
+// Exporter's project scope.
+func (e *Exporter) createCreateMetricDescriptorRequestProto(metric *quantify.Metric, keys []string) *monitoringpb.CreateMetricDescriptorRequest {
+
+	labels := make([]*label.LabelDescriptor, 0, len(keys))
+	for _, key := range keys {
+		labels = append(labels, &label.LabelDescriptor{
+			Key:       key,
+			ValueType: label.LabelDescriptor_STRING,
+		})
+	}
+
+	return &monitoringpb.CreateMetricDescriptorRequest{
+		Name: e.ProjectPath(),
+		MetricDescriptor: &metricpb.MetricDescriptor{
+			Type:        MetricType(metric.Name),
+			Labels:      labels,
+			MetricKind:  metricKinds[metric.Kind],
+			ValueType:   valueTypes[metric.ValueType],
+			DisplayName: metric.DisplayName,
+			Description: metric.Description,
+			LaunchStage: launchStages[metric.LaunchStage],
+			Unit:        metric.Unit,
+		},
+	}
+}
+
+// sortedKeys returns the keys of the provided set in ascending order.
+func sortedKeys(set map[string]bool) []string {
+
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,261 @@
+package gcms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+)
+
+const (
+	// metadataAttempts is the number of times each value is requested from the
+	// metadata server before detection fails.
+	metadataAttempts = 3
+	metadataBackoff  = 100 * time.Millisecond
+
+	// environment variables identifying a pod, which are expected to be set with the
+	// Kubernetes downward API.
+	envPodNamespace  = "POD_NAMESPACE"
+	envPodName       = "POD_NAME"
+	envContainerName = "CONTAINER_NAME"
+
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// ResourceDetectionError is returned when one or more labels of a Resource can't be
+// detected, describing why each failed.
+type ResourceDetectionError struct {
+
+	// Resource is the name of the resource type being detected.
+	Resource string
+
+	// Labels maps the key of each label that couldn't be detected to the cause.
+	Labels map[string]error
+}
+
+func (e *ResourceDetectionError) Error() string {
+
+	keys := make([]string, 0, len(e.Labels))
+	for key := range e.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	problems := make([]string, 0, len(keys))
+	for _, key := range keys {
+		problems = append(problems, fmt.Sprintf("%s: %v", key, e.Labels[key]))
+	}
+
+	return fmt.Sprintf("failed to detect %s labels: %s", e.Resource, strings.Join(problems, "; "))
+}
+
+// detector detects the labels of a Resource from the metadata server and the
+// environment, recording the cause of any that fail.
+type detector struct {
+	ctx     context.Context
+	client  *metadata.Client
+	backoff time.Duration
+	getenv  func(string) string
+
+	failed map[string]error
+}
+
+// NewGceInstanceResourceFromMetadata returns a ResourceGceInstance describing the
+// current Compute Engine instance, with its labels detected from the metadata
+// server. Requests are retried, and if any label still can't be detected, a
+// *ResourceDetectionError describing each failure is returned.
+func NewGceInstanceResourceFromMetadata(ctx context.Context) (*ResourceGceInstance, error) {
+	return newDetector(ctx).gceInstance()
+}
+
+// NewGkeContainerResourceFromMetadata returns a ResourceGkeContainer describing the
+// current container, with its labels detected from the metadata server and the
+// environment. If any label can't be detected, a *ResourceDetectionError describing
+// each failure is returned.
+//
+// The namespace, pod and container are read from the POD_NAMESPACE, POD_NAME and
+// CONTAINER_NAME environment variables, which can be set with the Kubernetes
+// downward API. Where they aren't set, the namespace falls back to that of the
+// pod's service account, and the pod to the hostname.
+func NewGkeContainerResourceFromMetadata(ctx context.Context) (*ResourceGkeContainer, error) {
+	return newDetector(ctx).gkeContainer()
+}
+
+func newDetector(ctx context.Context) *detector {
+	return &detector{
+		ctx:     ctx,
+		client:  metadata.NewClient(&http.Client{Timeout: 5 * time.Second}),
+		backoff: metadataBackoff,
+		getenv:  os.Getenv,
+		failed:  make(map[string]error),
+	}
+}
+
+// gceInstance detects the labels of a ResourceGceInstance.
+func (d *detector) gceInstance() (*ResourceGceInstance, error) {
+
+	resource := &ResourceGceInstance{
+		ProjectId:  d.metadata("project_id", "project/project-id"),
+		InstanceId: d.metadata("instance_id", "instance/id"),
+		Zone:       zoneName(d.metadata("zone", "instance/zone")),
+	}
+
+	if err := d.err(resourceNameGceInstance); err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// gkeContainer detects the labels of a ResourceGkeContainer.
+func (d *detector) gkeContainer() (*ResourceGkeContainer, error) {
+
+	resource := &ResourceGkeContainer{
+		ProjectId:     d.metadata("project_id", "project/project-id"),
+		ClusterName:   d.metadata("cluster_name", "instance/attributes/cluster-name"),
+		InstanceId:    d.metadata("instance_id", "instance/id"),
+		Zone:          zoneName(d.metadata("zone", "instance/zone")),
+		NamespaceId:   d.namespace(),
+		PodId:         d.env("pod_id", envPodName, "HOSTNAME"),
+		ContainerName: d.env("container_name", envContainerName),
+	}
+
+	if err := d.err(resourceNameGkeContainer); err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// metadata returns the value of the label with the provided key from the metadata
+// server.
+func (d *detector) metadata(key string, suffix string) string {
+
+	value, err := getMetadata(d.ctx, d.client, suffix, d.backoff)
+	if err != nil {
+		d.failed[key] = err
+		return ""
+	}
+
+	return value
+}
+
+// env returns the value of the label with the provided key from the first of the
+// provided environment variables that is set.
+func (d *detector) env(key string, names ...string) string {
+
+	for _, name := range names {
+		if value := d.getenv(name); value != "" {
+			return value
+		}
+	}
+
+	d.failed[key] = fmt.Errorf("environment variable %s not set", names[0])
+	return ""
+}
+
+// namespace returns the namespace of the pod, from the environment or the pod's
+// service account.
+func (d *detector) namespace() string {
+
+	if value := d.getenv(envPodNamespace); value != "" {
+		return value
+	}
+
+	value, err := os.ReadFile(serviceAccountNamespaceFile)
+	if err == nil && len(value) > 0 {
+		return strings.TrimSpace(string(value))
+	}
+
+	d.failed["namespace_id"] = fmt.Errorf("environment variable %s not set", envPodNamespace)
+	return ""
+}
+
+// err returns a *ResourceDetectionError for any labels that failed detection.
+func (d *detector) err(resource string) error {
+
+	if len(d.failed) == 0 {
+		return nil
+	}
+
+	return &ResourceDetectionError{
+		Resource: resource,
+		Labels:   d.failed,
+	}
+}
+
+// zoneName returns the name of a zone from its full path (e.g.
+// "projects/123/zones/europe-west2-a").
+func zoneName(zone string) string {
+	return zone[strings.LastIndex(zone, "/")+1:]
+}
+
+// getMetadata returns the value at the provided path of the metadata server,
+// retrying failed requests. Values that aren't defined aren't retried.
+func getMetadata(ctx context.Context, client *metadata.Client, suffix string, backoff time.Duration) (string, error) {
+
+	var err error
+
+	for attempt := 1; attempt <= metadataAttempts; attempt++ {
+
+		var value string
+		value, err = client.Get(suffix)
+		if err == nil && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value), nil
+		}
+		if err == nil {
+			err = errors.New("empty value")
+		}
+
+		var notDefined metadata.NotDefinedError
+		if errors.As(err, &notDefined) || attempt == metadataAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(backoff * time.Duration(attempt)):
+		}
+	}
+
+	return "", fmt.Errorf("metadata %s: %w", suffix, err)
+}
+
+// LabelSource provides the value of a resource label, for example from the metadata
+// server or an environment variable (see OptionWithResourceLabelSources).
+type LabelSource func(ctx context.Context) (string, error)
+
+// LabelFromMetadata returns a LabelSource reading the value at the provided path of
+// the metadata server (e.g. "instance/hostname"), retrying failed requests.
+func LabelFromMetadata(suffix string) LabelSource {
+	return func(ctx context.Context) (string, error) {
+		return getMetadata(ctx, newDetector(ctx).client, suffix, metadataBackoff)
+	}
+}
+
+// LabelFromMetadataAttribute returns a LabelSource reading the custom instance
+// attribute with the provided name from the metadata server (e.g. "cluster-name").
+func LabelFromMetadataAttribute(attribute string) LabelSource {
+	return LabelFromMetadata("instance/attributes/" + attribute)
+}
+
+// LabelFromEnv returns a LabelSource reading the environment variable with the
+// provided name, which must be set.
+func LabelFromEnv(name string) LabelSource {
+	return func(ctx context.Context) (string, error) {
+
+		value := os.Getenv(name)
+		if value == "" {
+			return "", fmt.Errorf("environment variable %s not set", name)
+		}
+
+		return value, nil
+	}
+}
@@ -0,0 +1,147 @@
+package gcms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMetadataServer starts a fake metadata server serving the provided values,
+// failing the first request for each path listed in flaky.
+func newMetadataServer(t *testing.T, values map[string]string, flaky ...string) *httptest.Server {
+
+	failures := make(map[string]bool)
+	for _, path := range flaky {
+		failures[path] = true
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		path := strings.TrimPrefix(r.URL.Path, "/computeMetadata/v1/")
+
+		if failures[path] {
+			failures[path] = false
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		value, ok := values[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(value))
+	}))
+
+	t.Setenv("GCE_METADATA_HOST", server.Listener.Addr().String())
+
+	return server
+}
+
+func TestDetector_gceInstance(t *testing.T) {
+
+	server := newMetadataServer(t, map[string]string{
+		"project/project-id": "quantify",
+		"instance/id":        "4520031799277581759",
+		"instance/zone":      "projects/123/zones/europe-west2-a",
+	}, "instance/id")
+	defer server.Close()
+
+	d := newDetector(context.Background())
+	d.backoff = 0
+
+	resource, err := d.gceInstance()
+
+	assert.NoError(t, err)
+	assert.Equal(t, &ResourceGceInstance{
+		ProjectId:  "quantify",
+		InstanceId: "4520031799277581759",
+		Zone:       "europe-west2-a",
+	}, resource)
+}
+
+func TestDetector_gkeContainer(t *testing.T) {
+
+	server := newMetadataServer(t, map[string]string{
+		"project/project-id":               "quantify",
+		"instance/zone":                    "projects/123/zones/europe-west2-a",
+		"instance/attributes/cluster-name": "primary",
+	})
+	defer server.Close()
+
+	env := map[string]string{
+		envPodNamespace: "payments",
+		"HOSTNAME":      "checkout-6d4cf56db6-2k8xq",
+	}
+
+	d := newDetector(context.Background())
+	d.backoff = 0
+	d.getenv = func(name string) string {
+		return env[name]
+	}
+
+	resource, err := d.gkeContainer()
+
+	assert.Nil(t, resource)
+	assert.EqualError(t, err, "failed to detect gke_container labels: "+
+		"container_name: environment variable CONTAINER_NAME not set; "+
+		"instance_id: metadata instance/id: metadata: GCE metadata \"instance/id\" not defined")
+
+	detectionErr, ok := err.(*ResourceDetectionError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "gke_container", detectionErr.Resource)
+		assert.Len(t, detectionErr.Labels, 2)
+	}
+}
+
+func TestNew_resourceLabelSources(t *testing.T) {
+
+	server := newMetadataServer(t, map[string]string{
+		"instance/attributes/cluster-name": "primary",
+	})
+	defer server.Close()
+
+	t.Setenv("POD_NAMESPACE", "payments")
+
+	resource := &ResourceGkeContainer{
+		ProjectId:     "quantify",
+		InstanceId:    "4520031799277581759",
+		Zone:          "europe-west2-a",
+		PodId:         "checkout-6d4cf56db6-2k8xq",
+		ContainerName: "checkout",
+	}
+
+	exporter, err := New(
+		context.Background(),
+		OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+		OptionWithResourceType(resource),
+		OptionWithResourceLabelSources(map[string]LabelSource{
+			"cluster_name": LabelFromMetadataAttribute("cluster-name"),
+			"namespace_id": LabelFromEnv("POD_NAMESPACE"),
+		}),
+	)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "primary", exporter.resourceLabels["cluster_name"])
+		assert.Equal(t, "payments", exporter.resourceLabels["namespace_id"])
+	}
+
+	// failed sources are reported against their labels
+	_, err = New(
+		context.Background(),
+		OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+		OptionWithResourceType(resource),
+		OptionWithResourceLabelSources(map[string]LabelSource{
+			"cluster_name": LabelFromMetadataAttribute("cluster-name"),
+			"namespace_id": LabelFromEnv("UNSET_NAMESPACE"),
+		}),
+	)
+
+	assert.ErrorContains(t, err, "resource.labels.namespace_id: environment variable UNSET_NAMESPACE not set")
+}
@@ -0,0 +1,686 @@
+// Package gcms provides a quantify.Exporter that reports metrics to Google
+// Cloud Monitoring (previously known as Stackdriver).
+package gcms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3"
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"google.golang.org/api/option"
+	distributionpb "google.golang.org/genproto/googleapis/api/distribution"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rustedturnip/quantify"
+)
+
+const (
+	// see https://cloud.google.com/monitoring/api/metrics_gcp for more info on
+	// metric roots.
+	//
+	// as this client is designed for custom metrics, this root is non-configurable
+	// (see https://cloud.google.com/monitoring/custom-metrics#identifier).
+	customMetricRoot = "custom.googleapis.com"
+
+	resourceLabelKeyProjectId = "project_id"
+
+	projectPathPrefix = "projects"
+
+	// maxTimeSeriesPerRequest is the maximum number of TimeSeries that can be
+	// included in a single CreateTimeSeriesRequest.
+	//
+	// see: https://cloud.google.com/monitoring/quotas
+	maxTimeSeriesPerRequest = 200
+
+	// minWriteInterval is the minimum interval between points written to a single
+	// time series.
+	//
+	// see: https://cloud.google.com/monitoring/quotas
+	minWriteInterval = time.Second * 5
+)
+
+var (
+	// ErrDeltaKindUnsupported is returned when validating a metric of
+	// quantify.MetricKindDelta, as Cloud Monitoring rejects DELTA points written to
+	// custom metrics.
+	ErrDeltaKindUnsupported = errors.New("DELTA metrics aren't supported for custom metrics")
+
+	// ErrClosed is returned when exporting, or replacing the client, once the
+	// Exporter has been closed (see Close).
+	ErrClosed = errors.New("exporter is closed")
+
+	// metricKinds maps quantify.MetricKind values to their Google Cloud equivalent.
+	metricKinds = map[quantify.MetricKind]metricpb.MetricDescriptor_MetricKind{
+		quantify.MetricKindCumulative: metricpb.MetricDescriptor_CUMULATIVE,
+		quantify.MetricKindGauge:      metricpb.MetricDescriptor_GAUGE,
+		quantify.MetricKindDelta:      metricpb.MetricDescriptor_DELTA,
+	}
+
+	// valueTypes maps quantify.ValueType values to their Google Cloud equivalent.
+	valueTypes = map[quantify.ValueType]metricpb.MetricDescriptor_ValueType{
+		quantify.ValueTypeInt64:        metricpb.MetricDescriptor_INT64,
+		quantify.ValueTypeDouble:       metricpb.MetricDescriptor_DOUBLE,
+		quantify.ValueTypeDistribution: metricpb.MetricDescriptor_DISTRIBUTION,
+	}
+)
+
+// Exporter implements quantify.Exporter, reporting metrics to Google Cloud
+// Monitoring as custom metrics.
+type Exporter struct {
+	mu             *sync.Mutex
+	resource       Resource
+	resourceName   string
+	resourceLabels map[string]string
+	resourcePolicy ResourcePolicy
+	labelSources   map[string]LabelSource
+	client         *monitoring.MetricClient
+
+	// clientMu is held for reading whilst the client is in use, allowing it to be
+	// replaced between flushes (see ReplaceClient).
+	clientMu sync.RWMutex
+
+	// clientOptions are used to configure the default client when one isn't
+	// supplied with OptionWithCloudMetricsClient.
+	clientOptions []option.ClientOption
+
+	// described tracks the metric names that descriptors have been created for,
+	// along with the label keys of each descriptor.
+	described map[string]map[string]bool
+
+	// requestLogger, when set, logs the requests made to the API (see
+	// OptionWithRequestLogging).
+	requestLogger *requestLogger
+
+	// strict, when set, applies every rule of Cloud Monitoring to metrics and
+	// series before they're written (see OptionWithStrictValidation).
+	strict bool
+
+	// ownsClient is set when the client was created by the Exporter, rather than
+	// supplied to it, and so should be closed by it (see Close).
+	ownsClient bool
+
+	// closed is set once the client has been closed (see Close).
+	closed bool
+}
+
+// New returns an instantiated Exporter, or returns an error if instantiation
+// fails.
+//
+// options allow the user to provide custom configurations as a list of Options.
+// If any options are invalid, a quantify.ValidationErrors describing every
+// problem is returned.
+func New(ctx context.Context, options ...Option) (*Exporter, error) {
+
+	exporter := &Exporter{
+		mu:        &sync.Mutex{},
+		described: make(map[string]map[string]bool),
+	}
+
+	// apply every option, so that all problems are reported together
+	var errs quantify.ValidationErrors
+
+	for _, option := range options {
+		err := option(exporter)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// client options would be silently ignored by a supplied client
+	if exporter.client != nil && len(exporter.clientOptions) > 0 {
+		errs = append(errs, &quantify.FieldError{
+			Path: "client",
+			Err:  fmt.Errorf("%w: client options can't be combined with a supplied client", quantify.ErrConflictingOptions),
+		})
+	}
+
+	// redaction only applies to logged requests
+	if exporter.requestLogger != nil && exporter.requestLogger.logger == nil {
+		errs = append(errs, &quantify.FieldError{
+			Path: "request_logging.redaction",
+			Err:  fmt.Errorf("%w: label redaction requires request logging", quantify.ErrConflictingOptions),
+		})
+	}
+
+	// detect any labels of the supplied resource from their sources
+	errs = append(errs, exporter.applyLabelSources(ctx)...)
+
+	// apply the resource policy if the supplied resource is incomplete
+	if err := exporter.applyResourcePolicy(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	// if exporter.client isn't supplied with options
+	if exporter.client == nil {
+
+		client, err := monitoring.NewMetricClient(ctx, exporter.clientOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		exporter.client = client
+		exporter.ownsClient = true
+	}
+
+	// if exporter.resource isn't supplied with options
+	if exporter.resourceName == "" || exporter.resourceLabels == nil {
+
+		// set to be global resource
+		option := OptionWithResourceType(&ResourceGlobal{
+			ProjectId: DetectProjectId(),
+		})
+
+		// attempt to apply resource
+		err := option(exporter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return exporter, nil
+}
+
+// applyLabelSources sets the labels of the supplied resource from their sources
+// (see OptionWithResourceLabelSources), returning an error for each that fails.
+func (e *Exporter) applyLabelSources(ctx context.Context) []error {
+
+	if len(e.labelSources) == 0 {
+		return nil
+	}
+
+	if e.resource == nil {
+		return []error{&quantify.FieldError{
+			Path: "resource_label_sources",
+			Err:  errors.New("requires a resource (see OptionWithResourceType)"),
+		}}
+	}
+
+	keys := make([]string, 0, len(e.labelSources))
+	for key := range e.labelSources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+
+	for _, key := range keys {
+
+		value, err := e.labelSources[key](ctx)
+		if err != nil {
+			errs = append(errs, &quantify.FieldError{Path: "resource.labels." + key, Err: err})
+			continue
+		}
+
+		// the project ID is validated when the resource is supplied, so is again here
+		if key == resourceLabelKeyProjectId {
+			if _, err := ProjectPath(value); err != nil {
+				errs = append(errs, &quantify.FieldError{Path: "resource.labels." + key, Err: err})
+				continue
+			}
+		}
+
+		e.resourceLabels[key] = value
+	}
+
+	return errs
+}
+
+// applyResourcePolicy passes the supplied resource to the ResourcePolicy if any of
+// its labels are missing, replacing it with the resource returned.
+func (e *Exporter) applyResourcePolicy() error {
+
+	if e.resource == nil {
+		return nil
+	}
+
+	// labels may have been set from sources rather than the resource itself
+	missing := make([]string, 0)
+	for _, key := range missingFields(e.resource) {
+		if e.resourceLabels[key] == "" {
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	policy := e.resourcePolicy
+	if policy == nil {
+		policy = ResourcePolicyError
+	}
+
+	resource, err := policy(e.resource, missing)
+	if err != nil {
+		return err
+	}
+
+	e.resource = nil
+	e.resourceName = ""
+	e.resourceLabels = nil
+
+	return OptionWithResourceType(resource)(e)
+}
+
+// MinWriteInterval implements quantify.WriteIntervalLimiter, as Google Cloud
+// Monitoring rejects points written to a time series less than 5 seconds apart.
+func (e *Exporter) MinWriteInterval() time.Duration {
+	return minWriteInterval
+}
+
+// LengthLimits implements quantify.LengthLimiter, returning the maximum lengths of
+// metric names (excluding the custom metric prefix), label keys and label values.
+func (e *Exporter) LengthLimits() quantify.LengthLimits {
+	return quantify.LengthLimits{
+		Name:       maxLengthMetricType - len(customMetricRoot+"/"),
+		LabelKey:   maxLengthMetricLabelKey,
+		LabelValue: maxLengthMetricLabelValue,
+	}
+}
+
+// ValidateMetric implements quantify.MetricValidator.
+//
+// ValidateMetric will return an error if the provided name does not match
+// Google's Metric_Type specification, or if any of the provided label keys
+// do not match Google's requirements. Refer to this link for more information:
+// https://cloud.google.com/monitoring/api/v3/naming-conventions
+//
+// With OptionWithStrictValidation, the lengths, counts and encoding of the metric
+// type and labels are validated too.
+func (e *Exporter) ValidateMetric(metric *quantify.Metric) error {
+
+	if !isMetricTypeValid(metric.Name) {
+		return fmt.Errorf("invalid name parameter provided")
+	}
+
+	for key := range metric.Labels {
+		if !isMetricLabelKeyValid(key) {
+			return fmt.Errorf("invalid label key provided: %s", key)
+		}
+	}
+
+	// see: https://cloud.google.com/monitoring/api/v3/kinds-and-types
+	if metric.Kind == quantify.MetricKindDelta {
+		return &quantify.FieldError{Path: "kind", Err: ErrDeltaKindUnsupported}
+	}
+
+	if e.strict {
+		return validateStrict(metric)
+	}
+
+	return nil
+}
+
+// Export implements quantify.Exporter, submitting the provided series to Google
+// Cloud Monitoring.
+//
+// As each request may only contain one point per time series, series holding
+// multiple points are split across sequential requests. All requests are
+// attempted, with the first error encountered being returned.
+//
+// Metrics carrying metadata (see quantify.MetricOption) have their metric
+// descriptor created before their first points are written, with the labels of
+// their series then conformed to it (see ErrLabelNotInDescriptor).
+//
+// ErrClosed is returned if the Exporter has been closed.
+func (e *Exporter) Export(ctx context.Context, series []*quantify.Series) error {
+
+	e.clientMu.RLock()
+	defer e.clientMu.RUnlock()
+
+	if e.closed {
+		return ErrClosed
+	}
+
+	var firstErr error
+
+	if e.strict {
+		series, firstErr = validateSeries(series)
+	}
+
+	err := e.createMetricDescriptors(ctx, series)
+	if err != nil && firstErr == nil {
+		firstErr = err
+	}
+
+	series, err = e.conformLabels(series)
+	if err != nil && firstErr == nil {
+		firstErr = err
+	}
+
+	// send requests
+	for _, request := range e.createCreateTimeSeriesRequestProtos(series) {
+
+		start := time.Now()
+		err := e.client.CreateTimeSeries(ctx, request)
+
+		if e.requestLogger != nil {
+			e.requestLogger.logCreateTimeSeries(request, time.Since(start), err)
+		}
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// ReplaceClient swaps the client used to report metrics, for example when the
+// credentials of a manually configured client have expired. The client is replaced
+// once any in-progress export has completed, and the previous client is returned so
+// that it can be closed by the caller. The Exporter doesn't close the new client.
+//
+// ErrClosed is returned, and the client isn't replaced, if the Exporter has been
+// closed.
+func (e *Exporter) ReplaceClient(client *monitoring.MetricClient) (*monitoring.MetricClient, error) {
+
+	e.clientMu.Lock()
+	defer e.clientMu.Unlock()
+
+	if e.closed {
+		return nil, ErrClosed
+	}
+
+	previous := e.client
+	e.client = client
+	e.ownsClient = false
+
+	return previous, nil
+}
+
+// Close implements io.Closer, closing the client used to report metrics and
+// releasing its connections, if the client was created by the Exporter. Clients
+// supplied with OptionWithCloudMetricsClient or ReplaceClient are left open for
+// their owner to close. Close is safe to call multiple times, and the Exporter
+// can't be used once closed (see ErrClosed).
+func (e *Exporter) Close() error {
+
+	e.clientMu.Lock()
+	defer e.clientMu.Unlock()
+
+	if e.closed {
+		return nil
+	}
+
+	e.closed = true
+
+	if e.client == nil || !e.ownsClient {
+		return nil
+	}
+
+	return e.client.Close()
+}
+
+// createCreateTimeSeriesRequestProtos compiles the provided series into as few
+// monitoringpb.CreateTimeSeriesRequest protos as possible whilst only including a
+// single point per series in each request, and no more than
+// maxTimeSeriesPerRequest series per request. Series are ordered by metric type
+// and labels within the requests.
+func (e *Exporter) createCreateTimeSeriesRequestProtos(series []*quantify.Series) []*monitoringpb.CreateTimeSeriesRequest {
+
+	// sort a copy so that requests are identical across flushes of the same series
+	series = append([]*quantify.Series{}, series...)
+	quantify.SortSeries(series)
+
+	// each request must only have one point per series, this multidimensional array
+	// tracks a single point from each series as multiple points can be submitted as
+	// long as they are from different series.
+	timeSeries := make([][]*monitoringpb.TimeSeries, 0)
+
+	// groups tracks the CounterGroup of each time series, so that groups aren't split
+	// across requests
+	groups := make([][]string, 0)
+
+	for _, s := range series {
+
+		metric := metricToMetricProto(s.Metric)
+
+		for i, point := range s.Points {
+
+			// if timeSeries[i] is out of bounds
+			if len(timeSeries) <= i {
+				timeSeries = append(timeSeries, make([]*monitoringpb.TimeSeries, 0, len(series)))
+				groups = append(groups, make([]string, 0, len(series)))
+			}
+
+			// split points out so only one point per metric per request
+			timeSeries[i] = append(timeSeries[i], e.createTimeSeriesProto(s.Metric, metric, pointToMetricPointProto(s.Metric, point)))
+			groups[i] = append(groups[i], s.Metric.Group)
+		}
+	}
+
+	requests := make([]*monitoringpb.CreateTimeSeriesRequest, 0, len(timeSeries))
+	for i, ts := range timeSeries {
+
+		// split into chunks that don't exceed the request limit
+		for start := 0; start < len(ts); {
+
+			end := requestEnd(groups[i], start, maxTimeSeriesPerRequest)
+
+			requests = append(requests, e.createCreateTimeSeriesRequestProto(ts[start:end]))
+			start = end
+		}
+	}
+
+	return requests
+}
+
+// requestEnd returns the end (exclusive) of the request of at most max time series
+// beginning at start, given the CounterGroup of each time series. A request ends
+// early rather than splitting a group, unless the group alone exceeds max.
+func requestEnd(groups []string, start int, max int) int {
+
+	end := start + max
+	if end >= len(groups) {
+		return len(groups)
+	}
+
+	group := groups[end]
+	if group == "" || groups[end-1] != group {
+		return end
+	}
+
+	// move the end back to the start of the group
+	groupStart := end - 1
+	for groupStart > start && groups[groupStart-1] == group {
+		groupStart--
+	}
+
+	if groupStart == start {
+		return end
+	}
+
+	return groupStart
+}
+
+// MetricType returns the Google Cloud Monitoring metric type that a metric with the
+// provided name is reported as, e.g. "custom.googleapis.com/planes".
+func MetricType(name string) string {
+	return path.Join(customMetricRoot, name)
+}
+
+// metricToMetricProto converts a quantify.Metric into a custom metricpb.Metric.
+func metricToMetricProto(metric *quantify.Metric) *metricpb.Metric {
+	return &metricpb.Metric{
+		Type:   MetricType(metric.Name),
+		Labels: metric.Labels,
+	}
+}
+
+// pointToMetricPointProto converts a quantify.Point of the provided metric into a
+// monitoringpb.Point.
+//
+// note: for non-gauge metrics, the duration between the start and end times must
+// be greater than 2 milliseconds for a valid Point as pointToMetricPointProto will
+// take 1 millisecond from the end time.
+func pointToMetricPointProto(metric *quantify.Metric, point *quantify.Point) *monitoringpb.Point {
+
+	interval := &monitoringpb.TimeInterval{
+		StartTime: timestamppb.New(point.Start),
+
+		// minus millisecond because: "The new start time must be at least a
+		// millisecond after the end time of the previous interval."
+		EndTime: timestamppb.New(point.End.Add(time.Millisecond * -1)),
+	}
+
+	// gauges measure a single point in time, so have no start time
+	if metric.Kind == quantify.MetricKindGauge {
+		interval = &monitoringpb.TimeInterval{
+			EndTime: timestamppb.New(point.End),
+		}
+	}
+
+	return &monitoringpb.Point{
+		Interval: interval,
+		Value:    pointToTypedValueProto(metric, point),
+	}
+}
+
+// pointToTypedValueProto converts the value of a quantify.Point of the provided
+// metric into a monitoringpb.TypedValue.
+func pointToTypedValueProto(metric *quantify.Metric, point *quantify.Point) *monitoringpb.TypedValue {
+
+	if metric.ValueType == quantify.ValueTypeDouble {
+		return &monitoringpb.TypedValue{
+			Value: &monitoringpb.TypedValue_DoubleValue{
+				DoubleValue: point.Value,
+			},
+		}
+	}
+
+	if metric.ValueType == quantify.ValueTypeDistribution {
+		return &monitoringpb.TypedValue{
+			Value: &monitoringpb.TypedValue_DistributionValue{
+				DistributionValue: distributionToDistributionProto(point.Distribution),
+			},
+		}
+	}
+
+	return &monitoringpb.TypedValue{
+		Value: &monitoringpb.TypedValue_Int64Value{
+			Int64Value: point.Count,
+		},
+	}
+}
+
+// distributionToDistributionProto converts a quantify.DistributionValue into a
+// distributionpb.Distribution.
+func distributionToDistributionProto(value *quantify.DistributionValue) *distributionpb.Distribution {
+
+	if value == nil {
+		return &distributionpb.Distribution{}
+	}
+
+	return &distributionpb.Distribution{
+		Count:                 value.Count,
+		Mean:                  value.Mean,
+		SumOfSquaredDeviation: value.SumOfSquaredDeviation,
+		BucketOptions:         bucketsToBucketOptionsProto(value.Buckets),
+		BucketCounts:          value.BucketCounts,
+	}
+}
+
+// bucketsToBucketOptionsProto converts quantify.Buckets into a
+// distributionpb.Distribution_BucketOptions.
+func bucketsToBucketOptionsProto(buckets *quantify.Buckets) *distributionpb.Distribution_BucketOptions {
+
+	if buckets == nil {
+		return nil
+	}
+
+	switch buckets.Layout {
+	case quantify.BucketLayoutLinear:
+		return &distributionpb.Distribution_BucketOptions{
+			Options: &distributionpb.Distribution_BucketOptions_LinearBuckets{
+				LinearBuckets: &distributionpb.Distribution_BucketOptions_Linear{
+					NumFiniteBuckets: int32(buckets.Count),
+					Width:            buckets.Width,
+					Offset:           buckets.Offset,
+				},
+			},
+		}
+
+	case quantify.BucketLayoutExponential:
+		return &distributionpb.Distribution_BucketOptions{
+			Options: &distributionpb.Distribution_BucketOptions_ExponentialBuckets{
+				ExponentialBuckets: &distributionpb.Distribution_BucketOptions_Exponential{
+					NumFiniteBuckets: int32(buckets.Count),
+					GrowthFactor:     buckets.GrowthFactor,
+					Scale:            buckets.Scale,
+				},
+			},
+		}
+	}
+
+	return &distributionpb.Distribution_BucketOptions{
+		Options: &distributionpb.Distribution_BucketOptions_ExplicitBuckets{
+			ExplicitBuckets: &distributionpb.Distribution_BucketOptions_Explicit{
+				Bounds: buckets.Bounds,
+			},
+		},
+	}
+}
+
+// ProjectPath returns the resource name of the provided project (e.g.
+// "projects/my-project"), which may be identified by its project ID or project
+// number. ErrInvalidProjectId is returned if projectId is neither.
+func ProjectPath(projectId string) (string, error) {
+
+	if !isProjectIdValid(projectId) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidProjectId, projectId)
+	}
+
+	return path.Join(projectPathPrefix, projectId), nil
+}
+
+// ProjectPath implements quantify.ProjectScoped, returning the resource name of
+// the project that metrics are written to (e.g. "projects/my-project").
+func (e *Exporter) ProjectPath() string {
+	return path.Join(projectPathPrefix, e.resourceLabels[resourceLabelKeyProjectId])
+}
+
+// createTimeSeriesProto creates a monitoringpb.TimeSeries proto for the provided
+// point of the provided metric, converted to metricProto, that can be submitted to
+// Google Cloud Monitoring within a monitoringpb.CreateTimeSeriesRequest.
+//
+// The value type and unit of the metric are included so that descriptors created
+// automatically, when the first point is written, describe them too, allowing
+// Metrics Explorer to choose sensible defaults.
+func (e *Exporter) createTimeSeriesProto(metric *quantify.Metric, metricProto *metricpb.Metric, point *monitoringpb.Point) *monitoringpb.TimeSeries {
+
+	return &monitoringpb.TimeSeries{
+		Metric:     metricProto,
+		MetricKind: metricKinds[metric.Kind],
+		ValueType:  valueTypes[metric.ValueType],
+		Unit:       metric.Unit,
+		Resource: &monitoredres.MonitoredResource{
+			Type:   e.resourceName,
+			Labels: e.resourceLabels,
+		},
+		Points: []*monitoringpb.Point{
+			point,
+		},
+	}
+}
+
+// createCreateTimeSeriesRequestProto compiles a monitoringpb.CreateTimeSeriesRequest proto
+// within the Exporter's project scope with the provided []*monitoringpb.TimeSeries.
+func (e *Exporter) createCreateTimeSeriesRequestProto(series []*monitoringpb.TimeSeries) *monitoringpb.CreateTimeSeriesRequest {
+	return &monitoringpb.CreateTimeSeriesRequest{
+		Name:       e.ProjectPath(),
+		TimeSeries: series,
+	}
+}
@@ -0,0 +1,756 @@
+package gcms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/api"
+	distributionpb "google.golang.org/genproto/googleapis/api/distribution"
+	"google.golang.org/genproto/googleapis/api/label"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestExporter_pointToMetricPointProto(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    *quantify.Point
+		expected *monitoringpb.Point
+	}{
+		{
+			name: "normal count",
+			input: &quantify.Point{
+				Start: time.Unix(1672693348, 0), // 2023-01-02 21:02:28
+				End:   time.Unix(1672693408, 0), // 2023-01-02 21:03:28
+				Count: 365,
+			},
+			expected: &monitoringpb.Point{
+				Interval: &monitoringpb.TimeInterval{
+					StartTime: &timestamppb.Timestamp{
+						Seconds: 1672693348,
+						Nanos:   0,
+					},
+					EndTime: &timestamppb.Timestamp{
+						Seconds: 1672693407,
+						Nanos:   999000000,
+					},
+				},
+				Value: &monitoringpb.TypedValue{
+					Value: &monitoringpb.TypedValue_Int64Value{
+						Int64Value: 365,
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, pointToMetricPointProto(&quantify.Metric{}, test.input), "%s failed", test.name)
+	}
+}
+
+func TestExporter_createTimeSeriesProto(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		pointsInput *monitoringpb.Point
+		metric      *quantify.Metric
+		metricInput *metricpb.Metric
+		exporter    *Exporter
+		expected    *monitoringpb.TimeSeries
+	}{
+		{
+			name: "single point, normal",
+			pointsInput: &monitoringpb.Point{
+
+				Interval: &monitoringpb.TimeInterval{
+					StartTime: &timestamppb.Timestamp{
+						Seconds: 1672693348, // 2023-01-02 21:02:28
+						Nanos:   0,
+					},
+					EndTime: &timestamppb.Timestamp{
+						Seconds: 1672693407, // 2023-01-02 21:03:28
+						Nanos:   999000000,
+					},
+				},
+				Value: &monitoringpb.TypedValue{
+					Value: &monitoringpb.TypedValue_Int64Value{
+						Int64Value: 365,
+					},
+				},
+			},
+			metric: &quantify.Metric{
+				Name: "test-metric",
+				Labels: map[string]string{
+					"colour": "red",
+				},
+				Unit: "{planes}",
+			},
+			metricInput: &metricpb.Metric{
+				Type: "custom.googleapis.com/test-metric",
+				Labels: map[string]string{
+					"colour": "red",
+				},
+			},
+			exporter: &Exporter{
+				resourceName: "global",
+				resourceLabels: map[string]string{
+					"project_id": "quantify",
+				},
+			},
+			expected: &monitoringpb.TimeSeries{
+				Metric: &metricpb.Metric{
+					Type: "custom.googleapis.com/test-metric",
+					Labels: map[string]string{
+						"colour": "red",
+					},
+				},
+				MetricKind: metricpb.MetricDescriptor_CUMULATIVE,
+				ValueType:  metricpb.MetricDescriptor_INT64,
+				Unit:       "{planes}",
+				Resource: &monitoredres.MonitoredResource{
+					Type: "global",
+					Labels: map[string]string{
+						"project_id": "quantify",
+					},
+				},
+				Points: []*monitoringpb.Point{
+					{
+						Interval: &monitoringpb.TimeInterval{
+							StartTime: &timestamppb.Timestamp{
+								Seconds: 1672693348, // 2023-01-02 21:02:28
+								Nanos:   0,
+							},
+							EndTime: &timestamppb.Timestamp{
+								Seconds: 1672693407, // 2023-01-02 21:03:28
+								Nanos:   999000000,
+							},
+						},
+						Value: &monitoringpb.TypedValue{
+							Value: &monitoringpb.TypedValue_Int64Value{
+								Int64Value: 365,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "delta",
+			pointsInput: &monitoringpb.Point{
+				Value: &monitoringpb.TypedValue{
+					Value: &monitoringpb.TypedValue_Int64Value{
+						Int64Value: 2,
+					},
+				},
+			},
+			metric: &quantify.Metric{
+				Name: "test-metric",
+				Kind: quantify.MetricKindDelta,
+			},
+			metricInput: &metricpb.Metric{
+				Type: "custom.googleapis.com/test-metric",
+			},
+			exporter: &Exporter{
+				resourceName: "global",
+			},
+			expected: &monitoringpb.TimeSeries{
+				Metric: &metricpb.Metric{
+					Type: "custom.googleapis.com/test-metric",
+				},
+				MetricKind: metricpb.MetricDescriptor_DELTA,
+				ValueType:  metricpb.MetricDescriptor_INT64,
+				Resource: &monitoredres.MonitoredResource{
+					Type: "global",
+				},
+				Points: []*monitoringpb.Point{
+					{
+						Value: &monitoringpb.TypedValue{
+							Value: &monitoringpb.TypedValue_Int64Value{
+								Int64Value: 2,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		result := test.exporter.createTimeSeriesProto(test.metric, test.metricInput, test.pointsInput)
+		assert.Equalf(t, test.expected, result, "%s failed", test.name)
+	}
+}
+
+func TestExporter_ValidateMetric(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		input         *quantify.Metric
+		expectedError error
+	}{
+		{
+			name: "valid metric",
+			input: &quantify.Metric{
+				Name: "test_metric",
+				Labels: map[string]string{
+					"colour": "red",
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "invalid metric type (name)",
+			input: &quantify.Metric{
+				Name: "test_metric!!!",
+				Labels: map[string]string{
+					"colour": "red",
+				},
+			},
+			expectedError: errors.New("invalid name parameter provided"),
+		},
+		{
+			name: "invalid label key",
+			input: &quantify.Metric{
+				Name: "test_metric",
+				Labels: map[string]string{
+					"@!blah": "red",
+				},
+			},
+			expectedError: errors.New("invalid label key provided: @!blah"),
+		},
+		{
+			name: "delta kind",
+			input: &quantify.Metric{
+				Name: "test_metric",
+				Kind: quantify.MetricKindDelta,
+			},
+			expectedError: &quantify.FieldError{Path: "kind", Err: ErrDeltaKindUnsupported},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expectedError, (&Exporter{}).ValidateMetric(test.input), "%s failed", test.name)
+	}
+}
+
+func TestExporter_createCreateTimeSeriesRequestProtos(t *testing.T) {
+
+	exporter := &Exporter{
+		resourceName: "global",
+		resourceLabels: map[string]string{
+			"project_id": "quantify",
+		},
+	}
+
+	start := time.Unix(1672693340, 0)
+	point := func(offset int64, count int64) *quantify.Point {
+		return &quantify.Point{
+			Start: start.Add(time.Duration(offset) * time.Second),
+			End:   start.Add(time.Duration(offset+10) * time.Second),
+			Count: count,
+		}
+	}
+
+	series := []*quantify.Series{
+		{
+			Metric: &quantify.Metric{Name: "planes", Labels: map[string]string{"model": "737-800"}},
+			Points: []*quantify.Point{point(0, 5), point(10, 7), point(20, 9)},
+		},
+		{
+			Metric: &quantify.Metric{Name: "planes", Labels: map[string]string{"model": "737-900"}},
+			Points: []*quantify.Point{point(0, 3)},
+		},
+	}
+
+	requests := exporter.createCreateTimeSeriesRequestProtos(series)
+
+	// one request per point of the longest series, with no series repeated in a request
+	assert.Len(t, requests, 3)
+	assert.Len(t, requests[0].TimeSeries, 2)
+	assert.Len(t, requests[1].TimeSeries, 1)
+	assert.Len(t, requests[2].TimeSeries, 1)
+
+	for _, request := range requests {
+		assert.Equal(t, "projects/quantify", request.Name)
+	}
+
+	assert.Equal(t, "custom.googleapis.com/planes", requests[0].TimeSeries[0].Metric.Type)
+	assert.Equal(t, int64(9), requests[2].TimeSeries[0].Points[0].Value.GetInt64Value())
+}
+
+func TestExporter_createCreateMetricDescriptorRequestProto(t *testing.T) {
+
+	exporter := &Exporter{
+		resourceName: "global",
+		resourceLabels: map[string]string{
+			"project_id": "quantify",
+		},
+	}
+
+	metric := &quantify.Metric{
+		Name: "planes",
+		Labels: map[string]string{
+			"model":        "737-800",
+			"manufacturer": "boeing",
+		},
+		DisplayName: "Planes",
+		Description: "Planes counted",
+		LaunchStage: quantify.LaunchStageAlpha,
+		Unit:        "{planes}",
+	}
+
+	expected := &monitoringpb.CreateMetricDescriptorRequest{
+		Name: "projects/quantify",
+		MetricDescriptor: &metricpb.MetricDescriptor{
+			Type: "custom.googleapis.com/planes",
+			Labels: []*label.LabelDescriptor{
+				{
+					Key:       "manufacturer",
+					ValueType: label.LabelDescriptor_STRING,
+				},
+				{
+					Key:       "model",
+					ValueType: label.LabelDescriptor_STRING,
+				},
+			},
+			MetricKind:  metricpb.MetricDescriptor_CUMULATIVE,
+			ValueType:   metricpb.MetricDescriptor_INT64,
+			DisplayName: "Planes",
+			Description: "Planes counted",
+			LaunchStage: api.LaunchStage_ALPHA,
+			Unit:        "{planes}",
+		},
+	}
+
+	assert.Equal(t, expected, exporter.createCreateMetricDescriptorRequestProto(metric, []string{"manufacturer", "model"}))
+}
+
+func TestExporter_createCreateTimeSeriesRequestProtos_order(t *testing.T) {
+
+	exporter := &Exporter{
+		resourceName: "global",
+		resourceLabels: map[string]string{
+			"project_id": "quantify",
+		},
+	}
+
+	series := make([]*quantify.Series, 0)
+	for _, model := range []string{"a320", "737-900", "a380", "737-800"} {
+		series = append(series, &quantify.Series{
+			Metric: &quantify.Metric{Name: "planes", Labels: map[string]string{"model": model}},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: 1,
+				},
+			},
+		})
+	}
+
+	requests := exporter.createCreateTimeSeriesRequestProtos(series)
+
+	models := make([]string, 0)
+	for _, ts := range requests[0].TimeSeries {
+		models = append(models, ts.Metric.Labels["model"])
+	}
+
+	assert.Equal(t, []string{"737-800", "737-900", "a320", "a380"}, models)
+
+	// the provided series are left in their original order
+	assert.Equal(t, "a320", series[0].Metric.Labels["model"])
+}
+
+func TestExporter_createCreateTimeSeriesRequestProtos_limit(t *testing.T) {
+
+	exporter := &Exporter{
+		resourceName: "global",
+		resourceLabels: map[string]string{
+			"project_id": "quantify",
+		},
+	}
+
+	series := make([]*quantify.Series, 0)
+	for i := 0; i < 450; i++ {
+		series = append(series, &quantify.Series{
+			Metric: &quantify.Metric{Name: "planes", Labels: map[string]string{"id": fmt.Sprint(i)}},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: 1,
+				},
+			},
+		})
+	}
+
+	requests := exporter.createCreateTimeSeriesRequestProtos(series)
+
+	assert.Len(t, requests, 3)
+	assert.Len(t, requests[0].TimeSeries, 200)
+	assert.Len(t, requests[1].TimeSeries, 200)
+	assert.Len(t, requests[2].TimeSeries, 50)
+}
+
+func TestExporter_createCreateTimeSeriesRequestProtos_groups(t *testing.T) {
+
+	exporter := &Exporter{
+		resourceName: "global",
+		resourceLabels: map[string]string{
+			"project_id": "quantify",
+		},
+	}
+
+	// 190 ungrouped series followed by a group of 20, which would straddle the limit
+	series := make([]*quantify.Series, 0)
+	for i := 0; i < 210; i++ {
+
+		group := ""
+		if i >= 190 {
+			group = "cache"
+		}
+
+		series = append(series, &quantify.Series{
+			Metric: &quantify.Metric{Name: "planes", Labels: map[string]string{"id": fmt.Sprintf("%03d", i)}, Group: group},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: 1,
+				},
+			},
+		})
+	}
+
+	requests := exporter.createCreateTimeSeriesRequestProtos(series)
+
+	assert.Len(t, requests, 2)
+	assert.Len(t, requests[0].TimeSeries, 190)
+	assert.Len(t, requests[1].TimeSeries, 20)
+}
+
+func TestRequestEnd(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		groups   []string
+		start    int
+		max      int
+		expected int
+	}{
+		{
+			name:     "fits",
+			groups:   []string{"", "", ""},
+			max:      5,
+			expected: 3,
+		},
+		{
+			name:     "ungrouped",
+			groups:   []string{"", "", "", ""},
+			max:      2,
+			expected: 2,
+		},
+		{
+			name:     "group starts at the limit",
+			groups:   []string{"", "", "a", "a"},
+			max:      2,
+			expected: 2,
+		},
+		{
+			name:     "group straddles the limit",
+			groups:   []string{"", "a", "a", ""},
+			max:      2,
+			expected: 1,
+		},
+		{
+			name:     "group straddles the limit from start",
+			groups:   []string{"", "a", "a", "a", ""},
+			start:    1,
+			max:      2,
+			expected: 3,
+		},
+		{
+			name:     "adjacent groups",
+			groups:   []string{"a", "b", "b"},
+			max:      2,
+			expected: 1,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, requestEnd(test.groups, test.start, test.max), "%s failed", test.name)
+	}
+}
+
+func TestPointToMetricPointProto_gauge(t *testing.T) {
+
+	metric := &quantify.Metric{
+		Kind:      quantify.MetricKindGauge,
+		ValueType: quantify.ValueTypeDouble,
+	}
+
+	point := &quantify.Point{
+		Start: time.Unix(1672693348, 0),
+		End:   time.Unix(1672693348, 0),
+		Value: 1.5,
+	}
+
+	expected := &monitoringpb.Point{
+		Interval: &monitoringpb.TimeInterval{
+			EndTime: &timestamppb.Timestamp{
+				Seconds: 1672693348,
+			},
+		},
+		Value: &monitoringpb.TypedValue{
+			Value: &monitoringpb.TypedValue_DoubleValue{
+				DoubleValue: 1.5,
+			},
+		},
+	}
+
+	assert.Equal(t, expected, pointToMetricPointProto(metric, point))
+}
+
+func TestExporter_ReplaceClient(t *testing.T) {
+
+	original := &monitoring.MetricClient{}
+	replacement := &monitoring.MetricClient{}
+
+	exporter := &Exporter{
+		client:     original,
+		ownsClient: true,
+	}
+
+	previous, err := exporter.ReplaceClient(replacement)
+	assert.NoError(t, err)
+
+	assert.Same(t, original, previous)
+	assert.Same(t, replacement, exporter.client)
+
+	// the replacement belongs to the caller, so isn't closed by the Exporter
+	assert.False(t, exporter.ownsClient)
+	assert.NoError(t, exporter.Close())
+
+	// a closed Exporter's client can't be replaced
+	previous, err = exporter.ReplaceClient(original)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.Nil(t, previous)
+	assert.Same(t, replacement, exporter.client)
+}
+
+func TestExporter_Close_suppliedClient(t *testing.T) {
+
+	// a supplied client is never closed, so its zero value is safe here
+	exporter, err := New(
+		context.Background(),
+		OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+		OptionWithResourceType(&ResourceGlobal{ProjectId: "quantify"}),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.False(t, exporter.ownsClient)
+	assert.NoError(t, exporter.Close())
+
+	// a closed Exporter rejects exports, even though the client is left open
+	assert.ErrorIs(t, exporter.Export(context.Background(), nil), ErrClosed)
+}
+
+func TestPointToTypedValueProto_distribution(t *testing.T) {
+
+	metric := &quantify.Metric{
+		ValueType: quantify.ValueTypeDistribution,
+	}
+
+	tests := []struct {
+		name            string
+		buckets         *quantify.Buckets
+		expectedOptions *distributionpb.Distribution_BucketOptions
+	}{
+		{
+			name:    "linear",
+			buckets: quantify.LinearBuckets(10, 100, 0),
+			expectedOptions: &distributionpb.Distribution_BucketOptions{
+				Options: &distributionpb.Distribution_BucketOptions_LinearBuckets{
+					LinearBuckets: &distributionpb.Distribution_BucketOptions_Linear{
+						NumFiniteBuckets: 10,
+						Width:            100,
+					},
+				},
+			},
+		},
+		{
+			name:    "exponential",
+			buckets: quantify.ExponentialBuckets(10, 2, 1),
+			expectedOptions: &distributionpb.Distribution_BucketOptions{
+				Options: &distributionpb.Distribution_BucketOptions_ExponentialBuckets{
+					ExponentialBuckets: &distributionpb.Distribution_BucketOptions_Exponential{
+						NumFiniteBuckets: 10,
+						GrowthFactor:     2,
+						Scale:            1,
+					},
+				},
+			},
+		},
+		{
+			name:    "explicit",
+			buckets: quantify.ExplicitBuckets(10, 50, 100),
+			expectedOptions: &distributionpb.Distribution_BucketOptions{
+				Options: &distributionpb.Distribution_BucketOptions_ExplicitBuckets{
+					ExplicitBuckets: &distributionpb.Distribution_BucketOptions_Explicit{
+						Bounds: []float64{10, 50, 100},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+
+		point := &quantify.Point{
+			Distribution: &quantify.DistributionValue{
+				Count:                 2,
+				Mean:                  30,
+				SumOfSquaredDeviation: 800,
+				Min:                   10,
+				Max:                   50,
+				Buckets:               test.buckets,
+				BucketCounts:          []int64{0, 1, 1},
+			},
+		}
+
+		expected := &monitoringpb.TypedValue{
+			Value: &monitoringpb.TypedValue_DistributionValue{
+				DistributionValue: &distributionpb.Distribution{
+					Count:                 2,
+					Mean:                  30,
+					SumOfSquaredDeviation: 800,
+					BucketOptions:         test.expectedOptions,
+					BucketCounts:          []int64{0, 1, 1},
+				},
+			},
+		}
+
+		assert.Equalf(t, expected, pointToTypedValueProto(metric, point), "%s failed", test.name)
+	}
+}
+
+func TestExporter_conformLabels(t *testing.T) {
+
+	exporter := &Exporter{
+		mu: &sync.Mutex{},
+		described: map[string]map[string]bool{
+			"planes": {"manufacturer": true, "model": true},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		metric         *quantify.Metric
+		expectedLabels map[string]string
+		expectedErr    error
+	}{
+		{
+			name:           "matching",
+			metric:         &quantify.Metric{Name: "planes", Labels: map[string]string{"manufacturer": "boeing", "model": "737-800"}},
+			expectedLabels: map[string]string{"manufacturer": "boeing", "model": "737-800"},
+		},
+		{
+			name:           "missing label",
+			metric:         &quantify.Metric{Name: "planes", Labels: map[string]string{"manufacturer": "boeing"}},
+			expectedLabels: map[string]string{"manufacturer": "boeing", "model": ""},
+		},
+		{
+			name:        "unknown label",
+			metric:      &quantify.Metric{Name: "planes", Labels: map[string]string{"manufacturer": "boeing", "airline": "ba"}},
+			expectedErr: ErrLabelNotInDescriptor,
+		},
+		{
+			name:           "not described",
+			metric:         &quantify.Metric{Name: "boats", Labels: map[string]string{"manufacturer": "sunseeker"}},
+			expectedLabels: map[string]string{"manufacturer": "sunseeker"},
+		},
+	}
+
+	for _, test := range tests {
+
+		original := fmt.Sprint(test.metric.Labels)
+
+		series, err := exporter.conformLabels([]*quantify.Series{{Metric: test.metric}})
+		assert.ErrorIsf(t, err, test.expectedErr, "%s failed", test.name)
+
+		if test.expectedErr != nil {
+			assert.Emptyf(t, series, "%s failed", test.name)
+			continue
+		}
+
+		if assert.Lenf(t, series, 1, "%s failed", test.name) {
+			assert.Equalf(t, test.expectedLabels, series[0].Metric.Labels, "%s failed", test.name)
+		}
+
+		// the provided metric is left unmodified
+		assert.Equalf(t, original, fmt.Sprint(test.metric.Labels), "%s failed", test.name)
+	}
+}
+
+func TestProjectPath(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		projectId     string
+		expectedPath  string
+		expectedError error
+	}{
+		{
+			name:         "project id",
+			projectId:    "quantify-123",
+			expectedPath: "projects/quantify-123",
+		},
+		{
+			name:         "project number",
+			projectId:    "123456789012",
+			expectedPath: "projects/123456789012",
+		},
+		{
+			name:         "domain scoped project id",
+			projectId:    "example.com:quantify",
+			expectedPath: "projects/example.com:quantify",
+		},
+		{
+			name:          "empty",
+			projectId:     "",
+			expectedError: ErrInvalidProjectId,
+		},
+		{
+			name:          "uppercase",
+			projectId:     "Quantify",
+			expectedError: ErrInvalidProjectId,
+		},
+		{
+			name:          "path traversal",
+			projectId:     "quantify/../other",
+			expectedError: ErrInvalidProjectId,
+		},
+	}
+
+	for _, test := range tests {
+
+		path, err := ProjectPath(test.projectId)
+
+		assert.ErrorIsf(t, err, test.expectedError, "%s failed", test.name)
+		assert.Equalf(t, test.expectedPath, path, "%s failed", test.name)
+	}
+}
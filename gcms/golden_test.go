@@ -0,0 +1,40 @@
+package gcms
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/rustedturnip/quantify/internal/golden"
+)
+
+func TestExporter_golden(t *testing.T) {
+
+	exporter := &Exporter{
+		resourceName: "global",
+		resourceLabels: map[string]string{
+			"project_id": "quantify",
+		},
+	}
+
+	payload := &bytes.Buffer{}
+	payload.WriteString("[")
+
+	for i, request := range exporter.createCreateTimeSeriesRequestProtos(golden.Series()) {
+
+		encoded, err := protojson.Marshal(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if i > 0 {
+			payload.WriteString(",")
+		}
+		payload.Write(encoded)
+	}
+
+	payload.WriteString("]")
+
+	golden.Assert(t, "create_time_series", payload.Bytes())
+}
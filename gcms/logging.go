@@ -0,0 +1,95 @@
+package gcms
+
+import (
+	"log"
+	"time"
+
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// LogLevel defines how much detail is logged about each request made to the
+// Monitoring API (see OptionWithRequestLogging).
+type LogLevel int
+
+const (
+	// LogLevelSummary logs a single line per request, with the number of series it
+	// contained, how long it took and its outcome.
+	LogLevelSummary LogLevel = iota
+
+	// LogLevelFull logs the summary of each request along with the full request
+	// body as JSON.
+	LogLevelFull
+)
+
+// requestLogger logs requests made to the Monitoring API, for troubleshooting what
+// was actually sent.
+type requestLogger struct {
+	logger *log.Logger
+	level  LogLevel
+
+	// redact, when set, is called with the key and value of each metric and
+	// resource label, returning the value to log in its place.
+	redact func(key, value string) string
+}
+
+// logCreateTimeSeries logs the outcome of the provided CreateTimeSeries request.
+func (rl *requestLogger) logCreateTimeSeries(request *monitoringpb.CreateTimeSeriesRequest, duration time.Duration, err error) {
+
+	outcome := "OK"
+	if err != nil {
+		outcome = err.Error()
+	}
+
+	rl.logger.Printf("gcms: CreateTimeSeries %s: %d series in %s: %s", request.Name, len(request.TimeSeries), duration, outcome)
+
+	if rl.level < LogLevelFull {
+		return
+	}
+
+	body, err := protojson.Marshal(rl.redactRequest(request))
+	if err != nil {
+		rl.logger.Printf("gcms: failed to marshal request: %v", err)
+		return
+	}
+
+	rl.logger.Printf("gcms: CreateTimeSeries %s request: %s", request.Name, body)
+}
+
+// redactRequest returns a copy of the provided request with the values of its
+// labels redacted.
+func (rl *requestLogger) redactRequest(request *monitoringpb.CreateTimeSeriesRequest) *monitoringpb.CreateTimeSeriesRequest {
+
+	if rl.redact == nil {
+		return request
+	}
+
+	redacted := proto.Clone(request).(*monitoringpb.CreateTimeSeriesRequest)
+
+	for _, ts := range redacted.TimeSeries {
+		if ts.Metric != nil {
+			ts.Metric.Labels = rl.redactLabels(ts.Metric.Labels)
+		}
+		if ts.Resource != nil {
+			ts.Resource.Labels = rl.redactLabels(ts.Resource.Labels)
+		}
+	}
+
+	return redacted
+}
+
+// redactLabels returns a copy of the provided labels with their values redacted.
+func (rl *requestLogger) redactLabels(labels map[string]string) map[string]string {
+
+	if labels == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(labels))
+	for key, value := range labels {
+		redacted[key] = rl.redact(key, value)
+	}
+
+	return redacted
+}
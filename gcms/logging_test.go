@@ -0,0 +1,106 @@
+package gcms
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/stretchr/testify/assert"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+)
+
+func TestRequestLogger_logCreateTimeSeries(t *testing.T) {
+
+	request := &monitoringpb.CreateTimeSeriesRequest{
+		Name: "projects/quantify",
+		TimeSeries: []*monitoringpb.TimeSeries{
+			{
+				Metric: &metricpb.Metric{
+					Type:   "custom.googleapis.com/logins",
+					Labels: map[string]string{"user_id": "u-123"},
+				},
+				Resource: &monitoredres.MonitoredResource{
+					Type:   "global",
+					Labels: map[string]string{"project_id": "quantify"},
+				},
+			},
+		},
+	}
+
+	redact := func(key, value string) string {
+		if key == "user_id" {
+			return "REDACTED"
+		}
+		return value
+	}
+
+	tests := []struct {
+		name            string
+		level           LogLevel
+		redact          func(key, value string) string
+		err             error
+		expectedLines   int
+		expectedContain []string
+		expectedOmit    []string
+	}{
+		{
+			name:            "summary",
+			level:           LogLevelSummary,
+			expectedLines:   1,
+			expectedContain: []string{"CreateTimeSeries projects/quantify: 1 series in 2s: OK"},
+			expectedOmit:    []string{"u-123"},
+		},
+		{
+			name:            "summary with error",
+			level:           LogLevelSummary,
+			err:             errors.New("points out of order"),
+			expectedLines:   1,
+			expectedContain: []string{"1 series in 2s: points out of order"},
+		},
+		{
+			name:            "full",
+			level:           LogLevelFull,
+			expectedLines:   2,
+			expectedContain: []string{"custom.googleapis.com/logins", "u-123"},
+		},
+		{
+			name:            "full redacted",
+			level:           LogLevelFull,
+			redact:          redact,
+			expectedLines:   2,
+			expectedContain: []string{"REDACTED", "quantify"},
+			expectedOmit:    []string{"u-123"},
+		},
+	}
+
+	for _, test := range tests {
+
+		buffer := &bytes.Buffer{}
+
+		rl := &requestLogger{
+			logger: log.New(buffer, "", 0),
+			level:  test.level,
+			redact: test.redact,
+		}
+
+		rl.logCreateTimeSeries(request, time.Second*2, test.err)
+
+		output := buffer.String()
+		assert.Equalf(t, test.expectedLines, strings.Count(output, "\n"), "%s failed", test.name)
+
+		for _, s := range test.expectedContain {
+			assert.Containsf(t, output, s, "%s failed", test.name)
+		}
+		for _, s := range test.expectedOmit {
+			assert.NotContainsf(t, output, s, "%s failed", test.name)
+		}
+	}
+
+	// the request itself is never redacted
+	assert.Equal(t, "u-123", request.TimeSeries[0].Metric.Labels["user_id"])
+}
@@ -1,4 +1,4 @@
-package quantify
+package gcms
 
 import "regexp"
 
@@ -24,6 +24,12 @@ var (
 	//
 	// see: https://cloud.google.com/monitoring/api/v3/naming-conventions
 	reMetricLabelKey = regexp.MustCompile("^[a-z][a-z0-9\\_]*$")
+
+	// reProjectId provides the pattern for Google Cloud project IDs, optionally
+	// scoped to a domain (e.g. "example.com:my-project"), or project numbers.
+	//
+	// see: https://cloud.google.com/resource-manager/docs/creating-managing-projects
+	reProjectId = regexp.MustCompile("^(([a-z0-9][a-z0-9\\.\\-]*[a-z0-9]:)?[a-z][a-z0-9\\-]{4,28}[a-z0-9]|[0-9]+)$")
 )
 
 // validateMetricType asserts whether the provided string is a valid Google Cloud
@@ -57,3 +63,9 @@ func isMetricLabelKeyValid(metricLabelKey string) bool {
 
 	return true
 }
+
+// isProjectIdValid asserts whether the provided string is a valid Google Cloud
+// project ID or project number.
+func isProjectIdValid(projectId string) bool {
+	return reProjectId.MatchString(projectId)
+}
@@ -0,0 +1,288 @@
+package gcms
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The functions below are reference implementations of the naming rules of Google
+// Cloud Monitoring, written from the documented grammar rather than the patterns
+// used by the exporter, so that the two can be checked against each other.
+//
+// see: https://cloud.google.com/monitoring/api/v3/naming-conventions
+
+const (
+	alphanumerics = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	lowers        = "abcdefghijklmnopqrstuvwxyz"
+	digits        = "0123456789"
+)
+
+// referenceMetricType returns whether the provided metric type is accepted by the
+// API: up to 200 characters of letters, digits, underscores and periods, split
+// into segments by slashes, with the first character and that of each segment
+// after the first being a letter or digit.
+func referenceMetricType(metricType string) bool {
+
+	if metricType == "" || len(metricType) > maxLengthMetricType {
+		return false
+	}
+
+	for _, segment := range strings.Split(metricType, "/") {
+
+		if segment == "" || !strings.ContainsRune(alphanumerics, rune(segment[0])) {
+			return false
+		}
+
+		for _, r := range segment[1:] {
+			if !strings.ContainsRune(alphanumerics+"_.", r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// referenceLabelKey returns whether the provided label key is accepted by the API:
+// up to 100 characters of lower-case letters, digits and underscores, starting
+// with a lower-case letter.
+func referenceLabelKey(key string) bool {
+
+	if key == "" || len(key) > maxLengthMetricLabelKey {
+		return false
+	}
+
+	if !strings.ContainsRune(lowers, rune(key[0])) {
+		return false
+	}
+
+	for _, r := range key[1:] {
+		if !strings.ContainsRune(lowers+digits+"_", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// referenceProjectId returns whether the provided project ID is accepted by the
+// API: a project number, or 6 to 30 lower-case letters, digits and hyphens,
+// starting with a letter and not ending with a hyphen, optionally scoped to a
+// domain (e.g. "example.com:my-project").
+func referenceProjectId(projectId string) bool {
+
+	if projectId != "" && strings.Trim(projectId, digits) == "" {
+		return true
+	}
+
+	if i := strings.LastIndex(projectId, ":"); i >= 0 {
+
+		domain := projectId[:i]
+		if len(domain) < 2 || strings.HasPrefix(domain, "-") || strings.HasPrefix(domain, ".") ||
+			strings.HasSuffix(domain, "-") || strings.HasSuffix(domain, ".") {
+			return false
+		}
+
+		for _, r := range domain {
+			if !strings.ContainsRune(lowers+digits+"-.", r) {
+				return false
+			}
+		}
+
+		projectId = projectId[i+1:]
+	}
+
+	if len(projectId) < 6 || len(projectId) > 30 {
+		return false
+	}
+
+	if !strings.ContainsRune(lowers, rune(projectId[0])) || strings.HasSuffix(projectId, "-") {
+		return false
+	}
+
+	for _, r := range projectId {
+		if !strings.ContainsRune(lowers+digits+"-", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestIsMetricTypeValid(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "simple", input: "planes", expected: true},
+		{name: "segments", input: "fleet/planes.boeing_737", expected: true},
+		{name: "leading digit", input: "737/planes", expected: true},
+		{name: "empty", input: "", expected: false},
+		{name: "leading underscore", input: "_planes", expected: false},
+		{name: "leading slash", input: "/planes", expected: false},
+		{name: "trailing slash", input: "planes/", expected: false},
+		{name: "double slash", input: "fleet//planes", expected: false},
+		{name: "segment leading period", input: "fleet/.planes", expected: false},
+		{name: "invalid character", input: "planes!", expected: false},
+		{name: "maximum length", input: strings.Repeat("a", maxLengthMetricType), expected: true},
+		{name: "too long", input: strings.Repeat("a", maxLengthMetricType+1), expected: false},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, isMetricTypeValid(test.input), "%s failed", test.name)
+		assert.Equalf(t, test.expected, referenceMetricType(test.input), "%s failed (reference)", test.name)
+	}
+}
+
+func TestIsMetricLabelKeyValid(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "simple", input: "colour", expected: true},
+		{name: "digits and underscores", input: "model_737", expected: true},
+		{name: "empty", input: "", expected: false},
+		{name: "leading digit", input: "737", expected: false},
+		{name: "leading underscore", input: "_colour", expected: false},
+		{name: "upper case", input: "Colour", expected: false},
+		{name: "invalid character", input: "colour-code", expected: false},
+		{name: "maximum length", input: strings.Repeat("a", maxLengthMetricLabelKey), expected: true},
+		{name: "too long", input: strings.Repeat("a", maxLengthMetricLabelKey+1), expected: false},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, isMetricLabelKeyValid(test.input), "%s failed", test.name)
+		assert.Equalf(t, test.expected, referenceLabelKey(test.input), "%s failed (reference)", test.name)
+	}
+}
+
+func TestIsProjectIdValid(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "project id", input: "quantify", expected: true},
+		{name: "hyphens", input: "quantify-123", expected: true},
+		{name: "project number", input: "123456789012", expected: true},
+		{name: "domain scoped", input: "example.com:quantify", expected: true},
+		{name: "empty", input: "", expected: false},
+		{name: "too short", input: "quant", expected: false},
+		{name: "too long", input: strings.Repeat("q", 31), expected: false},
+		{name: "leading digit", input: "1quantify", expected: false},
+		{name: "trailing hyphen", input: "quantify-", expected: false},
+		{name: "upper case", input: "Quantify", expected: false},
+		{name: "empty domain", input: ":quantify", expected: false},
+		{name: "domain trailing period", input: "example.:quantify", expected: false},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, isProjectIdValid(test.input), "%s failed", test.name)
+		assert.Equalf(t, test.expected, referenceProjectId(test.input), "%s failed (reference)", test.name)
+	}
+}
+
+// TestIsMetricTypeValid_generated checks that metric types generated from the
+// documented grammar are accepted.
+func TestIsMetricTypeValid_generated(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 1000; i++ {
+
+		segments := make([]string, 1+r.Intn(4))
+		for j := range segments {
+			segments[j] = generate(r, alphanumerics, 1) + generate(r, alphanumerics+"_.", r.Intn(10))
+		}
+
+		metricType := strings.Join(segments, "/")
+		assert.Truef(t, isMetricTypeValid(metricType), "%q failed", metricType)
+	}
+}
+
+// TestIsMetricLabelKeyValid_generated checks that label keys generated from the
+// documented grammar are accepted.
+func TestIsMetricLabelKeyValid_generated(t *testing.T) {
+
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 1000; i++ {
+
+		key := generate(r, lowers, 1) + generate(r, lowers+digits+"_", r.Intn(maxLengthMetricLabelKey))
+		assert.Truef(t, isMetricLabelKeyValid(key), "%q failed", key)
+	}
+}
+
+func FuzzIsMetricTypeValid(f *testing.F) {
+
+	for _, seed := range []string{"planes", "fleet/planes.boeing_737", "planes/", "fleet//planes", "_planes", "plänes"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, metricType string) {
+
+		accepted := isMetricTypeValid(metricType)
+
+		// anything accepted must be accepted by the API, and anything the API accepts
+		// shouldn't be rejected
+		if accepted != referenceMetricType(metricType) {
+			t.Errorf("isMetricTypeValid(%q) = %t, the API's rules give %t", metricType, accepted, !accepted)
+		}
+
+		if accepted && !utf8.ValidString(metricType) {
+			t.Errorf("isMetricTypeValid(%q) accepted invalid UTF-8", metricType)
+		}
+	})
+}
+
+func FuzzIsMetricLabelKeyValid(f *testing.F) {
+
+	for _, seed := range []string{"colour", "model_737", "737", "Colour", "colour-code", "cölour"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, key string) {
+
+		accepted := isMetricLabelKeyValid(key)
+
+		if accepted != referenceLabelKey(key) {
+			t.Errorf("isMetricLabelKeyValid(%q) = %t, the API's rules give %t", key, accepted, !accepted)
+		}
+	})
+}
+
+func FuzzIsProjectIdValid(f *testing.F) {
+
+	for _, seed := range []string{"quantify", "123456789012", "example.com:quantify", ":quantify", "quantify-", "a:b:quantify"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, projectId string) {
+
+		accepted := isProjectIdValid(projectId)
+
+		if accepted != referenceProjectId(projectId) {
+			t.Errorf("isProjectIdValid(%q) = %t, the API's rules give %t", projectId, accepted, !accepted)
+		}
+	})
+}
+
+// generate returns a string of n characters chosen from the provided characters.
+func generate(r *rand.Rand, characters string, n int) string {
+
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = characters[r.Intn(len(characters))]
+	}
+
+	return string(b)
+}
@@ -0,0 +1,258 @@
+package gcms
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Option defines a function for supplying the Exporter constructor with certain
+// configurations.
+type Option func(*Exporter) error
+
+// OptionWithCloudMetricsClient allows a cloud_metrics Client, which has been
+// manually configured, to be supplied to the exporter instead of using the default
+// configuration.
+func OptionWithCloudMetricsClient(client *monitoring.MetricClient) Option {
+	return func(exporter *Exporter) error {
+
+		if exporter.client != nil {
+			return &quantify.FieldError{Path: "client", Err: quantify.ErrDuplicateOption}
+		}
+
+		exporter.client = client
+		return nil
+	}
+}
+
+// OptionWithResourceType allows a Resource other than the default to be provided
+// which will govern how the metric is filed in Google Cloud Monitoring.
+func OptionWithResourceType(resource Resource) Option {
+	return func(exporter *Exporter) error {
+
+		if exporter.resourceName != "" {
+			return &quantify.FieldError{Path: "resource", Err: quantify.ErrDuplicateOption}
+		}
+
+		resourceLabels, err := flatten(resource)
+		if err != nil {
+			return err
+		}
+
+		value, ok := resourceLabels[resourceLabelKeyProjectId]
+		if !ok || value == "" {
+			return &quantify.FieldError{
+				Path: fmt.Sprintf("resource.labels.%s", resourceLabelKeyProjectId),
+				Err:  errors.New("required"),
+			}
+		}
+
+		if _, err := ProjectPath(value); err != nil {
+			return &quantify.FieldError{
+				Path: fmt.Sprintf("resource.labels.%s", resourceLabelKeyProjectId),
+				Err:  err,
+			}
+		}
+
+		exporter.resource = resource
+		exporter.resourceLabels = resourceLabels
+		exporter.resourceName = resource.GetName()
+
+		return nil
+	}
+}
+
+// OptionWithResourcePolicy sets the ResourcePolicy that decides how New proceeds
+// when the labels of the Resource are incomplete (see ResourcePolicyError,
+// ResourcePolicyFallbackGlobal and ResourcePolicyWarn). By default, New fails.
+func OptionWithResourcePolicy(policy ResourcePolicy) Option {
+	return func(exporter *Exporter) error {
+
+		if exporter.resourcePolicy != nil {
+			return &quantify.FieldError{Path: "resource_policy", Err: quantify.ErrDuplicateOption}
+		}
+
+		if policy == nil {
+			return &quantify.FieldError{Path: "resource_policy", Err: errors.New("required")}
+		}
+
+		exporter.resourcePolicy = policy
+		return nil
+	}
+}
+
+// OptionWithResourceLabelSources sets labels of the Resource (see
+// OptionWithResourceType) from the provided sources when New is called, mapping
+// values such as metadata server attributes or environment variables into labels
+// declaratively. Detected values replace any provided by the Resource itself.
+//
+// For example, to detect the cluster and namespace of a ResourceGkeContainer:
+//
+//	gcms.OptionWithResourceLabelSources(map[string]gcms.LabelSource{
+//		"cluster_name": gcms.LabelFromMetadataAttribute("cluster-name"),
+//		"namespace_id": gcms.LabelFromEnv("POD_NAMESPACE"),
+//	})
+func OptionWithResourceLabelSources(sources map[string]LabelSource) Option {
+	return func(exporter *Exporter) error {
+
+		if exporter.labelSources != nil {
+			return &quantify.FieldError{Path: "resource_label_sources", Err: quantify.ErrDuplicateOption}
+		}
+
+		exporter.labelSources = sources
+		return nil
+	}
+}
+
+// OptionWithUserAgent sets the user agent sent with each request to the Monitoring
+// API (e.g. "quantify/1.2 service/foo"), allowing API traffic to be attributed to
+// an application in audit logs.
+//
+// Note: this only applies to the client created by the Exporter, and so can't be
+// combined with OptionWithCloudMetricsClient.
+func OptionWithUserAgent(userAgent string) Option {
+	return func(exporter *Exporter) error {
+		exporter.clientOptions = append(exporter.clientOptions, option.WithUserAgent(userAgent))
+		return nil
+	}
+}
+
+// OptionWithEndpoint sets the address of the Monitoring API, for example to use a
+// regional endpoint, private connectivity (private.googleapis.com:443), or a test
+// double.
+//
+// Note: this only applies to the client created by the Exporter, and so can't be
+// combined with OptionWithCloudMetricsClient.
+func OptionWithEndpoint(endpoint string) Option {
+	return func(exporter *Exporter) error {
+		exporter.clientOptions = append(exporter.clientOptions, option.WithEndpoint(endpoint))
+		return nil
+	}
+}
+
+// OptionWithCredentialsFile sets the service account or workload identity
+// federation credentials file used to authenticate with the Monitoring API, rather
+// than the application default credentials.
+//
+// Note: this only applies to the client created by the Exporter, and so can't be
+// combined with OptionWithCloudMetricsClient.
+func OptionWithCredentialsFile(filename string) Option {
+	return func(exporter *Exporter) error {
+		exporter.clientOptions = append(exporter.clientOptions, option.WithCredentialsFile(filename))
+		return nil
+	}
+}
+
+// OptionWithTLSConfig sets the TLS configuration used to connect to the Monitoring
+// API, for example to trust a private certificate authority.
+//
+// Note: this only applies to the client created by the Exporter, and so can't be
+// combined with OptionWithCloudMetricsClient.
+func OptionWithTLSConfig(config *tls.Config) Option {
+	return func(exporter *Exporter) error {
+		if config == nil {
+			return &quantify.FieldError{Path: "tls_config", Err: errors.New("required")}
+		}
+		exporter.clientOptions = append(exporter.clientOptions, option.WithGRPCDialOption(
+			grpc.WithTransportCredentials(credentials.NewTLS(config)),
+		))
+		return nil
+	}
+}
+
+// OptionWithUnaryInterceptors applies the provided gRPC interceptors (e.g. for
+// tracing, metrics or custom auth) to every call made by the client created by the
+// Exporter. Interceptors are chained in the order provided.
+//
+// Note: this only applies to the client created by the Exporter, and so can't be
+// combined with OptionWithCloudMetricsClient.
+func OptionWithUnaryInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(exporter *Exporter) error {
+
+		if len(interceptors) == 0 {
+			return &quantify.FieldError{Path: "unary_interceptors", Err: errors.New("required")}
+		}
+
+		exporter.clientOptions = append(exporter.clientOptions, option.WithGRPCDialOption(
+			grpc.WithChainUnaryInterceptor(interceptors...),
+		))
+		return nil
+	}
+}
+
+// OptionWithClientOptions supplies any other options to the client created by the
+// Exporter, for configuration not covered by the options of this package.
+//
+// Note: this only applies to the client created by the Exporter, and so can't be
+// combined with OptionWithCloudMetricsClient.
+func OptionWithClientOptions(options ...option.ClientOption) Option {
+	return func(exporter *Exporter) error {
+		exporter.clientOptions = append(exporter.clientOptions, options...)
+		return nil
+	}
+}
+
+// OptionWithStrictValidation validates metrics against every rule Cloud Monitoring
+// applies when points are written, such as the number of labels and the length of
+// their values, rather than only the naming rules, so that series it would reject
+// are caught during development with a descriptive error (see ErrStrictValidation)
+// rather than as an error from the API.
+//
+// Metrics are validated when their instruments are created, or, for instruments
+// such as a quantify.CounterVec, when each series is first recorded. Series whose
+// labels are only known when they're reported are validated by Export, which drops
+// those that are invalid and returns the error of the first.
+func OptionWithStrictValidation() Option {
+	return func(exporter *Exporter) error {
+		exporter.strict = true
+		return nil
+	}
+}
+
+// OptionWithRequestLogging logs each request made to the Monitoring API to the
+// provided logger, with the detail governed by level, giving visibility of what
+// was actually sent (e.g. when troubleshooting rejected points).
+func OptionWithRequestLogging(logger *log.Logger, level LogLevel) Option {
+	return func(exporter *Exporter) error {
+
+		if logger == nil {
+			return &quantify.FieldError{Path: "request_logging.logger", Err: errors.New("required")}
+		}
+
+		if exporter.requestLogger == nil {
+			exporter.requestLogger = &requestLogger{}
+		} else if exporter.requestLogger.logger != nil {
+			return &quantify.FieldError{Path: "request_logging", Err: quantify.ErrDuplicateOption}
+		}
+
+		exporter.requestLogger.logger = logger
+		exporter.requestLogger.level = level
+		return nil
+	}
+}
+
+// OptionWithLabelRedaction sets a function that is called with the key and value of
+// each metric and resource label in requests logged by OptionWithRequestLogging,
+// returning the value to log in its place, so that sensitive values (e.g. user IDs)
+// aren't written to logs. It doesn't affect the requests sent.
+func OptionWithLabelRedaction(fn func(key, value string) string) Option {
+	return func(exporter *Exporter) error {
+
+		if exporter.requestLogger == nil {
+			exporter.requestLogger = &requestLogger{}
+		} else if exporter.requestLogger.redact != nil {
+			return &quantify.FieldError{Path: "request_logging.redaction", Err: quantify.ErrDuplicateOption}
+		}
+
+		exporter.requestLogger.redact = fn
+		return nil
+	}
+}
@@ -0,0 +1,356 @@
+package gcms
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/rustedturnip/quantify"
+)
+
+type mockResource struct{}
+
+func (mr *mockResource) GetName() string {
+	return "mock_resource"
+}
+
+func TestOptionWithResourceType(t *testing.T) {
+
+	tests := []struct {
+		name             string
+		input            Resource
+		expectedExporter *Exporter
+		expectedError    error
+	}{
+		{
+			name: "normal input",
+			input: &ResourceGenericNode{
+				ProjectId: "test-project",
+				Location:  "test-location",
+				Namespace: "test-namespace",
+				NodeId:    "test-node-id",
+			},
+			expectedExporter: &Exporter{
+				resource: &ResourceGenericNode{
+					ProjectId: "test-project",
+					Location:  "test-location",
+					Namespace: "test-namespace",
+					NodeId:    "test-node-id",
+				},
+				resourceName: "generic_node",
+				resourceLabels: map[string]string{
+					"project_id": "test-project",
+					"location":   "test-location",
+					"namespace":  "test-namespace",
+					"node_id":    "test-node-id",
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name:             "missing project_id",
+			input:            &mockResource{},
+			expectedExporter: &Exporter{},
+			expectedError:    &quantify.FieldError{Path: "resource.labels.project_id", Err: errors.New("required")},
+		},
+	}
+
+	for _, test := range tests {
+
+		fn := OptionWithResourceType(Resource(test.input))
+		exporter := &Exporter{}
+
+		assert.Equalf(t, test.expectedError, fn(exporter), "%s failed", test.name)
+		assert.Equalf(t, test.expectedExporter, exporter, "%s failed", test.name)
+	}
+}
+
+func TestNew_resourcePolicy(t *testing.T) {
+
+	incomplete := &ResourceGceInstance{
+		ProjectId: "quantify",
+		Zone:      "europe-west2-a",
+	}
+
+	var warned []string
+
+	tests := []struct {
+		name                   string
+		options                []Option
+		expectedResourceName   string
+		expectedResourceLabels map[string]string
+		expectedWarned         []string
+		expectedError          error
+	}{
+		{
+			name:          "default",
+			options:       nil,
+			expectedError: ErrIncompleteResource,
+		},
+		{
+			name:          "error",
+			options:       []Option{OptionWithResourcePolicy(ResourcePolicyError)},
+			expectedError: ErrIncompleteResource,
+		},
+		{
+			name:                   "fallback to global",
+			options:                []Option{OptionWithResourcePolicy(ResourcePolicyFallbackGlobal)},
+			expectedResourceName:   "global",
+			expectedResourceLabels: map[string]string{"project_id": "quantify"},
+		},
+		{
+			name: "warn",
+			options: []Option{OptionWithResourcePolicy(ResourcePolicyWarn(func(resource Resource, missing []string) {
+				warned = missing
+			}))},
+			expectedResourceName:   "gce_instance",
+			expectedResourceLabels: map[string]string{"project_id": "quantify", "zone": "europe-west2-a"},
+			expectedWarned:         []string{"instance_id"},
+		},
+	}
+
+	for _, test := range tests {
+
+		warned = nil
+
+		options := append([]Option{
+			OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+			OptionWithResourceType(incomplete),
+		}, test.options...)
+
+		exporter, err := New(context.Background(), options...)
+
+		if test.expectedError != nil {
+			assert.ErrorIsf(t, err, test.expectedError, "%s failed", test.name)
+			continue
+		}
+
+		if assert.NoErrorf(t, err, "%s failed", test.name) {
+			assert.Equalf(t, test.expectedResourceName, exporter.resourceName, "%s failed", test.name)
+			assert.Equalf(t, test.expectedResourceLabels, exporter.resourceLabels, "%s failed", test.name)
+			assert.Equalf(t, test.expectedWarned, warned, "%s failed", test.name)
+		}
+	}
+}
+
+func TestOptionWithUserAgent(t *testing.T) {
+
+	exporter := &Exporter{}
+
+	err := OptionWithUserAgent("quantify/1.2 service/foo")(exporter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []option.ClientOption{option.WithUserAgent("quantify/1.2 service/foo")}, exporter.clientOptions)
+}
+
+func TestOptionWithTLSConfig(t *testing.T) {
+
+	exporter := &Exporter{}
+
+	assert.EqualError(t, OptionWithTLSConfig(nil)(exporter), "tls_config: required")
+	assert.Len(t, exporter.clientOptions, 0)
+
+	assert.NoError(t, OptionWithTLSConfig(&tls.Config{})(exporter))
+	assert.Len(t, exporter.clientOptions, 1)
+}
+
+func TestOptionWithEndpoint(t *testing.T) {
+
+	exporter := &Exporter{}
+
+	err := OptionWithEndpoint("private.googleapis.com:443")(exporter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []option.ClientOption{option.WithEndpoint("private.googleapis.com:443")}, exporter.clientOptions)
+}
+
+// metricServiceServer implements monitoringpb.MetricServiceServer, accepting all
+// CreateTimeSeries requests.
+type metricServiceServer struct {
+	monitoringpb.UnimplementedMetricServiceServer
+}
+
+func (mss *metricServiceServer) CreateTimeSeries(ctx context.Context, request *monitoringpb.CreateTimeSeriesRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+func TestOptionWithUnaryInterceptors(t *testing.T) {
+
+	assert.EqualError(t, OptionWithUnaryInterceptors()(&Exporter{}), "unary_interceptors: required")
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	server := grpc.NewServer()
+	monitoringpb.RegisterMetricServiceServer(server, &metricServiceServer{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	var calls []string
+	interceptor := func(name string) grpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			calls = append(calls, name+" "+method)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+
+	exporter, err := New(
+		context.Background(),
+		OptionWithResourceType(&ResourceGlobal{ProjectId: "quantify"}),
+		OptionWithEndpoint(listener.Addr().String()),
+		OptionWithClientOptions(
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		),
+		OptionWithUnaryInterceptors(interceptor("first"), interceptor("second")),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = exporter.Export(context.Background(), []*quantify.Series{
+		{
+			Metric: &quantify.Metric{Name: "planes"},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: 1,
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"first /google.monitoring.v3.MetricService/CreateTimeSeries",
+		"second /google.monitoring.v3.MetricService/CreateTimeSeries",
+	}, calls)
+}
+
+func TestExporter_Close(t *testing.T) {
+
+	baseline := runtime.NumGoroutine()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	server := grpc.NewServer()
+	monitoringpb.RegisterMetricServiceServer(server, &metricServiceServer{})
+	go server.Serve(listener)
+
+	exporter, err := New(
+		context.Background(),
+		OptionWithResourceType(&ResourceGlobal{ProjectId: "quantify"}),
+		OptionWithEndpoint(listener.Addr().String()),
+		OptionWithClientOptions(
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+		),
+	)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, exporter.Close())
+	assert.NoError(t, exporter.Close())
+
+	server.Stop()
+
+	// the client's connections have been released
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), baseline, "goroutines leaked")
+}
+
+func TestNew_optionConflicts(t *testing.T) {
+
+	resource := &ResourceGlobal{ProjectId: "quantify"}
+
+	tests := []struct {
+		name          string
+		options       []Option
+		expectedError error
+	}{
+		{
+			name: "duplicate clients",
+			options: []Option{
+				OptionWithResourceType(resource),
+				OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+				OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+			},
+			expectedError: quantify.ErrDuplicateOption,
+		},
+		{
+			name: "duplicate resources",
+			options: []Option{
+				OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+				OptionWithResourceType(resource),
+				OptionWithResourceType(resource),
+			},
+			expectedError: quantify.ErrDuplicateOption,
+		},
+		{
+			name: "client options with supplied client",
+			options: []Option{
+				OptionWithResourceType(resource),
+				OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+				OptionWithUserAgent("quantify"),
+			},
+			expectedError: quantify.ErrConflictingOptions,
+		},
+		{
+			name: "duplicate request logging",
+			options: []Option{
+				OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+				OptionWithResourceType(resource),
+				OptionWithRequestLogging(log.Default(), LogLevelSummary),
+				OptionWithRequestLogging(log.Default(), LogLevelFull),
+			},
+			expectedError: quantify.ErrDuplicateOption,
+		},
+		{
+			name: "redaction without request logging",
+			options: []Option{
+				OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+				OptionWithResourceType(resource),
+				OptionWithLabelRedaction(func(key, value string) string { return "" }),
+			},
+			expectedError: quantify.ErrConflictingOptions,
+		},
+		{
+			name: "invalid project id",
+			options: []Option{
+				OptionWithCloudMetricsClient(&monitoring.MetricClient{}),
+				OptionWithResourceType(&ResourceGlobal{ProjectId: "Quantify!"}),
+			},
+			expectedError: ErrInvalidProjectId,
+		},
+	}
+
+	for _, test := range tests {
+
+		_, err := New(context.Background(), test.options...)
+
+		assert.ErrorIsf(t, err, test.expectedError, "%s failed", test.name)
+	}
+}
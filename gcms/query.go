@@ -0,0 +1,106 @@
+package gcms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"google.golang.org/api/iterator"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Query implements quantify.Querier, reading back the series of the named custom
+// metric, reported against the Exporter's resource type, that have points within
+// the provided time range.
+func (e *Exporter) Query(ctx context.Context, name string, start time.Time, end time.Time) ([]*quantify.Series, error) {
+
+	e.clientMu.RLock()
+	defer e.clientMu.RUnlock()
+
+	it := e.client.ListTimeSeries(ctx, e.createListTimeSeriesRequestProto(name, start, end))
+
+	series := make([]*quantify.Series, 0)
+
+	for {
+		ts, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		series = append(series, timeSeriesProtoToSeries(ts))
+	}
+
+	return series, nil
+}
+
+// createListTimeSeriesRequestProto compiles a monitoringpb.ListTimeSeriesRequest proto
+// for the named custom metric within the Exporter's project scope.
+func (e *Exporter) createListTimeSeriesRequestProto(name string, start time.Time, end time.Time) *monitoringpb.ListTimeSeriesRequest {
+	return &monitoringpb.ListTimeSeriesRequest{
+		Name: e.ProjectPath(),
+		Filter: fmt.Sprintf(
+			"metric.type = %q AND resource.type = %q",
+			MetricType(name),
+			e.resourceName,
+		),
+		Interval: &monitoringpb.TimeInterval{
+			StartTime: timestamppb.New(start),
+			EndTime:   timestamppb.New(end),
+		},
+		View: monitoringpb.ListTimeSeriesRequest_FULL,
+	}
+}
+
+// timeSeriesProtoToSeries converts a monitoringpb.TimeSeries, as returned by the
+// API, into a quantify.Series. Points are ordered by start time ascending.
+func timeSeriesProtoToSeries(ts *monitoringpb.TimeSeries) *quantify.Series {
+
+	series := &quantify.Series{
+		Metric: &quantify.Metric{
+			Name:   strings.TrimPrefix(ts.GetMetric().GetType(), customMetricRoot+"/"),
+			Labels: ts.GetMetric().GetLabels(),
+		},
+		Points: make([]*quantify.Point, 0, len(ts.GetPoints())),
+	}
+
+	if ts.GetValueType() == metricpb.MetricDescriptor_DOUBLE {
+		series.Metric.ValueType = quantify.ValueTypeDouble
+	}
+
+	// the API returns points in reverse time order
+	for i := len(ts.GetPoints()) - 1; i >= 0; i-- {
+
+		point := ts.GetPoints()[i]
+
+		// gauges have no start time
+		if ts.GetMetricKind() == metricpb.MetricDescriptor_GAUGE {
+			series.Metric.Kind = quantify.MetricKindGauge
+			series.Points = append(series.Points, &quantify.Point{
+				Start: point.GetInterval().GetEndTime().AsTime(),
+				End:   point.GetInterval().GetEndTime().AsTime(),
+				Count: point.GetValue().GetInt64Value(),
+				Value: point.GetValue().GetDoubleValue(),
+			})
+			continue
+		}
+
+		series.Points = append(series.Points, &quantify.Point{
+			Start: point.GetInterval().GetStartTime().AsTime(),
+
+			// add back the millisecond removed when the point was written
+			End:   point.GetInterval().GetEndTime().AsTime().Add(time.Millisecond),
+			Count: point.GetValue().GetInt64Value(),
+			Value: point.GetValue().GetDoubleValue(),
+		})
+	}
+
+	return series
+}
@@ -0,0 +1,79 @@
+package gcms
+
+import (
+	"testing"
+	"time"
+
+	monitoringpb "cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/stretchr/testify/assert"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestExporter_createListTimeSeriesRequestProto(t *testing.T) {
+
+	exporter := &Exporter{
+		resourceName: "global",
+		resourceLabels: map[string]string{
+			"project_id": "quantify",
+		},
+	}
+
+	request := exporter.createListTimeSeriesRequestProto("planes", time.Unix(1672693340, 0), time.Unix(1672693400, 0))
+
+	assert.Equal(t, "projects/quantify", request.Name)
+	assert.Equal(t, `metric.type = "custom.googleapis.com/planes" AND resource.type = "global"`, request.Filter)
+	assert.Equal(t, int64(1672693340), request.Interval.StartTime.Seconds)
+	assert.Equal(t, int64(1672693400), request.Interval.EndTime.Seconds)
+}
+
+func TestTimeSeriesProtoToSeries(t *testing.T) {
+
+	point := func(start int64, count int64) *monitoringpb.Point {
+		return &monitoringpb.Point{
+			Interval: &monitoringpb.TimeInterval{
+				StartTime: timestamppb.New(time.Unix(start, 0)),
+				EndTime:   timestamppb.New(time.Unix(start+10, 0).Add(-time.Millisecond)),
+			},
+			Value: &monitoringpb.TypedValue{
+				Value: &monitoringpb.TypedValue_Int64Value{
+					Int64Value: count,
+				},
+			},
+		}
+	}
+
+	input := &monitoringpb.TimeSeries{
+		Metric: &metricpb.Metric{
+			Type:   "custom.googleapis.com/planes",
+			Labels: map[string]string{"model": "737-800"},
+		},
+		Points: []*monitoringpb.Point{
+			point(1672693350, 7),
+			point(1672693340, 5),
+		},
+	}
+
+	expected := &quantify.Series{
+		Metric: &quantify.Metric{
+			Name:   "planes",
+			Labels: map[string]string{"model": "737-800"},
+		},
+		Points: []*quantify.Point{
+			{
+				Start: time.Unix(1672693340, 0).UTC(),
+				End:   time.Unix(1672693350, 0).UTC(),
+				Count: 5,
+			},
+			{
+				Start: time.Unix(1672693350, 0).UTC(),
+				End:   time.Unix(1672693360, 0).UTC(),
+				Count: 7,
+			},
+		},
+	}
+
+	assert.Equal(t, expected, timeSeriesProtoToSeries(input))
+}
@@ -0,0 +1,228 @@
+package gcms
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"cloud.google.com/go/compute/metadata"
+
+	"github.com/rustedturnip/quantify"
+)
+
+const (
+	cloudResourceFieldTag = "cloud_resource_field"
+
+	resourceNameGlobal       = "global"
+	resourceNameGkeContainer = "gke_container"
+	resourceNameGceInstance  = "gce_instance"
+	resourceNameGenericNode  = "generic_node"
+	resourceNameGenericTask  = "generic_task"
+)
+
+var (
+	ErrInvalidResourceFieldType = fmt.Errorf("field tagged as %s isn't of type string", cloudResourceFieldTag)
+	ErrIncompleteResource       = errors.New("resource labels missing")
+	ErrInvalidProjectId         = errors.New("invalid project ID")
+)
+
+// ResourcePolicy decides how the Exporter proceeds when the labels of its Resource
+// are incomplete, for example because the zone of an instance was detected but its
+// instance ID wasn't, as Google Cloud Monitoring rejects points written against an
+// incomplete resource. It's called by New with the resource and the keys of its
+// missing labels, returning the Resource to use in its place, or an error if New
+// should fail.
+type ResourcePolicy func(resource Resource, missing []string) (Resource, error)
+
+// ResourcePolicyError fails New with an error naming the missing labels. This is the
+// default policy.
+func ResourcePolicyError(resource Resource, missing []string) (Resource, error) {
+	return nil, &quantify.FieldError{
+		Path: "resource.labels",
+		Err:  fmt.Errorf("%w for %s: %s", ErrIncompleteResource, resource.GetName(), strings.Join(missing, ", ")),
+	}
+}
+
+// ResourcePolicyFallbackGlobal replaces an incomplete resource with the global
+// resource of the same project, which only requires a project ID.
+func ResourcePolicyFallbackGlobal(resource Resource, missing []string) (Resource, error) {
+
+	labels, err := flatten(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResourceGlobal{ProjectId: labels[resourceLabelKeyProjectId]}, nil
+}
+
+// ResourcePolicyWarn returns a ResourcePolicy that proceeds with an incomplete
+// resource, first calling fn with the resource and the keys of its missing labels
+// (e.g. so they can be logged).
+func ResourcePolicyWarn(fn func(resource Resource, missing []string)) ResourcePolicy {
+	return func(resource Resource, missing []string) (Resource, error) {
+		fn(resource, missing)
+		return resource, nil
+	}
+}
+
+type Resource interface {
+	GetName() string
+}
+
+type ResourceGlobal struct {
+	ProjectId string `cloud_resource_field:"project_id"`
+}
+
+type ResourceGceInstance struct {
+	ProjectId  string `cloud_resource_field:"project_id"`
+	InstanceId string `cloud_resource_field:"instance_id"`
+	Zone       string `cloud_resource_field:"zone"`
+}
+
+type ResourceGkeContainer struct {
+	ProjectId     string `cloud_resource_field:"project_id"`
+	ClusterName   string `cloud_resource_field:"cluster_name"`
+	InstanceId    string `cloud_resource_field:"instance_id"`
+	Zone          string `cloud_resource_field:"zone"`
+	NamespaceId   string `cloud_resource_field:"namespace_id"`
+	PodId         string `cloud_resource_field:"pod_id"`
+	ContainerName string `cloud_resource_field:"container_name"`
+}
+
+type ResourceGenericNode struct {
+	ProjectId string `cloud_resource_field:"project_id"`
+	Location  string `cloud_resource_field:"location"`
+	Namespace string `cloud_resource_field:"namespace"`
+	NodeId    string `cloud_resource_field:"node_id"`
+}
+
+type ResourceGenericTask struct {
+	ProjectId string `cloud_resource_field:"project_id"`
+	Location  string `cloud_resource_field:"location"`
+	Namespace string `cloud_resource_field:"namespace"`
+	Job       string `cloud_resource_field:"job"`
+	TaskId    string `cloud_resource_field:"task_id"`
+}
+
+func (g *ResourceGlobal) GetName() string {
+	return resourceNameGlobal
+}
+
+func (gi *ResourceGceInstance) GetName() string {
+	return resourceNameGceInstance
+}
+
+func (gc *ResourceGkeContainer) GetName() string {
+	return resourceNameGkeContainer
+}
+
+func (gn *ResourceGenericNode) GetName() string {
+	return resourceNameGenericNode
+}
+
+func (gt *ResourceGenericTask) GetName() string {
+	return resourceNameGenericTask
+}
+
+func flatten(v Resource) (map[string]string, error) {
+
+	result := make(map[string]string)
+
+	rv := reflect.ValueOf(v)
+
+	// if pointer, unwrap to get underlying struct
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+
+	t := rv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+
+		field, ok := t.Field(i).Tag.Lookup(cloudResourceFieldTag)
+		if !ok {
+			continue
+		}
+
+		if rv.Field(i).Kind() != reflect.String {
+			return nil, ErrInvalidResourceFieldType
+		}
+
+		value := rv.Field(i).String()
+
+		if value == "" {
+			continue
+		}
+
+		result[field] = value
+	}
+
+	return result, nil
+}
+
+// missingFields returns the keys of the fields of the provided Resource that are
+// tagged as resource labels, but are empty.
+func missingFields(v Resource) []string {
+
+	missing := make([]string, 0)
+
+	rv := reflect.ValueOf(v)
+
+	// if pointer, unwrap to get underlying struct
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+
+	t := rv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+
+		field, ok := t.Field(i).Tag.Lookup(cloudResourceFieldTag)
+		if !ok {
+			continue
+		}
+
+		if rv.Field(i).Kind() == reflect.String && rv.Field(i).String() == "" {
+			missing = append(missing, field)
+		}
+	}
+
+	return missing
+}
+
+func DetectProjectId() string {
+	projectId, _ := metadata.ProjectID()
+	return projectId
+}
+
+// DetectZone returns the value detected from the metadata server, or an empty string
+// if it can't be detected.
+//
+// Deprecated: use NewGceInstanceResourceFromMetadata, which retries and reports
+// why detection failed.
+func DetectZone() string {
+	zone, _ := metadata.Zone()
+	return zone
+}
+
+// DetectInstanceId returns the value detected from the metadata server, or an empty string
+// if it can't be detected.
+//
+// Deprecated: use NewGceInstanceResourceFromMetadata, which retries and reports
+// why detection failed.
+func DetectInstanceId() string {
+	instanceId, _ := metadata.InstanceID()
+	return instanceId
+}
+
+// DetectGkeClusterName returns the value detected from the metadata server, or an empty string
+// if it can't be detected.
+//
+// Deprecated: use NewGkeContainerResourceFromMetadata, which retries and reports
+// why detection failed, or LabelFromMetadataAttribute("cluster-name") with
+// OptionWithResourceLabelSources.
+func DetectGkeClusterName() string {
+	name, _ := metadata.InstanceAttributeValue("cluster-name")
+	return name
+}
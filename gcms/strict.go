@@ -0,0 +1,80 @@
+package gcms
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/rustedturnip/quantify"
+)
+
+const (
+
+	// maxMetricLabels is the maximum number of labels of a Google Cloud custom
+	// metric.
+	//
+	// see: https://cloud.google.com/monitoring/quotas
+	maxMetricLabels = 30
+
+	// maxLengthMetricLabelValue is the maximum length, in bytes, of a Google Cloud
+	// Metric label value.
+	//
+	// see: https://cloud.google.com/monitoring/quotas
+	maxLengthMetricLabelValue = 1024
+)
+
+var (
+	ErrStrictValidation = errors.New("series would be rejected by Cloud Monitoring")
+)
+
+// validateStrict returns an ErrStrictValidation if the provided metric breaks any
+// of the rules Cloud Monitoring applies when points are written, beyond those of
+// ValidateMetric (see OptionWithStrictValidation).
+func validateStrict(metric *quantify.Metric) error {
+
+	if metricType := MetricType(metric.Name); len(metricType) > maxLengthMetricType {
+		return fmt.Errorf("%w: metric type %s exceeds %d characters", ErrStrictValidation, metricType, maxLengthMetricType)
+	}
+
+	if len(metric.Labels) > maxMetricLabels {
+		return fmt.Errorf("%w: metric %s has %d labels, more than %d", ErrStrictValidation, metric.Name, len(metric.Labels), maxMetricLabels)
+	}
+
+	for key, value := range metric.Labels {
+
+		if len(value) > maxLengthMetricLabelValue {
+			return fmt.Errorf("%w: value of label %s of metric %s exceeds %d bytes", ErrStrictValidation, key, metric.Name, maxLengthMetricLabelValue)
+		}
+
+		if !utf8.ValidString(value) {
+			return fmt.Errorf("%w: value of label %s of metric %s isn't valid UTF-8", ErrStrictValidation, key, metric.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateSeries returns the provided series without any that break the rules of
+// validateStrict, along with the error of the first that does. Series whose labels
+// are only known when they're recorded, such as those of a quantify.TopK, aren't
+// validated when their instrument is created, so are caught here instead.
+func validateSeries(series []*quantify.Series) ([]*quantify.Series, error) {
+
+	var firstErr error
+
+	valid := make([]*quantify.Series, 0, len(series))
+
+	for _, s := range series {
+
+		if err := validateStrict(s.Metric); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		valid = append(valid, s)
+	}
+
+	return valid, firstErr
+}
@@ -0,0 +1,139 @@
+package gcms
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestExporter_ValidateMetric_strict(t *testing.T) {
+
+	manyLabels := make(map[string]string)
+	for i := 0; i <= maxMetricLabels; i++ {
+		manyLabels[fmt.Sprintf("label_%d", i)] = "value"
+	}
+
+	tests := []struct {
+		name          string
+		strict        bool
+		input         *quantify.Metric
+		expectedError error
+	}{
+		{
+			name:   "valid metric",
+			strict: true,
+			input: &quantify.Metric{
+				Name: "planes",
+				Labels: map[string]string{
+					"colour": "red",
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "metric type too long",
+			strict: true,
+			input: &quantify.Metric{
+				Name: strings.Repeat("p", maxLengthMetricType-1),
+			},
+			expectedError: ErrStrictValidation,
+		},
+		{
+			name:   "too many labels",
+			strict: true,
+			input: &quantify.Metric{
+				Name:   "planes",
+				Labels: manyLabels,
+			},
+			expectedError: ErrStrictValidation,
+		},
+		{
+			name:   "label value too long",
+			strict: true,
+			input: &quantify.Metric{
+				Name: "planes",
+				Labels: map[string]string{
+					"colour": strings.Repeat("r", maxLengthMetricLabelValue+1),
+				},
+			},
+			expectedError: ErrStrictValidation,
+		},
+		{
+			name:   "label value invalid utf-8",
+			strict: true,
+			input: &quantify.Metric{
+				Name: "planes",
+				Labels: map[string]string{
+					"colour": "r\xffd",
+				},
+			},
+			expectedError: ErrStrictValidation,
+		},
+		{
+			name:   "label value too long, not strict",
+			strict: false,
+			input: &quantify.Metric{
+				Name: "planes",
+				Labels: map[string]string{
+					"colour": strings.Repeat("r", maxLengthMetricLabelValue+1),
+				},
+			},
+			expectedError: nil,
+		},
+	}
+
+	for _, test := range tests {
+
+		err := (&Exporter{strict: test.strict}).ValidateMetric(test.input)
+
+		if test.expectedError == nil {
+			assert.NoErrorf(t, err, "%s failed", test.name)
+			continue
+		}
+
+		assert.Truef(t, errors.Is(err, test.expectedError), "%s failed", test.name)
+	}
+}
+
+func TestValidateSeries(t *testing.T) {
+
+	valid := &quantify.Series{
+		Metric: &quantify.Metric{
+			Name:   "top_models",
+			Labels: map[string]string{"model": "737-800"},
+		},
+	}
+
+	invalid := &quantify.Series{
+		Metric: &quantify.Metric{
+			Name:   "top_models",
+			Labels: map[string]string{"model": strings.Repeat("7", maxLengthMetricLabelValue+1)},
+		},
+	}
+
+	series, err := validateSeries([]*quantify.Series{invalid, valid})
+
+	assert.True(t, errors.Is(err, ErrStrictValidation))
+	assert.Equal(t, []*quantify.Series{valid}, series)
+}
+
+func TestExporter_LengthLimits(t *testing.T) {
+
+	limits := (&Exporter{}).LengthLimits()
+
+	// names truncated to the limit pass even strict validation
+	metric := &quantify.Metric{
+		Name: strings.Repeat("p", limits.Name),
+		Labels: map[string]string{
+			strings.Repeat("c", limits.LabelKey): strings.Repeat("r", limits.LabelValue),
+		},
+	}
+
+	assert.NoError(t, (&Exporter{strict: true}).ValidateMetric(metric))
+	assert.Len(t, MetricType(metric.Name), maxLengthMetricType)
+}
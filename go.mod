@@ -9,6 +9,7 @@ require (
 	github.com/stretchr/testify v1.8.1
 	google.golang.org/api v0.106.0
 	google.golang.org/genproto v0.0.0-20230106154932-a12b697841d9
+	google.golang.org/grpc v1.51.0
 	google.golang.org/protobuf v1.28.1
 )
 
@@ -27,6 +28,5 @@ require (
 	golang.org/x/sys v0.0.0-20220728004956-3c1f35247d10 // indirect
 	golang.org/x/text v0.5.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
-	google.golang.org/grpc v1.51.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
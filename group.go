@@ -0,0 +1,128 @@
+package quantify
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// CounterGroup groups related counters, such as the hits and misses of a cache, so
+// that their series are always reported together: in the same Export call, for the
+// same intervals, and adjacent to one another once sorted (see SortSeries), so that
+// exporters batching series into requests (such as gcms) keep them in the same
+// request. This stops dashboards momentarily showing inconsistent ratios between
+// them.
+//
+// Counters of a group share the interval of the group. So that their intervals are
+// never split across reports, they aren't limited by OptionWithMaxPointsPerFlush.
+type CounterGroup struct {
+	q        *Quantifier
+	name     string
+	interval int64
+}
+
+// CreateCounterGroup creates a CounterGroup with the provided name, whose counters
+// count in intervals of the provided number of seconds.
+func (q *Quantifier) CreateCounterGroup(name string, interval int64) (*CounterGroup, error) {
+
+	if name == "" {
+		return nil, &FieldError{Path: "name", Err: errors.New("required")}
+	}
+
+	if interval <= 0 {
+		return nil, errors.New("interval must be greater than 0")
+	}
+
+	return &CounterGroup{
+		q:        q,
+		name:     name,
+		interval: interval,
+	}, nil
+}
+
+// CreateCounter creates a Counter (see Quantifier.CreateCounter) within the group.
+func (g *CounterGroup) CreateCounter(name string, labels map[string]string, options ...MetricOption) (*Counter, error) {
+
+	instrument, err := g.createCounter(name, labels, options...)
+	if err != nil && g.q.noopInstead(err) {
+		return newNoopCounter(), nil
+	}
+
+	return instrument, err
+}
+
+// createCounter creates a Counter (see CreateCounter).
+func (g *CounterGroup) createCounter(name string, labels map[string]string, options ...MetricOption) (*Counter, error) {
+
+	mc, err := g.q.newMetricCounter(nil, name, labels, g.interval, options...)
+	if err != nil {
+		return nil, err
+	}
+	mc.metric.Group = g.name
+
+	g.q.registerCounter(mc)
+	return mc.counter, nil
+}
+
+// limit returns the limit on the points taken from the counter in a report, given
+// the limit of the report. Counters of a CounterGroup aren't limited.
+func (mc *metricCounter) limit(limit int) int {
+
+	if mc.metric.Group != "" {
+		return 0
+	}
+
+	return limit
+}
+
+// alignGroups adds zero points to the points taken from grouped counters, so that
+// each counter of a group has a point for every interval that any of them has.
+func alignGroups(counters []*metricCounter, taken [][]*Point) {
+
+	var groups map[string][]int
+	for i, mc := range counters {
+
+		if mc.metric.Group == "" {
+			continue
+		}
+
+		if groups == nil {
+			groups = make(map[string][]int)
+		}
+		groups[mc.metric.Group] = append(groups[mc.metric.Group], i)
+	}
+
+	for _, members := range groups {
+
+		// end of each interval taken from any counter of the group, by start
+		ends := make(map[int64]time.Time)
+		for _, i := range members {
+			for _, point := range taken[i] {
+				ends[point.Start.Unix()] = point.End
+			}
+		}
+
+		for _, i := range members {
+
+			if len(taken[i]) == len(ends) {
+				continue
+			}
+
+			present := make(map[int64]bool, len(taken[i]))
+			for _, point := range taken[i] {
+				present[point.Start.Unix()] = true
+			}
+
+			for start, end := range ends {
+				if !present[start] {
+					taken[i] = append(taken[i], &Point{Start: time.Unix(start, 0), End: end})
+				}
+			}
+
+			points := taken[i]
+			sort.Slice(points, func(a, b int) bool {
+				return points[a].Start.Before(points[b].Start)
+			})
+		}
+	}
+}
@@ -0,0 +1,107 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_CreateCounterGroup(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		groupName     string
+		interval      int64
+		expectedError string
+	}{
+		{
+			name:      "valid",
+			groupName: "cache",
+			interval:  10,
+		},
+		{
+			name:          "no name",
+			interval:      10,
+			expectedError: "name: required",
+		},
+		{
+			name:          "invalid interval",
+			groupName:     "cache",
+			expectedError: "interval must be greater than 0",
+		},
+	}
+
+	for _, test := range tests {
+
+		q := &Quantifier{clock: systemClock{}}
+
+		group, err := q.CreateCounterGroup(test.groupName, test.interval)
+		if test.expectedError != "" {
+			assert.EqualError(t, err, test.expectedError, "%s failed", test.name)
+			continue
+		}
+
+		assert.NoError(t, err, "%s failed", test.name)
+		assert.NotNil(t, group, "%s failed", test.name)
+	}
+}
+
+func TestCounterGroup_report(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+		maxPoints:    1,
+	}
+
+	group, err := client.CreateCounterGroup("cache", 10)
+	assert.NoError(t, err)
+
+	hits, err := group.CreateCounter("cache_hits", nil)
+	assert.NoError(t, err)
+
+	misses, err := group.CreateCounter("cache_misses", nil)
+	assert.NoError(t, err)
+
+	planes, err := client.CreateCounter("planes", nil, 10)
+	assert.NoError(t, err)
+
+	// hits in both intervals, but misses only in the second
+	start := mockClock.Now()
+	hits.CountAt(start)
+	hits.CountAt(start.Add(time.Second * 10))
+	misses.CountAt(start.Add(time.Second * 10))
+	planes.CountAt(start)
+	planes.CountAt(start.Add(time.Second * 10))
+
+	mockClock.Add(time.Second * 20)
+	assert.NoError(t, client.report(false))
+
+	series := make(map[string]*Series)
+	for _, s := range exporter.series {
+		series[s.Metric.Name] = s
+	}
+
+	// ungrouped counters are still limited
+	assert.Len(t, series["planes"].Points, 1)
+
+	// grouped counters are reported for the same intervals, unlimited
+	assert.Equal(t, "cache", series["cache_hits"].Metric.Group)
+	assert.Equal(t, []*Point{
+		{Start: time.Unix(1670681770, 0), End: time.Unix(1670681780, 0), Count: 1},
+		{Start: time.Unix(1670681780, 0), End: time.Unix(1670681790, 0), Count: 1},
+	}, series["cache_hits"].Points)
+	assert.Equal(t, []*Point{
+		{Start: time.Unix(1670681770, 0), End: time.Unix(1670681780, 0), Count: 0},
+		{Start: time.Unix(1670681780, 0), End: time.Unix(1670681790, 0), Count: 1},
+	}, series["cache_misses"].Points)
+}
@@ -0,0 +1,94 @@
+package quantify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	// historicalChunkSize is the maximum number of points passed to the Exporter in
+	// a single Export call when writing historical points.
+	historicalChunkSize = 100
+)
+
+var (
+	ErrInvalidPointInterval = errors.New("point end time must be after its start time")
+	ErrOverlappingPoints    = errors.New("points must not have overlapping intervals")
+	ErrNilMetric            = errors.New("metric must not be nil")
+	ErrNilPoint             = errors.New("points must not be nil")
+)
+
+// WriteHistorical writes the provided, previously recorded, points for a metric
+// directly to the Exporter, for example to migrate historical counts from another
+// system. The points don't need to be provided in order, but must not overlap.
+//
+// The metric is validated in the same way as metrics created by CreateCounter, and
+// the points are exported in chunks in chronological order. If an export fails, the
+// returned error reports how many points were written so that the write can be
+// resumed. The provided metric isn't modified, with any normalisation (e.g. see
+// OptionWithTruncation) applied to a copy.
+func (q *Quantifier) WriteHistorical(ctx context.Context, metric *Metric, points []*Point) error {
+
+	if metric == nil {
+		return ErrNilMetric
+	}
+
+	// checked before sorting, which would dereference them
+	for _, point := range points {
+		if point == nil {
+			return ErrNilPoint
+		}
+	}
+
+	// validation may truncate the name and labels, replacing rather than modifying
+	// the labels, so a shallow copy leaves the caller's metric unchanged
+	copied := *metric
+	metric = &copied
+
+	err := q.validateMetric(metric)
+	if err != nil {
+		return err
+	}
+
+	sorted := append([]*Point{}, points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	for i, point := range sorted {
+
+		if !point.End.After(point.Start) {
+			return ErrInvalidPointInterval
+		}
+
+		if point.Count < 0 {
+			return ErrNegativeValue
+		}
+
+		if i > 0 && point.Start.Before(sorted[i-1].End) {
+			return ErrOverlappingPoints
+		}
+	}
+
+	for start := 0; start < len(sorted); start += historicalChunkSize {
+
+		end := start + historicalChunkSize
+		if end > len(sorted) {
+			end = len(sorted)
+		}
+
+		err := q.exporter.Export(ctx, []*Series{
+			{
+				Metric: metric,
+				Points: sorted[start:end],
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("wrote %d of %d points: %w", start, len(sorted), err)
+		}
+	}
+
+	return nil
+}
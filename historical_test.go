@@ -0,0 +1,138 @@
+package quantify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_WriteHistorical(t *testing.T) {
+
+	start := time.Unix(1670681770, 0)
+	point := func(offset time.Duration, length time.Duration) *Point {
+		return &Point{
+			Start: start.Add(offset),
+			End:   start.Add(offset + length),
+			Count: 1,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		metric         *Metric
+		points         []*Point
+		exportErr      error
+		expectedError  error
+		expectedSeries int
+	}{
+		{
+			name:           "unordered points",
+			metric:         &Metric{Name: "planes"},
+			points:         []*Point{point(time.Minute, time.Minute), point(0, time.Minute)},
+			expectedSeries: 1,
+		},
+		{
+			name:           "chunked points",
+			metric:         &Metric{Name: "planes"},
+			points:         historicalPoints(start, 250),
+			expectedSeries: 3,
+		},
+		{
+			name:          "overlapping points",
+			metric:        &Metric{Name: "planes"},
+			points:        []*Point{point(0, time.Minute), point(time.Second*30, time.Minute)},
+			expectedError: ErrOverlappingPoints,
+		},
+		{
+			name:          "invalid interval",
+			metric:        &Metric{Name: "planes"},
+			points:        []*Point{point(0, 0)},
+			expectedError: ErrInvalidPointInterval,
+		},
+		{
+			name:          "nil metric",
+			points:        []*Point{point(0, time.Minute)},
+			expectedError: ErrNilMetric,
+		},
+		{
+			name:          "nil point",
+			metric:        &Metric{Name: "planes"},
+			points:        []*Point{point(0, time.Minute), nil},
+			expectedError: ErrNilPoint,
+		},
+		{
+			name:          "export failure",
+			metric:        &Metric{Name: "planes"},
+			points:        []*Point{point(0, time.Minute)},
+			exportErr:     errors.New("export failed"),
+			expectedError: errors.New("wrote 0 of 1 points: export failed"),
+		},
+	}
+
+	for _, test := range tests {
+
+		exporter := &mockExporter{
+			exportErr: test.exportErr,
+		}
+
+		client := &Quantifier{
+			exporter: exporter,
+		}
+
+		err := client.WriteHistorical(context.Background(), test.metric, test.points)
+
+		if test.expectedError != nil {
+			assert.EqualErrorf(t, err, test.expectedError.Error(), "%s failed", test.name)
+			continue
+		}
+
+		assert.NoErrorf(t, err, "%s failed", test.name)
+		assert.Lenf(t, exporter.series, test.expectedSeries, "%s failed", test.name)
+		assert.Truef(t, exporter.series[0].Points[0].Start.Equal(start), "%s failed", test.name)
+	}
+}
+
+func TestQuantifier_WriteHistorical_truncation(t *testing.T) {
+
+	exporter := &limitedLengthExporter{limits: LengthLimits{Name: 16, LabelKey: 12, LabelValue: 12}}
+
+	client := &Quantifier{
+		exporter: exporter,
+		truncate: true,
+	}
+
+	name := strings.Repeat("p", 20)
+	labels := map[string]string{"model": strings.Repeat("7", 20)}
+	metric := &Metric{Name: name, Labels: labels}
+
+	err := client.WriteHistorical(context.Background(), metric, historicalPoints(time.Unix(1670681770, 0), 1))
+	assert.NoError(t, err)
+
+	// the exported metric is truncated, whilst the caller's is left unchanged
+	assert.Len(t, exporter.series, 1)
+	assert.Len(t, exporter.series[0].Metric.Name, 16)
+	assert.Len(t, exporter.series[0].Metric.Labels["model"], 12)
+
+	assert.Equal(t, name, metric.Name)
+	assert.Equal(t, map[string]string{"model": strings.Repeat("7", 20)}, metric.Labels)
+}
+
+// historicalPoints returns n consecutive, minute long, points beginning at start.
+func historicalPoints(start time.Time, n int) []*Point {
+
+	points := make([]*Point, 0, n)
+
+	for i := 0; i < n; i++ {
+		points = append(points, &Point{
+			Start: start.Add(time.Duration(i) * time.Minute),
+			End:   start.Add(time.Duration(i+1) * time.Minute),
+			Count: int64(i),
+		})
+	}
+
+	return points
+}
@@ -0,0 +1,87 @@
+package quantify
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+const (
+	// hllPrecision is the number of hash bits used to select a register, giving
+	// 2^hllPrecision registers and a standard error of around 0.81%.
+	hllPrecision = 14
+	hllRegisters = 1 << hllPrecision
+)
+
+// hyperLogLog is a HyperLogLog sketch, estimating the number of distinct values
+// added to it in a fixed amount of memory.
+//
+// see: https://algo.inria.fr/flajolet/Publications/FlFuGaMe07.pdf
+type hyperLogLog struct {
+	registers [hllRegisters]uint8
+}
+
+// add records the provided value in the sketch.
+func (h *hyperLogLog) add(value string) {
+
+	x := hash64(value)
+
+	index := x >> (64 - hllPrecision)
+
+	// the remaining bits, with a guard bit so that rank is bounded
+	remaining := x<<hllPrecision | 1<<(hllPrecision-1)
+	rank := uint8(bits.LeadingZeros64(remaining) + 1)
+
+	if rank > h.registers[index] {
+		h.registers[index] = rank
+	}
+}
+
+// estimate returns the estimated number of distinct values added to the sketch.
+func (h *hyperLogLog) estimate() float64 {
+
+	m := float64(hllRegisters)
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	var sum float64
+	var zeros int
+
+	for _, register := range h.registers {
+		sum += math.Ldexp(1, -int(register))
+		if register == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha * m * m / sum
+
+	// linear counting is more accurate for small cardinalities
+	if estimate <= 2.5*m && zeros > 0 {
+		return m * math.Log(m/float64(zeros))
+	}
+
+	return estimate
+}
+
+// reset clears the sketch.
+func (h *hyperLogLog) reset() {
+	h.registers = [hllRegisters]uint8{}
+}
+
+// hash64 returns a well distributed 64-bit hash of the provided value. FNV-1a is
+// finalised with the mixer of SplitMix64, as its high bits alone aren't uniform
+// enough for register selection.
+func hash64(value string) uint64 {
+
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(value))
+	x := hasher.Sum64()
+
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
@@ -0,0 +1,49 @@
+package quantify
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperLogLog_estimate(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		distinct int
+	}{
+		{
+			name:     "empty",
+			distinct: 0,
+		},
+		{
+			name:     "small",
+			distinct: 10,
+		},
+		{
+			name:     "medium",
+			distinct: 5000,
+		},
+		{
+			name:     "large",
+			distinct: 200000,
+		},
+	}
+
+	for _, test := range tests {
+
+		sketch := &hyperLogLog{}
+
+		// add each value twice, duplicates shouldn't be counted
+		for i := 0; i < test.distinct*2; i++ {
+			sketch.add(fmt.Sprintf("user-%d", i%test.distinct))
+		}
+
+		// allow for 3 standard errors
+		assert.InDeltaf(t, float64(test.distinct), sketch.estimate(), float64(test.distinct)*0.0243+0.5, "%s failed", test.name)
+
+		sketch.reset()
+		assert.Equalf(t, 0.0, sketch.estimate(), "%s failed", test.name)
+	}
+}
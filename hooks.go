@@ -0,0 +1,112 @@
+package quantify
+
+// Hooks are functions called at points in the lifecycle of the counters of a
+// Quantifier, for example so that platform wrappers can maintain an external
+// registry of the metrics a binary emits. Any of them may be nil.
+//
+// Hooks are called synchronously, so should return promptly.
+type Hooks struct {
+
+	// OnCreate is called with the Metric of each counter created, including those
+	// created within instruments such as OutcomeCounter.
+	OnCreate func(metric *Metric)
+
+	// OnFirstPublish is called with the Metric of a counter once its first points
+	// have been exported successfully.
+	OnFirstPublish func(metric *Metric)
+
+	// OnDelete is called with the Metric of a counter when it's deleted (see
+	// Quantifier.DeleteCounter), or removed from a CounterVec once idle (see
+	// MetricOptionWithIdleTTL).
+	OnDelete func(metric *Metric)
+}
+
+// DeleteCounter stops the provided Counter being reported, such as when the entity
+// it counts (e.g. a tenant) no longer exists. Any counts not yet reported, including
+// those of the current interval, are reported with the next refresh. The Counter
+// shouldn't be used after it's deleted.
+func (q *Quantifier) DeleteCounter(counter *Counter) error {
+
+	mc := q.unregisterCounter(counter)
+	if mc == nil {
+		return ErrUnknownCounter
+	}
+
+	q.retireCounter(mc)
+	return nil
+}
+
+// retireCounter adds the provided counter, which is no longer reported, to the
+// deleted counters, so that its remaining points are reported with the next
+// refresh (see takeDeleted).
+func (q *Quantifier) retireCounter(mc *metricCounter) {
+
+	q.countersMu.Lock()
+	q.deleted = append(q.deleted, mc)
+	q.countersMu.Unlock()
+
+	if q.hooks.OnDelete != nil {
+		q.hooks.OnDelete(mc.metric)
+	}
+}
+
+// unregisterCounter stops the provided Counter being reported, returning its
+// metricCounter, or nil if it isn't registered.
+func (q *Quantifier) unregisterCounter(counter *Counter) *metricCounter {
+
+	q.countersMu.Lock()
+	defer q.countersMu.Unlock()
+
+	for i, mc := range q.counters {
+
+		if mc.counter != counter {
+			continue
+		}
+
+		// the slice is copied, rather than modified, so that snapshots of it taken
+		// by reports are unaffected (see registeredCounters)
+		q.counters = append(q.counters[:i:i], q.counters[i+1:]...)
+
+		return mc
+	}
+
+	return nil
+}
+
+// takeDeleted takes all remaining points of deleted counters, returning the series
+// to report for them.
+func (q *Quantifier) takeDeleted() []*Series {
+
+	q.countersMu.Lock()
+	deleted := q.deleted
+	q.deleted = nil
+	q.countersMu.Unlock()
+
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	series := make([]*Series, 0, len(deleted))
+
+	for _, mc := range deleted {
+
+		// the counter is no longer used, so its buffer's goroutine can exit
+		mc.counter.stopBuffer()
+
+		points := mc.counter.takePoints(true, 0)
+		if len(points) == 0 {
+			continue
+		}
+
+		if q.cumulative && mc.metric.Kind != MetricKindDelta {
+			points = mc.accumulate(points)
+		}
+
+		series = append(series, &Series{
+			Metric: mc.metric,
+			Points: points,
+		})
+	}
+
+	return series
+}
@@ -0,0 +1,193 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionWithHooks(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+	events := make([]string, 0)
+
+	record := func(event string) func(*Metric) {
+		return func(metric *Metric) {
+			events = append(events, event+" "+metric.Name)
+		}
+	}
+
+	q, err := New(
+		context.Background(),
+		OptionWithClock(mockClock),
+		OptionWithExporter(exporter),
+		OptionWithErrorHandler(func(q *Quantifier, err error) {}),
+		OptionSynchronous(),
+		OptionWithHooks(Hooks{
+			OnCreate:       record("create"),
+			OnFirstPublish: record("publish"),
+			OnDelete:       record("delete"),
+		}),
+	)
+	assert.NoError(t, err)
+
+	planes, err := q.CreateCounter("planes", nil, 10)
+	assert.NoError(t, err)
+
+	boats, err := q.CreateCounter("boats", nil, 10)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"create planes", "create boats"}, events)
+
+	// publishing isn't recorded until an export succeeds
+	exporter.exportErr = errors.New("unavailable")
+	planes.Count()
+	mockClock.Add(time.Second * 10)
+	assert.Error(t, q.Flush())
+
+	exporter.exportErr = nil
+	planes.Count()
+	mockClock.Add(time.Second * 10)
+	assert.NoError(t, q.Flush())
+
+	planes.Count()
+	mockClock.Add(time.Second * 10)
+	assert.NoError(t, q.Flush())
+
+	assert.Equal(t, []string{"create planes", "create boats", "publish planes"}, events)
+
+	// deleted counters report their remaining counts, including the current interval
+	boats.Count()
+	assert.NoError(t, q.DeleteCounter(boats))
+	assert.Equal(t, ErrUnknownCounter, q.DeleteCounter(boats))
+	assert.Equal(t, []string{"create planes", "create boats", "publish planes", "delete boats"}, events)
+
+	exporter.series = nil
+	assert.NoError(t, q.Flush())
+
+	if assert.Len(t, exporter.series, 1) {
+		assert.Equal(t, "boats", exporter.series[0].Metric.Name)
+		assert.Equal(t, int64(1), exporter.series[0].Points[0].Count)
+	}
+
+	// and are no longer reported
+	exporter.series = nil
+	mockClock.Add(time.Second * 10)
+	assert.NoError(t, q.Flush())
+	assert.Empty(t, exporter.series)
+
+	q.Stop()
+}
+
+func TestQuantifier_DeleteCounter_cumulative(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		cumulative:   true,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	totals, err := client.CreateCounter("requests", nil, 10)
+	assert.NoError(t, err)
+
+	deltas, err := client.CreateCounter("requests_delta", nil, 10, MetricOptionWithDeltaKind())
+	assert.NoError(t, err)
+
+	totals.Count()
+	deltas.Count()
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	totals.Count()
+	deltas.Count()
+	assert.NoError(t, client.DeleteCounter(totals))
+	assert.NoError(t, client.DeleteCounter(deltas))
+	exporter.series = nil
+	client.report(false)
+
+	// the remaining points of deleted delta counters aren't converted to running
+	// totals, unlike those of other counters
+	counts := make(map[string]int64)
+	for _, s := range exporter.series {
+		counts[s.Metric.Name] = s.Points[len(s.Points)-1].Count
+	}
+	assert.Equal(t, map[string]int64{"requests": 2, "requests_delta": 1}, counts)
+}
+
+func TestQuantifier_DeleteCounter_whilstReporting(t *testing.T) {
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        newMockClock(),
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	counters := make([]*Counter, 0, 100)
+	for i := 0; i < 100; i++ {
+		counter, err := client.CreateCounter("tenant_requests", map[string]string{"tenant": strconv.Itoa(i)}, 10)
+		assert.NoError(t, err)
+		counters = append(counters, counter)
+
+		counter.Count()
+	}
+
+	// reports run in the background whilst counters are deleted (run with -race)
+	started := make(chan struct{})
+	done := make(chan struct{})
+	reported := make(chan struct{})
+
+	go func() {
+		defer close(reported)
+
+		client.report(true)
+		close(started)
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				client.report(true)
+			}
+		}
+	}()
+
+	<-started
+
+	for _, counter := range counters {
+		assert.NoError(t, client.DeleteCounter(counter))
+	}
+
+	close(done)
+	<-reported
+
+	// every remaining count is reported once the deleted counters are taken
+	client.report(true)
+
+	var total int64
+	for _, s := range exporter.series {
+		for _, point := range s.Points {
+			total += point.Count
+		}
+	}
+
+	assert.Equal(t, int64(100), total)
+	assert.Empty(t, client.registeredCounters())
+}
@@ -0,0 +1,62 @@
+package quantify
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+const (
+	instanceLabelKeyHostname    = "hostname"
+	instanceLabelKeyPid         = "pid"
+	instanceLabelKeyContainerId = "container_id"
+
+	cgroupPath = "/proc/self/cgroup"
+)
+
+var (
+	// reContainerId matches the 64 character hex container ID found within cgroup
+	// paths of processes running under docker/containerd.
+	reContainerId = regexp.MustCompile("[0-9a-f]{64}")
+)
+
+// detectInstanceLabels returns labels identifying the current process instance,
+// including its hostname, pid and container ID. Any values that can't be
+// detected are omitted.
+func detectInstanceLabels() map[string]string {
+
+	labels := map[string]string{
+		instanceLabelKeyPid: strconv.Itoa(os.Getpid()),
+	}
+
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		labels[instanceLabelKeyHostname] = hostname
+	}
+
+	if f, err := os.Open(cgroupPath); err == nil {
+		defer f.Close()
+
+		if containerId := parseContainerId(f); containerId != "" {
+			labels[instanceLabelKeyContainerId] = containerId
+		}
+	}
+
+	return labels
+}
+
+// parseContainerId extracts a container ID from the contents of a cgroup file,
+// returning an empty string if there isn't one.
+func parseContainerId(r io.Reader) string {
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		if id := reContainerId.FindString(scanner.Text()); id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,50 @@
+package quantify
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContainerId(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "docker cgroup v1",
+			input:    "12:pids:/docker/3f1c0a6b4c1e2d3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f\n11:memory:/docker/3f1c0a6b4c1e2d3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f\n",
+			expected: "3f1c0a6b4c1e2d3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f",
+		},
+		{
+			name:     "kubernetes containerd",
+			input:    "0::/kubepods/burstable/pod1234/cri-containerd-aaaabbbbccccddddeeeeffff0000111122223333444455556666777788889999.scope\n",
+			expected: "aaaabbbbccccddddeeeeffff0000111122223333444455556666777788889999",
+		},
+		{
+			name:     "not in a container",
+			input:    "0::/user.slice/user-1000.slice/session-1.scope\n",
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, parseContainerId(strings.NewReader(test.input)), "%s failed", test.name)
+	}
+}
+
+func TestDetectInstanceLabels(t *testing.T) {
+
+	labels := detectInstanceLabels()
+
+	assert.Equal(t, strconv.Itoa(os.Getpid()), labels[instanceLabelKeyPid])
+
+	if hostname, err := os.Hostname(); err == nil {
+		assert.Equal(t, hostname, labels[instanceLabelKeyHostname])
+	}
+}
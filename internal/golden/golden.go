@@ -0,0 +1,141 @@
+// Package golden provides a golden file test framework for the payloads produced
+// by exporters, guarding against accidental changes to their wire formats.
+//
+// Each exporter's tests encode the payload produced for the fixed set of series
+// returned by Series, and compare it against the package's golden file with
+// Assert. Golden files can be regenerated after an intentional change by running
+// the package's tests with the -update flag, e.g.:
+//
+//	go test ./gcms -run golden -update
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+)
+
+var (
+	update = flag.Bool("update", false, "update golden files")
+)
+
+// Series returns the fixed set of series that golden files are produced from,
+// covering labelled and unlabelled counters, multiple points, units, and gauges
+// of doubles.
+func Series() []*quantify.Series {
+	return []*quantify.Series{
+		{
+			Metric: &quantify.Metric{
+				Name: "planes",
+				Labels: map[string]string{
+					"manufacturer": "boeing",
+					"model":        "737-800",
+				},
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: 3,
+				},
+				{
+					Start: time.Unix(1672693350, 0),
+					End:   time.Unix(1672693360, 0),
+					Count: 5,
+				},
+			},
+		},
+		{
+			Metric: &quantify.Metric{
+				Name: "checkout/revenue",
+				Unit: "{USD}",
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693400, 0),
+					Count: 120,
+				},
+			},
+		},
+		{
+			Metric: &quantify.Metric{
+				Name:      "slo/burn_rate",
+				Labels:    map[string]string{"window": "1h"},
+				Kind:      quantify.MetricKindGauge,
+				ValueType: quantify.ValueTypeDouble,
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693400, 0),
+					End:   time.Unix(1672693400, 0),
+					Value: 1.25,
+				},
+			},
+		},
+	}
+}
+
+// Assert compares the provided JSON payload against the golden file of the
+// provided name within the calling package's testdata directory, failing the test
+// if they differ. The payload is indented before comparison so that golden files
+// are readable, and so that differences in whitespace are ignored.
+//
+// When the -update flag is set, the golden file is written instead.
+func Assert(t *testing.T, name string, payload []byte) {
+
+	t.Helper()
+
+	indented := &bytes.Buffer{}
+
+	err := json.Indent(indented, compact(t, payload), "", "  ")
+	if err != nil {
+		t.Fatalf("failed to indent payload: %v", err)
+	}
+	indented.WriteByte('\n')
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = os.WriteFile(path, indented.Bytes(), 0o644)
+		}
+		if err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create it): %v", err)
+	}
+
+	if !bytes.Equal(expected, indented.Bytes()) {
+		t.Errorf("payload doesn't match golden file %s (run with -update if intentional)\n\nexpected:\n%s\nactual:\n%s", path, expected, indented.Bytes())
+	}
+}
+
+// compact removes insignificant whitespace from the provided JSON payload.
+func compact(t *testing.T, payload []byte) []byte {
+
+	t.Helper()
+
+	compacted := &bytes.Buffer{}
+
+	err := json.Compact(compacted, payload)
+	if err != nil {
+		t.Fatalf("failed to compact payload: %v", err)
+	}
+
+	return compacted.Bytes()
+}
@@ -0,0 +1,135 @@
+// Package record provides a serialisable representation of exported points,
+// shared by the exporters that publish points as individual records (e.g. JSON
+// messages or log lines).
+package record
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Record represents a single point of a series.
+type Record struct {
+	Metric    string            `json:"metric"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Kind      string            `json:"kind"`
+	ValueType string            `json:"value_type"`
+	Unit      string            `json:"unit,omitempty"`
+	Start     time.Time         `json:"start"`
+	End       time.Time         `json:"end"`
+
+	// Count is set for metrics of quantify.ValueTypeInt64.
+	Count *int64 `json:"count,omitempty"`
+
+	// Value is set for metrics of quantify.ValueTypeDouble.
+	Value *float64 `json:"value,omitempty"`
+
+	// Distribution is set for metrics of quantify.ValueTypeDistribution.
+	Distribution *Distribution `json:"distribution,omitempty"`
+}
+
+// Distribution represents the distribution of observations of a single point.
+type Distribution struct {
+	Count                 int64     `json:"count"`
+	Mean                  float64   `json:"mean"`
+	SumOfSquaredDeviation float64   `json:"sum_of_squared_deviation"`
+	Min                   float64   `json:"min"`
+	Max                   float64   `json:"max"`
+	Bounds                []float64 `json:"bounds"`
+	BucketCounts          []int64   `json:"bucket_counts"`
+}
+
+// FromSeries returns a Record for each point of the provided series, in the order
+// the series and points are provided.
+func FromSeries(series []*quantify.Series) []*Record {
+
+	records := make([]*Record, 0)
+
+	for _, s := range series {
+		for _, point := range s.Points {
+			records = append(records, fromPoint(s.Metric, point))
+		}
+	}
+
+	return records
+}
+
+// fromPoint returns the Record of a single point of the provided metric.
+func fromPoint(metric *quantify.Metric, point *quantify.Point) *Record {
+
+	record := &Record{
+		Metric:    metric.Name,
+		Labels:    metric.Labels,
+		Kind:      metric.Kind.String(),
+		ValueType: metric.ValueType.String(),
+		Unit:      metric.Unit,
+		Start:     point.Start.UTC(),
+		End:       point.End.UTC(),
+	}
+
+	switch metric.ValueType {
+	case quantify.ValueTypeDouble:
+		value := point.Value
+		record.Value = &value
+	case quantify.ValueTypeDistribution:
+		record.Distribution = fromDistribution(point.Distribution)
+	default:
+		count := point.Count
+		record.Count = &count
+	}
+
+	return record
+}
+
+// fromDistribution returns the Distribution of the provided value, with the finite
+// boundaries of its buckets.
+func fromDistribution(value *quantify.DistributionValue) *Distribution {
+
+	if value == nil {
+		return &Distribution{}
+	}
+
+	return &Distribution{
+		Count:                 value.Count,
+		Mean:                  value.Mean,
+		SumOfSquaredDeviation: value.SumOfSquaredDeviation,
+		Min:                   value.Min,
+		Max:                   value.Max,
+		Bounds:                value.Buckets.Boundaries(),
+		BucketCounts:          value.BucketCounts,
+	}
+}
+
+// Key returns a key identifying the series of the Record, formed of the metric
+// name and its sorted labels, e.g. "planes{manufacturer=boeing,model=737}".
+func (r *Record) Key() string {
+
+	keys := make([]string, 0, len(r.Labels))
+	for key := range r.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	builder := &strings.Builder{}
+	builder.WriteString(r.Metric)
+
+	if len(keys) == 0 {
+		return builder.String()
+	}
+
+	builder.WriteString("{")
+	for i, key := range keys {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(key)
+		builder.WriteString("=")
+		builder.WriteString(r.Labels[key])
+	}
+	builder.WriteString("}")
+
+	return builder.String()
+}
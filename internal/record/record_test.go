@@ -0,0 +1,72 @@
+package record
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestFromSeries(t *testing.T) {
+
+	series := []*quantify.Series{
+		{
+			Metric: &quantify.Metric{
+				Name:   "planes",
+				Labels: map[string]string{"model": "737", "manufacturer": "boeing"},
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: 3,
+				},
+			},
+		},
+		{
+			Metric: &quantify.Metric{
+				Name:      "burn_rate",
+				Kind:      quantify.MetricKindGauge,
+				ValueType: quantify.ValueTypeDouble,
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693350, 0),
+					End:   time.Unix(1672693350, 0),
+					Value: 1.5,
+				},
+			},
+		},
+	}
+
+	records := FromSeries(series)
+
+	encoded, err := json.Marshal(records)
+	assert.NoError(t, err)
+
+	assert.JSONEq(t, `[
+		{
+			"metric": "planes",
+			"labels": {"manufacturer": "boeing", "model": "737"},
+			"kind": "CUMULATIVE",
+			"value_type": "INT64",
+			"start": "2023-01-02T21:02:20Z",
+			"end": "2023-01-02T21:02:30Z",
+			"count": 3
+		},
+		{
+			"metric": "burn_rate",
+			"kind": "GAUGE",
+			"value_type": "DOUBLE",
+			"start": "2023-01-02T21:02:30Z",
+			"end": "2023-01-02T21:02:30Z",
+			"value": 1.5
+		}
+	]`, string(encoded))
+
+	assert.Equal(t, "planes{manufacturer=boeing,model=737}", records[0].Key())
+	assert.Equal(t, "burn_rate", records[1].Key())
+}
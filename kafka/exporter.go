@@ -0,0 +1,104 @@
+// Package kafka provides a quantify.Exporter that produces each exported point
+// as a JSON record to a Kafka topic, allowing points to be consumed by custom
+// downstream aggregation pipelines.
+//
+// The package doesn't depend on a particular Kafka client. Instead, a Producer
+// wrapping the client of choice must be provided.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/rustedturnip/quantify"
+	"github.com/rustedturnip/quantify/internal/record"
+)
+
+var (
+	ErrNoProducer = errors.New("no producer provided")
+	ErrNoTopic    = errors.New("no topic provided")
+)
+
+// Message is a single Kafka message to be produced.
+type Message struct {
+
+	// Key identifies the series of the point held by the message, so that all
+	// points of a series are produced to the same partition.
+	Key []byte
+
+	// Value is the JSON encoded point.
+	Value []byte
+}
+
+// Producer produces messages to a Kafka topic, and is implemented by wrapping a
+// Kafka client.
+type Producer interface {
+
+	// Produce writes the provided messages to the provided topic, returning once
+	// they have been acknowledged.
+	Produce(ctx context.Context, topic string, messages []*Message) error
+}
+
+// Exporter implements quantify.Exporter, producing points to a Kafka topic.
+type Exporter struct {
+	producer Producer
+	topic    string
+}
+
+// New returns an instantiated Exporter that produces points to the provided topic
+// using the provided Producer.
+func New(producer Producer, topic string) (*Exporter, error) {
+
+	if producer == nil {
+		return nil, ErrNoProducer
+	}
+
+	if topic == "" {
+		return nil, ErrNoTopic
+	}
+
+	return &Exporter{
+		producer: producer,
+		topic:    topic,
+	}, nil
+}
+
+// Export implements quantify.Exporter, producing a message for each point of the
+// provided series. Each message's value is a JSON object of the form:
+//
+//	{
+//	  "metric": "planes",
+//	  "labels": {"manufacturer": "boeing"},
+//	  "kind": "CUMULATIVE",
+//	  "value_type": "INT64",
+//	  "start": "2023-01-02T21:02:20Z",
+//	  "end": "2023-01-02T21:02:30Z",
+//	  "count": 3
+//	}
+//
+// where "count" is replaced by "value" for metrics of quantify.ValueTypeDouble.
+func (e *Exporter) Export(ctx context.Context, series []*quantify.Series) error {
+
+	records := record.FromSeries(series)
+	if len(records) == 0 {
+		return nil
+	}
+
+	messages := make([]*Message, 0, len(records))
+
+	for _, r := range records {
+
+		value, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+
+		messages = append(messages, &Message{
+			Key:   []byte(r.Key()),
+			Value: value,
+		})
+	}
+
+	return e.producer.Produce(ctx, e.topic, messages)
+}
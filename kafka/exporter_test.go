@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+type mockProducer struct {
+	topic    string
+	messages []*Message
+	err      error
+}
+
+func (mp *mockProducer) Produce(ctx context.Context, topic string, messages []*Message) error {
+	mp.topic = topic
+	mp.messages = append(mp.messages, messages...)
+	return mp.err
+}
+
+func TestNew(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		producer      Producer
+		topic         string
+		expectedError error
+	}{
+		{
+			name:          "normal input",
+			producer:      &mockProducer{},
+			topic:         "metrics",
+			expectedError: nil,
+		},
+		{
+			name:          "missing producer",
+			producer:      nil,
+			topic:         "metrics",
+			expectedError: ErrNoProducer,
+		},
+		{
+			name:          "missing topic",
+			producer:      &mockProducer{},
+			topic:         "",
+			expectedError: ErrNoTopic,
+		},
+	}
+
+	for _, test := range tests {
+
+		_, err := New(test.producer, test.topic)
+
+		assert.Equalf(t, test.expectedError, err, "%s failed", test.name)
+	}
+}
+
+func TestExporter_Export(t *testing.T) {
+
+	producer := &mockProducer{}
+
+	exporter, err := New(producer, "metrics")
+	assert.NoError(t, err)
+
+	series := []*quantify.Series{
+		{
+			Metric: &quantify.Metric{
+				Name:   "planes",
+				Labels: map[string]string{"manufacturer": "boeing"},
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: 3,
+				},
+				{
+					Start: time.Unix(1672693350, 0),
+					End:   time.Unix(1672693360, 0),
+					Count: 1,
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, exporter.Export(context.Background(), series))
+
+	assert.Equal(t, "metrics", producer.topic)
+	assert.Len(t, producer.messages, 2)
+	assert.Equal(t, "planes{manufacturer=boeing}", string(producer.messages[0].Key))
+	assert.JSONEq(t, `{
+		"metric": "planes",
+		"labels": {"manufacturer": "boeing"},
+		"kind": "CUMULATIVE",
+		"value_type": "INT64",
+		"start": "2023-01-02T21:02:20Z",
+		"end": "2023-01-02T21:02:30Z",
+		"count": 3
+	}`, string(producer.messages[0].Value))
+
+	// producer errors are returned
+	producer.err = errors.New("broker unavailable")
+	assert.Equal(t, producer.err, exporter.Export(context.Background(), series))
+}
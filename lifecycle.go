@@ -0,0 +1,89 @@
+package quantify
+
+const (
+
+	// LifecycleStartsMetric is the name of the counter of process starts recorded
+	// with OptionWithLifecycleMetrics.
+	LifecycleStartsMetric = "process_starts"
+
+	// LifecycleShutdownsMetric is the name of the counter of process shutdowns
+	// recorded with OptionWithLifecycleMetrics, labelled with ShutdownReasonLabelKey.
+	LifecycleShutdownsMetric = "process_shutdowns"
+
+	// ShutdownReasonLabelKey is the key of the label describing how the Quantifier
+	// was shut down.
+	ShutdownReasonLabelKey = "reason"
+
+	// ShutdownReasonStop is the reason of a clean shutdown, with Stop or Close.
+	ShutdownReasonStop = "stop"
+
+	// ShutdownReasonContextCancelled is the reason of a shutdown caused by the
+	// cancellation of the context provided to New.
+	ShutdownReasonContextCancelled = "context_cancelled"
+
+	// lifecycleInterval is the interval (in seconds) of the lifecycle counters.
+	lifecycleInterval = 60
+)
+
+// lifecycle holds the counters recorded with OptionWithLifecycleMetrics.
+type lifecycle struct {
+	shutdowns *CounterVec
+}
+
+// OptionWithLifecycleMetrics can be used to count the starts and shutdowns of the
+// process, with the LifecycleStartsMetric and LifecycleShutdownsMetric counters.
+// A start is counted when the Quantifier is created, and a shutdown when it's
+// stopped, labelled with whether it was a clean Stop (ShutdownReasonStop) or the
+// cancellation of its context (ShutdownReasonContextCancelled). Starts that
+// outnumber shutdowns reveal processes that crashed, and frequent starts a crash
+// loop, from metrics alone.
+//
+// So that the shutdown is reported, the current interval of every counter is
+// flushed when the context is cancelled, as it is by Stop.
+func OptionWithLifecycleMetrics() Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("lifecycle_metrics"); err != nil {
+			return err
+		}
+
+		q.lifecycle = &lifecycle{}
+		return nil
+	}
+}
+
+// startLifecycle creates the lifecycle counters, counting the start of the process,
+// if lifecycle metrics are enabled (see OptionWithLifecycleMetrics).
+func (q *Quantifier) startLifecycle() error {
+
+	if q.lifecycle == nil {
+		return nil
+	}
+
+	starts, err := q.CreateCounter(LifecycleStartsMetric, nil, lifecycleInterval)
+	if err != nil {
+		return err
+	}
+
+	shutdowns, err := q.CreateCounterVec(LifecycleShutdownsMetric, []string{ShutdownReasonLabelKey}, lifecycleInterval)
+	if err != nil {
+		return err
+	}
+
+	q.lifecycle.shutdowns = shutdowns
+	starts.Count()
+
+	return nil
+}
+
+// countShutdown counts the shutdown of the process for the provided reason, if
+// lifecycle metrics are enabled. It's called once, by the final report (see
+// finalReport).
+func (q *Quantifier) countShutdown(reason string) {
+
+	if q.lifecycle == nil || q.lifecycle.shutdowns == nil {
+		return
+	}
+
+	q.lifecycle.shutdowns.With(ShutdownReasonLabelKey, reason).Count()
+}
@@ -0,0 +1,123 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lifecycleCounts returns the total counted for each lifecycle series exported, keyed
+// by the metric name and shutdown reason.
+func lifecycleCounts(exporter *mockExporter) map[string]int64 {
+
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+
+	counts := make(map[string]int64)
+	for _, s := range exporter.series {
+
+		key := s.Metric.Name
+		if reason, ok := s.Metric.Labels[ShutdownReasonLabelKey]; ok {
+			key += "/" + reason
+		}
+
+		for _, point := range s.Points {
+			counts[key] += point.Count
+		}
+	}
+
+	return counts
+}
+
+func TestOptionWithLifecycleMetrics(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		shutdown func(q *Quantifier, cancel context.CancelFunc)
+		expected map[string]int64
+	}{
+		{
+			name: "stopped",
+			shutdown: func(q *Quantifier, cancel context.CancelFunc) {
+				q.Stop()
+			},
+			expected: map[string]int64{
+				LifecycleStartsMetric:                               1,
+				LifecycleShutdownsMetric + "/" + ShutdownReasonStop: 1,
+			},
+		},
+		{
+			name: "closed twice",
+			shutdown: func(q *Quantifier, cancel context.CancelFunc) {
+				_ = q.Close()
+				_ = q.Close()
+			},
+			expected: map[string]int64{
+				LifecycleStartsMetric:                               1,
+				LifecycleShutdownsMetric + "/" + ShutdownReasonStop: 1,
+			},
+		},
+		{
+			name: "context cancelled",
+			shutdown: func(q *Quantifier, cancel context.CancelFunc) {
+				cancel()
+			},
+			expected: map[string]int64{
+				LifecycleStartsMetric: 1,
+				LifecycleShutdownsMetric + "/" + ShutdownReasonContextCancelled: 1,
+			},
+		},
+		{
+			name: "stopped after context cancelled",
+			shutdown: func(q *Quantifier, cancel context.CancelFunc) {
+				cancel()
+				<-q.stopped
+				q.Stop()
+			},
+			expected: map[string]int64{
+				LifecycleStartsMetric: 1,
+				LifecycleShutdownsMetric + "/" + ShutdownReasonContextCancelled: 1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		exporter := &mockExporter{}
+
+		q, err := New(ctx, OptionWithExporter(exporter), OptionWithLifecycleMetrics())
+		assert.NoErrorf(t, err, "%s failed", test.name)
+
+		test.shutdown(q, cancel)
+
+		assert.Eventuallyf(t, func() bool {
+			return assert.ObjectsAreEqual(test.expected, lifecycleCounts(exporter))
+		}, time.Second, time.Millisecond*10, "%s failed", test.name)
+
+		cancel()
+	}
+}
+
+func TestOptionWithLifecycleMetrics_disabled(t *testing.T) {
+
+	exporter := &mockExporter{}
+
+	q, err := New(context.Background(), OptionWithExporter(exporter), OptionSynchronous())
+	assert.NoError(t, err)
+
+	q.Stop()
+
+	assert.Empty(t, lifecycleCounts(exporter))
+}
+
+func TestOptionWithLifecycleMetrics_duplicate(t *testing.T) {
+
+	_, err := New(context.Background(), OptionWithExporter(&mockExporter{}), OptionWithLifecycleMetrics(), OptionWithLifecycleMetrics())
+
+	assert.ErrorIs(t, err, ErrDuplicateOption)
+}
@@ -0,0 +1,167 @@
+package quantify
+
+import (
+	"time"
+	"unsafe"
+)
+
+const (
+
+	// intervalBytes is the approximate memory held by each interval of a Counter
+	// that is yet to be reported, including the overhead of its map entry.
+	intervalBytes = 64
+
+	// pointBytes is the approximate memory held by each point of a series yet to be
+	// exported, such as those pending a checkpoint.
+	pointBytes = int64(unsafe.Sizeof(Point{})) + 16
+)
+
+// memoryUsage returns the approximate number of bytes held by the Quantifier for
+// counts, observations and series that are yet to be reported. It's an estimate of
+// the state that grows with a backlog, rather than the memory of the process.
+func (q *Quantifier) memoryUsage() int64 {
+
+	var usage int64
+
+	for _, mc := range q.registeredCounters() {
+		usage += mc.counter.memoryUsage()
+	}
+
+	for _, source := range q.sources {
+		for _, mc := range source.metricCounters() {
+			usage += mc.counter.memoryUsage()
+		}
+	}
+
+	for _, r := range q.recorders {
+		usage += r.memoryUsage()
+	}
+
+	for _, s := range q.pending {
+		usage += int64(len(s.Points)) * pointBytes
+	}
+
+	return usage
+}
+
+// memoryUsage returns the approximate number of bytes held by the Counter for
+// intervals yet to be reported, and for its buffer, if any.
+func (c *Counter) memoryUsage() int64 {
+
+	var intervals int64
+
+	c.counts.Range(func(key, value any) bool {
+		intervals++
+		return true
+	})
+
+	if c.stripes != nil {
+		for _, stripe := range c.stripes.stripes {
+			stripe.Range(func(key, value any) bool {
+				intervals++
+				return true
+			})
+		}
+	}
+
+	usage := intervals * intervalBytes
+
+	if c.buffer != nil {
+		usage += int64(cap(c.buffer)) * int64(unsafe.Sizeof(countEvent{}))
+	}
+
+	return usage
+}
+
+// memoryUsage implements recorder, returning the approximate number of bytes held
+// by the Distribution for intervals yet to be reported.
+func (d *Distribution) memoryUsage() int64 {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	size := intervalBytes + int64(unsafe.Sizeof(DistributionValue{})) + int64(len(d.bounds)+1)*8
+
+	return int64(len(d.values)) * size
+}
+
+// memoryUsage implements recorder, returning the approximate number of bytes held
+// by the FloatCounter for intervals yet to be reported.
+func (fc *FloatCounter) memoryUsage() int64 {
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return int64(len(fc.totals)) * intervalBytes
+}
+
+// enforceMemoryLimit drops the oldest series pending export, and then the oldest
+// completed intervals of each counter and recorder in turn, until the Quantifier's
+// memory usage is within the limit set with OptionWithMemoryLimit. Dropped points
+// are recorded as part of a Gap, which is passed to the gap handler with the next
+// successful export.
+func (q *Quantifier) enforceMemoryLimit(now time.Time) {
+
+	if q.memoryLimit <= 0 {
+		return
+	}
+
+	usage := q.memoryUsage()
+	if usage <= q.memoryLimit {
+		return
+	}
+
+	// pending series are older than any interval still held by a counter
+	for len(q.pending) > 0 && usage > q.memoryLimit {
+
+		usage -= int64(len(q.pending[0].Points)) * pointBytes
+
+		q.outage.recordDropped(now, q.pending[:1])
+		q.pending = q.pending[1:]
+	}
+
+	counters := q.registeredCounters()
+	for _, source := range q.sources {
+		counters = append(counters[:len(counters):len(counters)], source.metricCounters()...)
+	}
+
+	// drop a single interval from each in turn, so that no series loses all of its
+	// backlog whilst others keep theirs
+	for usage > q.memoryLimit {
+
+		dropped := false
+
+		for _, mc := range counters {
+
+			points := mc.counter.takePoints(false, 1)
+			if len(points) == 0 {
+				continue
+			}
+
+			dropped = true
+			usage -= intervalBytes
+
+			q.outage.recordDropped(now, []*Series{{Metric: mc.metric, Points: points}})
+		}
+
+		for _, r := range q.recorders {
+
+			before := r.memoryUsage()
+
+			points := r.takePoints(false, 1)
+			if len(points) == 0 {
+				continue
+			}
+
+			dropped = true
+			usage -= before - r.memoryUsage()
+
+			q.outage.recordDropped(now, []*Series{{Metric: r.recordedMetric(), Points: points}})
+		}
+
+		// only the intervals in progress remain
+		if !dropped {
+			return
+		}
+	}
+}
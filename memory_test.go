@@ -0,0 +1,88 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_memoryUsage(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     &mockExporter{},
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	counter, _ := client.CreateCounter("planes", nil, 10)
+	total, _ := client.CreateFloatCounter("revenue", nil, 10)
+
+	assert.Equal(t, int64(0), client.Stats().MemoryBytes)
+
+	// an interval of each
+	counter.Count()
+	_ = total.Add(1.5)
+
+	assert.Equal(t, int64(intervalBytes*2), client.Stats().MemoryBytes)
+
+	// pending series
+	client.pending = []*Series{{Metric: &Metric{Name: "planes"}, Points: []*Point{{}, {}}}}
+
+	assert.Equal(t, intervalBytes*2+pointBytes*2, client.Stats().MemoryBytes)
+}
+
+func TestQuantifier_enforceMemoryLimit(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+	gaps := make([]*Gap, 0)
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+		gapHandler: func(q *Quantifier, gap *Gap) {
+			gaps = append(gaps, gap)
+		},
+		maxPoints:   1,
+		memoryLimit: intervalBytes,
+	}
+
+	counter, _ := client.CreateCounter("planes", nil, 10)
+
+	// a backlog of 4 completed intervals
+	for i := 0; i < 4; i++ {
+		counter.Count()
+		mockClock.Add(time.Second * 10)
+	}
+
+	// 1 is reported, and the oldest 2 of the remaining 3 dropped
+	client.report(false)
+
+	assert.Len(t, exporter.series, 1)
+	assert.Equal(t, int64(intervalBytes), client.Stats().MemoryBytes)
+	assert.Len(t, gaps, 0)
+
+	// the newest is reported with the next refresh, ending the gap
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	assert.Len(t, exporter.series, 2)
+	assert.Equal(t, time.Unix(1670681800, 0), exporter.series[1].Points[0].Start)
+	assert.Equal(t, []*Gap{
+		{
+			Start:          time.Unix(1670681810, 0),
+			End:            time.Unix(1670681820, 0),
+			SeriesAffected: 1,
+			PointsDropped:  2,
+		},
+	}, gaps)
+}
@@ -0,0 +1,276 @@
+package quantify
+
+import (
+	"strings"
+	"time"
+)
+
+// LaunchStage describes the maturity of a metric, allowing exporters that
+// support it to distinguish experimental metrics from stable ones.
+type LaunchStage string
+
+const (
+	LaunchStageUnspecified LaunchStage = ""
+	LaunchStagePrelaunch   LaunchStage = "PRELAUNCH"
+	LaunchStageEarlyAccess LaunchStage = "EARLY_ACCESS"
+	LaunchStageAlpha       LaunchStage = "ALPHA"
+	LaunchStageBeta        LaunchStage = "BETA"
+	LaunchStageGA          LaunchStage = "GA"
+	LaunchStageDeprecated  LaunchStage = "DEPRECATED"
+)
+
+// MetricKind describes how the points of a metric relate to each other in time.
+type MetricKind int
+
+const (
+
+	// MetricKindCumulative points accumulate over time, with each point covering
+	// its own interval (or, with OptionWithCumulativeTotals, the time since the
+	// start of the series).
+	MetricKindCumulative MetricKind = iota
+
+	// MetricKindGauge points measure a value at a specific instant in time.
+	MetricKindGauge
+
+	// MetricKindDelta points each cover their own interval, and are never reported
+	// as running totals (see MetricOptionWithDeltaKind).
+	MetricKindDelta
+)
+
+// String returns the name of the MetricKind, e.g. "CUMULATIVE".
+func (mk MetricKind) String() string {
+
+	switch mk {
+	case MetricKindCumulative:
+		return "CUMULATIVE"
+	case MetricKindGauge:
+		return "GAUGE"
+	case MetricKindDelta:
+		return "DELTA"
+	}
+
+	return "UNKNOWN"
+}
+
+// ValueType describes the type of the values held by a metric's points.
+type ValueType int
+
+const (
+
+	// ValueTypeInt64 points hold their value in Point.Count.
+	ValueTypeInt64 ValueType = iota
+
+	// ValueTypeDouble points hold their value in Point.Value.
+	ValueTypeDouble
+
+	// ValueTypeDistribution points hold their value in Point.Distribution.
+	ValueTypeDistribution
+)
+
+// String returns the name of the ValueType, e.g. "INT64".
+func (vt ValueType) String() string {
+
+	switch vt {
+	case ValueTypeInt64:
+		return "INT64"
+	case ValueTypeDouble:
+		return "DOUBLE"
+	case ValueTypeDistribution:
+		return "DISTRIBUTION"
+	}
+
+	return "UNKNOWN"
+}
+
+// Metric identifies a single time series by its name and labels, along with
+// any optional metadata describing it.
+type Metric struct {
+
+	// Name is the name of the metric as provided when it was created.
+	Name string
+
+	// Labels are the label key/value pairs that identify this series.
+	Labels map[string]string
+
+	// Kind is the kind of the metric, defaulting to MetricKindCumulative.
+	Kind MetricKind
+
+	// ValueType is the type of the metric's values, defaulting to ValueTypeInt64.
+	ValueType ValueType
+
+	// DisplayName is an optional, human-readable, name for the metric.
+	DisplayName string
+
+	// Description is an optional description of what the metric measures.
+	Description string
+
+	// LaunchStage is the optional maturity of the metric.
+	LaunchStage LaunchStage
+
+	// Unit is the optional unit of the metric's values, following the Unified Code
+	// for Units of Measure (e.g. "s", "By", or "{USD}").
+	Unit string
+
+	// Group is the name of the CounterGroup the metric belongs to, if any. Series of
+	// the same group should be exported together.
+	Group string
+
+	// bufferSize, when set, configures the Counter of the metric to count through
+	// a buffered channel (see MetricOptionWithBufferedCounting).
+	bufferSize int
+
+	// striped, when set, configures the Counter of the metric to count into
+	// per-processor stripes (see MetricOptionWithStripedCounting).
+	striped bool
+
+	// normalisers are applied to the values of the labels they're keyed by (see
+	// MetricOptionWithLabelNormaliser).
+	normalisers map[string]func(string) string
+
+	// idleTTL, when set, is how long the series of a CounterVec may be idle before
+	// they're removed (see MetricOptionWithIdleTTL).
+	idleTTL time.Duration
+
+	// shutdownValue, when set, replaces the values of the gauge's points published
+	// by the final report (see MetricOptionWithShutdownValue).
+	shutdownValue *float64
+}
+
+// MetricOption defines a function for supplying optional metadata to a Metric
+// when it is created.
+type MetricOption func(*Metric)
+
+// MetricOptionWithDisplayName sets a human-readable name for the metric.
+func MetricOptionWithDisplayName(displayName string) MetricOption {
+	return func(metric *Metric) {
+		metric.DisplayName = displayName
+	}
+}
+
+// MetricOptionWithDescription sets a description of what the metric measures.
+func MetricOptionWithDescription(description string) MetricOption {
+	return func(metric *Metric) {
+		metric.Description = description
+	}
+}
+
+// MetricOptionWithLaunchStage sets the maturity of the metric, for example to
+// mark it as experimental (LaunchStageAlpha) or stable (LaunchStageGA).
+func MetricOptionWithLaunchStage(stage LaunchStage) MetricOption {
+	return func(metric *Metric) {
+		metric.LaunchStage = stage
+	}
+}
+
+// MetricOptionWithUnit sets the unit of the metric's values, which dashboards use
+// to format them. The unit should follow the Unified Code for Units of Measure,
+// for example "s" for seconds or "By" for bytes.
+func MetricOptionWithUnit(unit string) MetricOption {
+	return func(metric *Metric) {
+		metric.Unit = unit
+	}
+}
+
+// MetricOptionWithCurrency sets the unit of the metric's values to the provided
+// ISO 4217 currency code (e.g. "usd" becomes "{USD}"), for metrics such as revenue
+// or cost. Values should be recorded in the major unit of the currency, so
+// fractional amounts require a FloatCounter (see CreateFloatCounter).
+func MetricOptionWithCurrency(code string) MetricOption {
+	return func(metric *Metric) {
+		metric.Unit = "{" + strings.ToUpper(code) + "}"
+	}
+}
+
+// MetricOptionWithDeltaKind reports the points of a counter with MetricKindDelta,
+// each covering only its own interval, which matches how a Counter buckets its
+// counts. Delta counters are never converted to running totals, even with
+// OptionWithCumulativeTotals, so backends don't need to detect resets when a
+// process restarts. Not every backend accepts delta series for every metric (e.g.
+// the gcms exporter rejects them, as Google Cloud Monitoring doesn't accept them
+// for custom metrics).
+//
+// The option has no effect on instruments that aren't counters, such as gauges.
+func MetricOptionWithDeltaKind() MetricOption {
+	return func(metric *Metric) {
+		if metric.Kind == MetricKindCumulative {
+			metric.Kind = MetricKindDelta
+		}
+	}
+}
+
+// MetricOptionWithLabelNormaliser sets a function that normalises the values of the
+// provided label key of the metric, such as strings.ToLower, or one stripping the
+// query strings of URLs, so that hygiene rules are defined once, rather than at
+// each call site. The function is applied to the label's value when the metric is
+// created, and to values provided whilst recording, such as those given to
+// CounterVec.With or TopK.Count, so values that normalise to the same value are
+// counted towards the same series.
+//
+// As values may be normalised more than once, the function should return values
+// that are already normalised unchanged.
+func MetricOptionWithLabelNormaliser(key string, fn func(value string) string) MetricOption {
+	return func(metric *Metric) {
+
+		if metric.normalisers == nil {
+			metric.normalisers = make(map[string]func(string) string)
+		}
+		metric.normalisers[key] = fn
+
+		value, ok := metric.Labels[key]
+		if !ok {
+			return
+		}
+
+		// the labels may belong to the caller, so are copied rather than modified
+		labels := make(map[string]string, len(metric.Labels))
+		for k, v := range metric.Labels {
+			labels[k] = v
+		}
+		labels[key] = fn(value)
+
+		metric.Labels = labels
+	}
+}
+
+// MetricOptionWithBufferedCounting configures the Counter of the metric to count
+// through a buffered channel of the provided size, with a single goroutine
+// aggregating the counts, rather than with atomic operations on shared state.
+// This can perform better for bursty producers counting from many goroutines at
+// once, which contend on the same interval, so should be chosen by benchmarking.
+//
+// Calls to Count block once the buffer is full, until the aggregator catches up.
+// Counts are always aggregated before each report, so none are reported late. The
+// goroutine exits once the Quantifier is stopped, or the Counter is deleted, after
+// its counts have been reported.
+func MetricOptionWithBufferedCounting(size int) MetricOption {
+	return func(metric *Metric) {
+		metric.bufferSize = size
+	}
+}
+
+// MetricOptionWithStripedCounting configures the Counter of the metric to spread
+// its counts across a stripe per processor (GOMAXPROCS when created), which are
+// combined when the counter is reported. This avoids contention between
+// goroutines counting on different processors, for extreme counting rates (e.g.
+// over 10 million per second), at the cost of memory per stripe.
+func MetricOptionWithStripedCounting() MetricOption {
+	return func(metric *Metric) {
+		metric.striped = true
+	}
+}
+
+// normalise returns the provided value of the provided label key, normalised by the
+// metric's normaliser for the key, if it has one.
+func (m *Metric) normalise(key string, value string) string {
+
+	if fn, ok := m.normalisers[key]; ok {
+		return fn(value)
+	}
+
+	return value
+}
+
+// HasMetadata returns whether any optional metadata has been set on the Metric.
+func (m *Metric) HasMetadata() bool {
+	return m.DisplayName != "" || m.Description != "" || m.LaunchStage != LaunchStageUnspecified || m.Unit != ""
+}
@@ -0,0 +1,86 @@
+package quantify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricOptionWithCurrency(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		code         string
+		expectedUnit string
+	}{
+		{
+			name:         "upper case code",
+			code:         "USD",
+			expectedUnit: "{USD}",
+		},
+		{
+			name:         "lower case code",
+			code:         "gbp",
+			expectedUnit: "{GBP}",
+		},
+	}
+
+	for _, test := range tests {
+
+		metric := &Metric{}
+		MetricOptionWithCurrency(test.code)(metric)
+
+		assert.Equalf(t, test.expectedUnit, metric.Unit, "%s failed", test.name)
+		assert.Truef(t, metric.HasMetadata(), "%s failed", test.name)
+	}
+}
+
+func TestMetricOptionWithDeltaKind(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		kind         MetricKind
+		expectedKind MetricKind
+	}{
+		{
+			name:         "counter",
+			kind:         MetricKindCumulative,
+			expectedKind: MetricKindDelta,
+		},
+		{
+			name:         "gauge",
+			kind:         MetricKindGauge,
+			expectedKind: MetricKindGauge,
+		},
+	}
+
+	for _, test := range tests {
+
+		metric := &Metric{Kind: test.kind}
+		MetricOptionWithDeltaKind()(metric)
+
+		assert.Equalf(t, test.expectedKind, metric.Kind, "%s failed", test.name)
+	}
+
+	assert.Equal(t, "DELTA", MetricKindDelta.String())
+}
+
+func TestMetricOptionWithLabelNormaliser(t *testing.T) {
+
+	labels := map[string]string{
+		"method": "GET",
+		"path":   "/planes",
+	}
+
+	metric := &Metric{Labels: labels}
+	MetricOptionWithLabelNormaliser("method", strings.ToLower)(metric)
+	MetricOptionWithLabelNormaliser("region", strings.ToLower)(metric)
+
+	assert.Equal(t, map[string]string{"method": "get", "path": "/planes"}, metric.Labels)
+	assert.Equal(t, "eu", metric.normalise("region", "EU"))
+	assert.Equal(t, "/PLANES", metric.normalise("path", "/PLANES"))
+
+	// the caller's labels are left as they were
+	assert.Equal(t, "GET", labels["method"])
+}
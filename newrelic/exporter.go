@@ -0,0 +1,209 @@
+// Package newrelic provides a quantify.Exporter that reports metrics to New
+// Relic using the Metric API.
+//
+// see: https://docs.newrelic.com/docs/data-apis/ingest-apis/metric-api/report-metrics-metric-api/
+package newrelic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+)
+
+const (
+	// EndpointUS is the Metric API endpoint for accounts in the US data center.
+	EndpointUS = "https://metric-api.newrelic.com/metric/v1"
+
+	// EndpointEU is the Metric API endpoint for accounts in the EU data center.
+	EndpointEU = "https://metric-api.eu.newrelic.com/metric/v1"
+
+	// defaultTimeout bounds each request made by the default http.Client, so that
+	// an unresponsive Metric API can't block reporting indefinitely.
+	defaultTimeout = time.Second * 10
+)
+
+var (
+	ErrNoLicenseKey = errors.New("no license key provided")
+)
+
+// Exporter implements quantify.Exporter, reporting metrics to New Relic.
+type Exporter struct {
+	endpoint   string
+	licenseKey string
+	client     *http.Client
+	attributes map[string]interface{}
+}
+
+// New returns an instantiated Exporter that authenticates with the provided
+// license (ingest) key, or returns an error if instantiation fails.
+//
+// options allow the user to provide custom configurations as a list of Options.
+// If any options are invalid, a quantify.ValidationErrors describing every
+// problem is returned.
+func New(licenseKey string, options ...Option) (*Exporter, error) {
+
+	if licenseKey == "" {
+		return nil, ErrNoLicenseKey
+	}
+
+	exporter := &Exporter{
+		endpoint:   EndpointUS,
+		licenseKey: licenseKey,
+		client:     &http.Client{Timeout: defaultTimeout},
+	}
+
+	// apply every option, so that all problems are reported together
+	var errs quantify.ValidationErrors
+
+	for _, option := range options {
+		err := option(exporter)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return exporter, nil
+}
+
+// Export implements quantify.Exporter, submitting the points of the provided
+// series as a single Metric API payload. Points of quantify.MetricKindCumulative
+// are submitted as count metrics covering their interval (so running totals aren't
+// supported, see SumsCounts), and points of quantify.MetricKindGauge as gauge
+// metrics.
+func (e *Exporter) Export(ctx context.Context, series []*quantify.Series) error {
+
+	payload := e.createPayload(series)
+	if len(payload[0].Metrics) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Api-Key", e.licenseKey)
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusAccepted {
+		message, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("failed to submit metrics: %s: %s", response.Status, message)
+	}
+
+	return nil
+}
+
+// SumsCounts implements quantify.CountSummer, as New Relic sums the values of count
+// metrics, so running totals (see quantify.OptionWithCumulativeTotals) would be
+// counted again with every report.
+func (e *Exporter) SumsCounts() bool {
+	return true
+}
+
+// createPayload compiles the provided series into a Metric API payload.
+func (e *Exporter) createPayload(series []*quantify.Series) []*metricBatch {
+
+	batch := &metricBatch{
+		Metrics: make([]*metricData, 0),
+	}
+
+	if len(e.attributes) > 0 {
+		batch.Common = &commonData{
+			Attributes: e.attributes,
+		}
+	}
+
+	for _, s := range series {
+		for _, point := range s.Points {
+			batch.Metrics = append(batch.Metrics, pointToMetricData(s.Metric, point))
+		}
+	}
+
+	return []*metricBatch{batch}
+}
+
+// pointToMetricData converts a single point of the provided metric to its Metric
+// API representation.
+func pointToMetricData(metric *quantify.Metric, point *quantify.Point) *metricData {
+
+	var value interface{} = point.Count
+	if metric.ValueType == quantify.ValueTypeDouble {
+		value = point.Value
+	}
+
+	data := &metricData{
+		Name:      metric.Name,
+		Type:      "count",
+		Value:     value,
+		Timestamp: point.Start.UnixMilli(),
+		Interval:  point.End.Sub(point.Start).Milliseconds(),
+	}
+
+	if metric.Kind == quantify.MetricKindGauge {
+		data.Type = "gauge"
+		data.Timestamp = point.End.UnixMilli()
+		data.Interval = 0
+	}
+
+	// distributions are summarised by their count, sum, minimum and maximum
+	if metric.ValueType == quantify.ValueTypeDistribution && point.Distribution != nil {
+
+		dv := point.Distribution
+
+		data.Type = "summary"
+		data.Value = map[string]interface{}{
+			"count": dv.Count,
+			"sum":   dv.Mean * float64(dv.Count),
+			"min":   dv.Min,
+			"max":   dv.Max,
+		}
+	}
+
+	if len(metric.Labels) > 0 {
+		data.Attributes = make(map[string]interface{}, len(metric.Labels))
+		for key, value := range metric.Labels {
+			data.Attributes[key] = value
+		}
+	}
+
+	return data
+}
+
+type metricBatch struct {
+	Common  *commonData   `json:"common,omitempty"`
+	Metrics []*metricData `json:"metrics"`
+}
+
+type commonData struct {
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+type metricData struct {
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"`
+	Value      interface{}            `json:"value"`
+	Timestamp  int64                  `json:"timestamp"`
+	Interval   int64                  `json:"interval.ms,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
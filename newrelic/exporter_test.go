@@ -0,0 +1,118 @@
+package newrelic
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestExporter_Export(t *testing.T) {
+
+	var header http.Header
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	exporter, err := New(
+		"license",
+		OptionWithEndpoint(server.URL),
+		OptionWithCommonAttributes(map[string]interface{}{"service.name": "checkout"}),
+	)
+	assert.NoError(t, err)
+
+	series := []*quantify.Series{
+		{
+			Metric: &quantify.Metric{
+				Name:   "planes",
+				Labels: map[string]string{"manufacturer": "boeing"},
+			},
+			Points: []*quantify.Point{
+				{Start: time.Unix(1672693340, 0), End: time.Unix(1672693350, 0), Count: 3},
+			},
+		},
+		{
+			Metric: &quantify.Metric{
+				Name:      "burn_rate",
+				Kind:      quantify.MetricKindGauge,
+				ValueType: quantify.ValueTypeDouble,
+			},
+			Points: []*quantify.Point{
+				{Start: time.Unix(1672693350, 0), End: time.Unix(1672693350, 0), Value: 1.5},
+			},
+		},
+	}
+
+	assert.NoError(t, exporter.Export(context.Background(), series))
+
+	assert.Equal(t, "license", header.Get("Api-Key"))
+	assert.JSONEq(t, `[
+		{
+			"common": {"attributes": {"service.name": "checkout"}},
+			"metrics": [
+				{
+					"name": "planes",
+					"type": "count",
+					"value": 3,
+					"timestamp": 1672693340000,
+					"interval.ms": 10000,
+					"attributes": {"manufacturer": "boeing"}
+				},
+				{
+					"name": "burn_rate",
+					"type": "gauge",
+					"value": 1.5,
+					"timestamp": 1672693350000
+				}
+			]
+		}
+	]`, string(body))
+}
+
+func TestExporter_Export_error(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	exporter, err := New("license", OptionWithEndpoint(server.URL))
+	assert.NoError(t, err)
+
+	series := []*quantify.Series{
+		{
+			Metric: &quantify.Metric{Name: "planes"},
+			Points: []*quantify.Point{
+				{Start: time.Unix(1672693340, 0), End: time.Unix(1672693350, 0), Count: 3},
+			},
+		},
+	}
+
+	assert.EqualError(t, exporter.Export(context.Background(), series), "failed to submit metrics: 403 Forbidden: ")
+}
+
+func TestNew(t *testing.T) {
+
+	_, err := New("")
+	assert.Equal(t, ErrNoLicenseKey, err)
+
+	// the default client is bounded, rather than waiting indefinitely
+	exporter, err := New("key")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTimeout, exporter.client.Timeout)
+
+	// counts are summed, so running totals are rejected
+	_, err = quantify.New(context.Background(), quantify.OptionWithExporter(exporter), quantify.OptionWithCumulativeTotals())
+	assert.ErrorIs(t, err, quantify.ErrCumulativeTotalsUnsupported)
+}
@@ -0,0 +1,23 @@
+package newrelic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rustedturnip/quantify/internal/golden"
+)
+
+func TestExporter_golden(t *testing.T) {
+
+	exporter, err := New("license")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := json.Marshal(exporter.createPayload(golden.Series()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden.Assert(t, "payload", payload)
+}
@@ -0,0 +1,52 @@
+package newrelic
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Option defines a function for supplying the Exporter constructor with certain
+// configurations.
+type Option func(*Exporter) error
+
+// OptionWithEndpoint sets the Metric API endpoint, which defaults to EndpointUS.
+// Accounts in the EU data center should use EndpointEU.
+func OptionWithEndpoint(endpoint string) Option {
+	return func(exporter *Exporter) error {
+		if endpoint == "" {
+			return &quantify.FieldError{Path: "endpoint", Err: errors.New("can't be empty")}
+		}
+		exporter.endpoint = endpoint
+		return nil
+	}
+}
+
+// OptionWithHTTPClient allows a manually configured http.Client to be used to
+// call the Metric API, instead of the default client, which times out requests
+// after 10 seconds. The provided client should also bound its requests (e.g. with
+// a Timeout), as a request that never completes blocks reporting.
+func OptionWithHTTPClient(client *http.Client) Option {
+	return func(exporter *Exporter) error {
+		exporter.client = client
+		return nil
+	}
+}
+
+// OptionWithCommonAttributes sets attributes (e.g. "service.name") that are
+// applied to every metric submitted by the Exporter.
+func OptionWithCommonAttributes(attributes map[string]interface{}) Option {
+	return func(exporter *Exporter) error {
+
+		if exporter.attributes == nil {
+			exporter.attributes = make(map[string]interface{}, len(attributes))
+		}
+
+		for key, value := range attributes {
+			exporter.attributes[key] = value
+		}
+
+		return nil
+	}
+}
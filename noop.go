@@ -0,0 +1,121 @@
+package quantify
+
+import (
+	"sync"
+)
+
+// noopInstead returns whether a no-op instrument should be returned in place of the
+// provided error from creating an instrument (see OptionWithBestEffortInstruments),
+// passing the error to the error handler the first time it occurs.
+func (q *Quantifier) noopInstead(err error) bool {
+
+	if !q.bestEffort {
+		return false
+	}
+
+	q.mu.Lock()
+	reported := q.reported[err.Error()]
+	if !reported {
+		if q.reported == nil {
+			q.reported = make(map[string]bool)
+		}
+		q.reported[err.Error()] = true
+	}
+	q.mu.Unlock()
+
+	if !reported {
+		q.errorHandler(q, err)
+	}
+
+	return true
+}
+
+// newNoopCounter returns a Counter that discards its counts.
+func newNoopCounter() *Counter {
+	return &Counter{
+		interval: 1,
+		counts:   &sync.Map{},
+		mu:       &sync.Mutex{},
+		clock:    systemClock{},
+		noop:     true,
+	}
+}
+
+// newNoopGauge returns a Gauge that is never published.
+func newNoopGauge() *Gauge {
+	return &Gauge{
+		mu:     &sync.Mutex{},
+		metric: &Metric{},
+	}
+}
+
+// newNoopUpDownCounter returns an UpDownCounter that is never published.
+func newNoopUpDownCounter() *UpDownCounter {
+	return &UpDownCounter{
+		metric: &Metric{},
+	}
+}
+
+// newNoopObservableGauge returns an ObservableGauge that is never observed.
+func newNoopObservableGauge() *ObservableGauge {
+	return &ObservableGauge{
+		metric: &Metric{},
+		fn:     func() float64 { return 0 },
+	}
+}
+
+// newNoopTopK returns a TopK that discards its counts.
+func newNoopTopK() *TopK {
+	return &TopK{
+		mu:     &sync.Mutex{},
+		metric: &Metric{},
+		noop:   true,
+	}
+}
+
+// newNoopUniqueCounter returns a UniqueCounter that is never published.
+func newNoopUniqueCounter() *UniqueCounter {
+	return &UniqueCounter{
+		mu:     &sync.Mutex{},
+		metric: &Metric{},
+		sketch: &hyperLogLog{},
+	}
+}
+
+// newNoopOutcomeCounter returns an OutcomeCounter that discards its outcomes.
+func newNoopOutcomeCounter() *OutcomeCounter {
+	return &OutcomeCounter{
+		success: &metricCounter{
+			metric:  &Metric{},
+			counter: newNoopCounter(),
+		},
+		mu:       &sync.Mutex{},
+		failures: make(map[string]*metricCounter),
+		noop:     true,
+	}
+}
+
+// newNoopCounterVec returns a CounterVec whose Counters discard their counts.
+func newNoopCounterVec() *CounterVec {
+	return &CounterVec{
+		mu:       &sync.Mutex{},
+		counters: make(map[string]*metricCounter),
+		noop:     true,
+	}
+}
+
+// newNoopBurnRate returns a BurnRate that is never published.
+func newNoopBurnRate() *BurnRate {
+	return &BurnRate{
+		mu:     &sync.Mutex{},
+		metric: &Metric{},
+	}
+}
+
+// newNoopRate returns a Rate that is never published.
+func newNoopRate() *Rate {
+	return &Rate{
+		mu:     &sync.Mutex{},
+		metric: &Metric{},
+	}
+}
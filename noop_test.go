@@ -0,0 +1,107 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionWithBestEffortInstruments(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+	errs := make([]error, 0)
+
+	q, err := New(
+		context.Background(),
+		OptionWithClock(mockClock),
+		OptionWithExporter(exporter),
+		OptionWithErrorHandler(func(q *Quantifier, err error) {
+			errs = append(errs, err)
+		}),
+		OptionWithNamePolicy(requirePrefixPolicy),
+		OptionWithBestEffortInstruments(),
+		OptionSynchronous(),
+	)
+	assert.NoError(t, err)
+
+	// invalid instruments are no-ops, with each error reported once
+	for i := 0; i < 2; i++ {
+
+		counter, err := q.CreateCounter("planes", nil, 10)
+		assert.NoError(t, err)
+		counter.Count()
+		assert.NoError(t, counter.AddAt(mockClock.Now(), 2))
+	}
+
+	gauge, err := q.CreateGauge("planes", nil, GaugeAggregationLast)
+	assert.NoError(t, err)
+	gauge.Set(1)
+
+	topK, err := q.CreateTopK("planes", nil, "model", 3)
+	assert.NoError(t, err)
+	topK.Count("737-800")
+
+	unique, err := q.CreateUniqueCounter("planes", nil)
+	assert.NoError(t, err)
+	unique.Observe("737-800")
+
+	inFlight, err := q.CreateUpDownCounter("planes", nil)
+	assert.NoError(t, err)
+	inFlight.Inc()
+
+	_, err = q.CreateObservableGauge("planes", nil, func() float64 { return 1 })
+	assert.NoError(t, err)
+
+	rate, err := q.CreateRate("planes", nil)
+	assert.NoError(t, err)
+	rate.Mark()
+
+	outcomes, err := q.CreateOutcomeCounter("planes", nil, 10, true)
+	assert.NoError(t, err)
+	outcomes.Success()
+	outcomes.Failure("timeout")
+
+	_, err = q.CreateChildCounter(&Counter{}, "team/planes", nil, 10)
+	assert.NoError(t, err)
+
+	// valid instruments are unaffected
+	boats, err := q.CreateCounter("team/boats", nil, 10)
+	assert.NoError(t, err)
+	boats.Count()
+
+	mockClock.Add(time.Second * 10)
+	assert.NoError(t, q.Flush())
+
+	if assert.Len(t, exporter.series, 1) {
+		assert.Equal(t, "team/boats", exporter.series[0].Metric.Name)
+	}
+
+	assert.Equal(t, []error{
+		errors.New("name must be prefixed with team/"),
+		ErrUnknownCounter,
+	}, unwrapAll(errs))
+
+	q.Stop()
+}
+
+// unwrapAll returns the innermost error of each of the provided errors.
+func unwrapAll(errs []error) []error {
+
+	unwrapped := make([]error, 0, len(errs))
+	for _, err := range errs {
+		for errors.Unwrap(err) != nil {
+			err = errors.Unwrap(err)
+		}
+		unwrapped = append(unwrapped, err)
+	}
+
+	return unwrapped
+}
@@ -0,0 +1,73 @@
+package quantify
+
+import "time"
+
+// ObservableGauge is a gauge whose value is observed by a callback with each
+// refresh, rather than set whenever it changes (see CreateObservableGauge).
+type ObservableGauge struct {
+	metric *Metric
+	fn     func() float64
+}
+
+// CreateObservableGauge creates a gauge whose value is observed with fn on each
+// refresh, for values that are cheap to read but change too often to set, such as a
+// cache size or the utilisation of a pool. fn is called by the reporting goroutine,
+// so must be safe to call concurrently with the rest of the application.
+//
+// options allow optional metadata, such as a display name, to be provided as a
+// list of MetricOptions.
+func (q *Quantifier) CreateObservableGauge(name string, labels map[string]string, fn func() float64, options ...MetricOption) (*ObservableGauge, error) {
+
+	instrument, err := q.createObservableGauge(name, labels, fn, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopObservableGauge(), nil
+	}
+
+	return instrument, err
+}
+
+// createObservableGauge creates an ObservableGauge (see CreateObservableGauge).
+func (q *Quantifier) createObservableGauge(name string, labels map[string]string, fn func() float64, options ...MetricOption) (*ObservableGauge, error) {
+
+	metric := &Metric{
+		Name:      name,
+		Labels:    q.mergeCommonLabels(labels),
+		Kind:      MetricKindGauge,
+		ValueType: ValueTypeDouble,
+	}
+
+	for _, option := range options {
+		option(metric)
+	}
+
+	err := q.validateMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	og := &ObservableGauge{
+		metric: metric,
+		fn:     fn,
+	}
+
+	q.collectors = append(q.collectors, og)
+
+	return og, nil
+}
+
+// collect implements collector, publishing the value observed at the provided
+// time.
+func (og *ObservableGauge) collect(now time.Time) []*Series {
+	return []*Series{
+		{
+			Metric: og.metric,
+			Points: []*Point{
+				{
+					Start: now,
+					End:   now,
+					Value: og.fn(),
+				},
+			},
+		},
+	}
+}
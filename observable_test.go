@@ -0,0 +1,50 @@
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObservableGauge_report(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	observations := 0
+
+	gauge, err := client.CreateObservableGauge("cache_size", nil, func() float64 {
+		observations++
+		return float64(observations * 10)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, MetricKindGauge, gauge.metric.Kind)
+
+	// observed once per refresh
+	assert.Equal(t, 0, observations)
+
+	client.report(false)
+	mockClock.Add(time.Second * 10)
+	client.report(false)
+
+	assert.Equal(t, 2, observations)
+	assert.Equal(t, []*Series{
+		{
+			Metric: gauge.metric,
+			Points: []*Point{{Start: time.Unix(1670681770, 0), End: time.Unix(1670681770, 0), Value: 10}},
+		},
+		{
+			Metric: gauge.metric,
+			Points: []*Point{{Start: time.Unix(1670681780, 0), End: time.Unix(1670681780, 0), Value: 20}},
+		},
+	}, exporter.series)
+}
@@ -1,44 +1,23 @@
 package quantify
 
 import (
-	"fmt"
+	"errors"
 	"time"
-
-	monitoring "cloud.google.com/go/monitoring/apiv3"
 )
 
 // Option defines a function for supplying the Quantifier constructor with certain
 // configurations.
 type Option func(*Quantifier) error
 
-// OptionWithCloudMetricsClient allows a cloud_metrics Client, which has been
-// manually configured, to be supplied to the client instead of using the default
-// configuration.
-func OptionWithCloudMetricsClient(client *monitoring.MetricClient) Option {
-	return func(quantifier *Quantifier) error {
-		quantifier.client = client
-		return nil
-	}
-}
-
-// OptionWithResourceType allows a Resource other than the default to be provided
-// which will govern how the metric is filed in Google Cloud Monitoring.
-func OptionWithResourceType(resource Resource) Option {
+// OptionWithExporter sets the Exporter that recorded metrics are pushed to.
+func OptionWithExporter(exporter Exporter) Option {
 	return func(quantifier *Quantifier) error {
 
-		resourceLabels, err := flatten(resource)
-		if err != nil {
+		if err := quantifier.configure("exporter"); err != nil {
 			return err
 		}
 
-		value, ok := resourceLabels[resourceLabelKeyProjectId]
-		if !ok || value == "" {
-			return fmt.Errorf("missing required %s resource label", resourceLabelKeyProjectId)
-		}
-
-		quantifier.resourceLabels = resourceLabels
-		quantifier.resourceName = resource.GetName()
-
+		quantifier.exporter = exporter
 		return nil
 	}
 }
@@ -48,6 +27,11 @@ func OptionWithResourceType(resource Resource) Option {
 // program should be terminated in the event of an error.
 func OptionWithErrorHandler(fn func(*Quantifier, error)) Option {
 	return func(quantifier *Quantifier) error {
+
+		if err := quantifier.configure("error_handler"); err != nil {
+			return err
+		}
+
 		quantifier.errorHandler = fn
 		return nil
 	}
@@ -57,7 +41,366 @@ func OptionWithErrorHandler(fn func(*Quantifier, error)) Option {
 // be pushed to Google Cloud. This does not affect how counts are aggregated.
 func OptionWithRefreshInterval(interval time.Duration) Option {
 	return func(q *Quantifier) error {
+
+		if err := q.configure("refresh_interval"); err != nil {
+			return err
+		}
+
 		q.refreshInterval = interval
 		return nil
 	}
 }
+
+// OptionWithClock allows a Clock other than the system clock to be used as the
+// time source for the Quantifier and any counters it creates.
+func OptionWithClock(clock Clock) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("clock"); err != nil {
+			return err
+		}
+
+		q.clock = clock
+		return nil
+	}
+}
+
+// OptionWithNamePolicy allows a naming policy to be enforced on all metrics created
+// by the Quantifier, for example requiring a team prefix. The policy is called with
+// the name of each metric at creation time, and any error it returns is returned by
+// the create call.
+func OptionWithNamePolicy(policy func(name string) error) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("name_policy"); err != nil {
+			return err
+		}
+
+		q.namePolicy = policy
+		return nil
+	}
+}
+
+// OptionWithMaxPointsPerFlush limits how many completed intervals are reported for
+// each counter per refresh, so that a large backlog (e.g. after a long refresh
+// interval) drains gradually over several refreshes rather than in a single burst
+// of requests. The oldest intervals are always reported first.
+//
+// The limit does not apply to the final flush performed by Quantifier.Stop. A limit
+// of 0 (the default) reports all completed intervals.
+func OptionWithMaxPointsPerFlush(max int) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("max_points_per_flush"); err != nil {
+			return err
+		}
+
+		if max < 0 {
+			return &FieldError{Path: "max_points_per_flush", Err: errors.New("can't be negative")}
+		}
+		q.maxPoints = max
+		return nil
+	}
+}
+
+// OptionWithMemoryLimit caps the approximate memory, in bytes, held for counts,
+// observations and series that are yet to be reported, e.g. whilst exports are
+// failing. When a report leaves more than the limit, the oldest series pending
+// export are dropped first, followed by the oldest completed intervals of each
+// instrument in turn. Dropped points are described by a Gap passed to the gap
+// handler (see OptionWithGapHandler) with the next successful export.
+//
+// The current usage is reported by Quantifier.Stats. A limit of 0 (the default)
+// doesn't limit memory.
+func OptionWithMemoryLimit(bytes int64) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("memory_limit"); err != nil {
+			return err
+		}
+
+		if bytes < 0 {
+			return &FieldError{Path: "memory_limit", Err: errors.New("can't be negative")}
+		}
+		q.memoryLimit = bytes
+		return nil
+	}
+}
+
+// OptionWithGapHandler allows a function to be provided that is called when exports
+// recover after one or more failures, describing the Gap in reported data (e.g. so
+// that it can be logged to explain blank periods on dashboards).
+func OptionWithGapHandler(fn func(*Quantifier, *Gap)) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("gap_handler"); err != nil {
+			return err
+		}
+
+		q.gapHandler = fn
+		return nil
+	}
+}
+
+// OptionWithInstanceLabels adds labels identifying the current process instance to
+// every metric created by the Quantifier, allowing quick per-instance breakdowns
+// (e.g. in development environments). The labels added are "hostname", "pid" and,
+// when running within a container, "container_id".
+//
+// Note: as pid changes on every restart, this option can produce a large number of
+// series over time and isn't recommended for long-lived production workloads.
+func OptionWithInstanceLabels() Option {
+	return func(q *Quantifier) error {
+
+		if q.commonLabels == nil {
+			q.commonLabels = make(map[string]string)
+		}
+
+		for key, value := range detectInstanceLabels() {
+			q.commonLabels[key] = value
+		}
+
+		return nil
+	}
+}
+
+// OptionWithSelfInstrumentation enables measurement of the overhead of the library's
+// own hot path (Counter.Count), exposed via Quantifier.Stats. This adds a small
+// cost to every count, so is intended for debugging and benchmarking, for example
+// to help decide whether a sharded counter is required.
+func OptionWithSelfInstrumentation() Option {
+	return func(q *Quantifier) error {
+		q.countLatency = newLatencyRecorder()
+		return nil
+	}
+}
+
+// OptionWithAdaptiveSampling bounds the overhead of counting at high volumes by
+// sampling calls to Counter.Count. Counters record every call whilst the count of
+// their previous interval is at or below threshold, and progressively sample fewer
+// calls (1 in 2, 1 in 4, ...) as the count rises above it. Sampled calls are
+// weighted by the sample rate, so reported totals remain corrected estimates.
+func OptionWithAdaptiveSampling(threshold int64) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("adaptive_sampling"); err != nil {
+			return err
+		}
+
+		if threshold <= 0 {
+			return &FieldError{Path: "adaptive_sampling.threshold", Err: errors.New("must be greater than 0")}
+		}
+		q.sampleAbove = threshold
+		return nil
+	}
+}
+
+// OptionWithReportingDelay delays the reporting of each completed counter interval
+// by the provided duration. This gives late calls to Count (e.g. from systems with
+// long asynchronous pipelines that capture the time of an event before counting
+// it with CountAt) time to land in the correct interval before it is reported.
+func OptionWithReportingDelay(delay time.Duration) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("reporting_delay"); err != nil {
+			return err
+		}
+
+		if delay < 0 {
+			return &FieldError{Path: "reporting_delay", Err: errors.New("can't be negative")}
+		}
+		q.reportingDelay = delay
+		return nil
+	}
+}
+
+// OptionWithCompaction skips publishing points whose value is unchanged from the
+// previous point published for the same series, reducing ingestion costs for
+// metrics that are mostly idle. To avoid a series appearing to have stopped
+// reporting, a point is published regardless once maxSkipped consecutive points
+// have been skipped.
+//
+// Note: compaction should only be used where dashboards and alerts treat a missing
+// point as carrying the previous value (e.g. gauges), as skipped intervals of a
+// Counter will otherwise appear as having no counts.
+func OptionWithCompaction(maxSkipped int) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("compaction"); err != nil {
+			return err
+		}
+
+		if maxSkipped <= 0 {
+			return &FieldError{Path: "compaction.max_skipped", Err: errors.New("must be greater than 0")}
+		}
+		q.compactor = newCompactor(maxSkipped)
+		return nil
+	}
+}
+
+// OptionWithCumulativeTotals reports each Counter as a running total since the start
+// of the process, rather than as the count within each interval. Every point of a
+// series shares the same start time, which is established by the first point the
+// process reports, so a restarted process begins a new cumulative epoch and rates
+// are rendered correctly across restarts.
+//
+// Exporters whose backends sum the points of counters (see CountSummer) can't be
+// sent running totals, so New returns ErrCumulativeTotalsUnsupported if the option
+// is used with one.
+func OptionWithCumulativeTotals() Option {
+	return func(q *Quantifier) error {
+		q.cumulative = true
+		return nil
+	}
+}
+
+// OptionWithCheckpointStore enables checkpointing of reports, for metrics such as
+// billing counters where intervals must never be lost. The series of each report
+// are saved to the provided CheckpointStore before being exported, and are only
+// acknowledged once the export succeeds. Series that fail to export, or fail to be
+// saved, are retried with the next reports until they're exported (see
+// OptionWithCheckpointMaxAttempts to drop them instead), and any that weren't
+// acknowledged before a crash are loaded by New and exported with the first report.
+//
+// Note: a crash after an export succeeds, but before it is acknowledged, results
+// in its series being exported again. Exporters whose backends reject points for
+// intervals that have already been written (as Google Cloud Monitoring does)
+// therefore avoid double counting.
+func OptionWithCheckpointStore(store CheckpointStore) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("checkpoint_store"); err != nil {
+			return err
+		}
+
+		q.checkpointStore = store
+		return nil
+	}
+}
+
+// OptionWithCheckpointMaxAttempts limits the number of reports that checkpointed
+// series (see OptionWithCheckpointStore) are exported with. Series that still
+// haven't been exported after the provided number of attempts are dropped, and
+// reported as part of a Gap (see OptionWithGapHandler), so that series an exporter
+// will never accept (e.g. duplicates of points already written) aren't retried
+// forever. Attempts are only counted once the series have been saved.
+//
+// By default, checkpointed series are never dropped, and are retried until they're
+// exported.
+func OptionWithCheckpointMaxAttempts(attempts int) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("checkpoint_max_attempts"); err != nil {
+			return err
+		}
+
+		if attempts <= 0 {
+			return &FieldError{Path: "checkpoint_max_attempts", Err: errors.New("must be greater than 0")}
+		}
+
+		q.checkpointMaxAttempts = attempts
+		return nil
+	}
+}
+
+// OptionWithEnableIf allows publishing to be enabled based on the environment, so
+// that instrumentation can remain in code whilst publishing nothing in specified
+// environments (e.g. development). The provided function is called once by New,
+// and if it returns false, all recorded counts are discarded rather than exported.
+// An Exporter isn't required whilst disabled.
+//
+// For example, to disable publishing when the ENV environment variable is "dev":
+//
+//	quantify.OptionWithEnableIf(func() bool {
+//		return os.Getenv("ENV") != "dev"
+//	})
+func OptionWithEnableIf(fn func() bool) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("enable_if"); err != nil {
+			return err
+		}
+
+		q.enableIf = fn
+		return nil
+	}
+}
+
+// OptionWithPublishOnClose publishes the point of each interval as soon as the
+// interval closes (after any reporting delay, see OptionWithReportingDelay), rather
+// than with the next refresh. By default, an interval that closes just after a
+// refresh waits up to a whole refresh interval to be published, which is the lag
+// before it can be seen in dashboards and alerts.
+//
+// The tradeoff is that points are exported in more, smaller batches, as a report
+// is made whenever an interval of any instrument closes, which costs more requests
+// to the exporter's backend. Gauges and other sampled instruments are still only
+// published with each refresh.
+func OptionWithPublishOnClose() Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("publish_on_close"); err != nil {
+			return err
+		}
+
+		q.publishOnClose = true
+		return nil
+	}
+}
+
+// OptionSynchronous creates a Quantifier that doesn't report in the background,
+// with reports instead driven by the caller through Flush and Stop. As no
+// background goroutine is started, tests and short-lived programs (e.g. CLIs) are
+// deterministic and leak-free. The refresh interval is ignored.
+func OptionSynchronous() Option {
+	return func(q *Quantifier) error {
+		q.synchronous = true
+		return nil
+	}
+}
+
+// OptionWithHooks sets functions called at points in the lifecycle of counters,
+// such as their creation and first publish (see Hooks).
+func OptionWithHooks(hooks Hooks) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("hooks"); err != nil {
+			return err
+		}
+
+		q.hooks = hooks
+		return nil
+	}
+}
+
+// OptionWithCatalogFile writes the Catalog of the Quantifier, as JSON, to the file
+// at path with the first report after startup, once instruments have typically
+// been created, and again with any later report if metrics have been created since.
+// Errors writing the file are passed to the error handler.
+func OptionWithCatalogFile(path string) Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("catalog_file"); err != nil {
+			return err
+		}
+
+		if path == "" {
+			return &FieldError{Path: "catalog_file", Err: errors.New("required")}
+		}
+
+		q.catalogPath = path
+		return nil
+	}
+}
+
+// OptionWithBestEffortInstruments treats instrumentation as best effort: when an
+// instrument can't be created (e.g. because its name is invalid), the error is
+// passed to the error handler, once for each distinct error, and a no-op
+// instrument is returned in place of the error, so callers needn't check errors
+// from Create functions.
+func OptionWithBestEffortInstruments() Option {
+	return func(q *Quantifier) error {
+		q.bestEffort = true
+		return nil
+	}
+}
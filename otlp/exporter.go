@@ -0,0 +1,369 @@
+// Package otlp provides a quantify.Exporter that reports metrics in the
+// OpenTelemetry Protocol (OTLP), so that they can flow into an existing
+// OpenTelemetry pipeline, such as an OpenTelemetry Collector, alongside metrics
+// instrumented with OpenTelemetry itself.
+//
+// Metrics are submitted with OTLP/HTTP, using its JSON encoding, so no
+// OpenTelemetry dependencies are required.
+//
+// see: https://opentelemetry.io/docs/specs/otlp/#otlphttp
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+)
+
+const (
+
+	// DefaultEndpoint is the OTLP/HTTP metrics endpoint of a local OpenTelemetry
+	// Collector.
+	DefaultEndpoint = "http://localhost:4318/v1/metrics"
+
+	// ScopeName is the name of the instrumentation scope that metrics are reported
+	// under.
+	ScopeName = "github.com/rustedturnip/quantify"
+)
+
+const (
+
+	// temporalityDelta and temporalityCumulative are the OTLP aggregation
+	// temporalities of sums and histograms, which the JSON encoding represents by
+	// their integer values.
+	temporalityDelta      = 1
+	temporalityCumulative = 2
+)
+
+const (
+
+	// defaultTimeout bounds each request made by the default http.Client, so that
+	// an unresponsive endpoint can't block reporting indefinitely.
+	defaultTimeout = time.Second * 10
+)
+
+// Exporter implements quantify.Exporter, reporting metrics to an OTLP/HTTP
+// endpoint.
+type Exporter struct {
+	endpoint   string
+	client     *http.Client
+	headers    http.Header
+	attributes map[string]string
+}
+
+// New returns an instantiated Exporter, or returns an error if instantiation
+// fails.
+//
+// options allow the user to provide custom configurations as a list of Options.
+// If any options are invalid, a quantify.ValidationErrors describing every
+// problem is returned.
+func New(options ...Option) (*Exporter, error) {
+
+	exporter := &Exporter{
+		endpoint: DefaultEndpoint,
+		client:   &http.Client{Timeout: defaultTimeout},
+		headers:  make(http.Header),
+	}
+
+	// apply every option, so that all problems are reported together
+	var errs quantify.ValidationErrors
+
+	for _, option := range options {
+		err := option(exporter)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return exporter, nil
+}
+
+// Export implements quantify.Exporter, submitting the points of the provided
+// series as a single OTLP request. Counters are reported as monotonic sums, with
+// the DELTA temporality for quantify.MetricKindDelta and CUMULATIVE otherwise
+// (each point being cumulative from its own start), gauges as gauges, and
+// distributions as histograms.
+func (e *Exporter) Export(ctx context.Context, series []*quantify.Series) error {
+
+	if len(series) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(e.createRequest(series))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for key, values := range e.headers {
+		request.Header[key] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("failed to submit metrics: %s: %s", response.Status, message)
+	}
+
+	return nil
+}
+
+// createRequest compiles the provided series into an OTLP metrics request, with a
+// metric for each series.
+func (e *Exporter) createRequest(series []*quantify.Series) *exportRequest {
+
+	scope := &scopeMetrics{
+		Scope:   &scope{Name: ScopeName},
+		Metrics: make([]*metric, 0, len(series)),
+	}
+
+	for _, s := range series {
+		scope.Metrics = append(scope.Metrics, seriesToMetric(s))
+	}
+
+	return &exportRequest{
+		ResourceMetrics: []*resourceMetrics{
+			{
+				Resource: &resource{
+					Attributes: toAttributes(e.attributes),
+				},
+				ScopeMetrics: []*scopeMetrics{scope},
+			},
+		},
+	}
+}
+
+// seriesToMetric converts the provided series to its OTLP representation.
+func seriesToMetric(s *quantify.Series) *metric {
+
+	m := &metric{
+		Name:        s.Metric.Name,
+		Description: s.Metric.Description,
+		Unit:        s.Metric.Unit,
+	}
+
+	attributes := toAttributes(s.Metric.Labels)
+
+	temporality := temporalityCumulative
+	if s.Metric.Kind == quantify.MetricKindDelta {
+		temporality = temporalityDelta
+	}
+
+	switch {
+	case s.Metric.ValueType == quantify.ValueTypeDistribution:
+
+		m.Histogram = &histogram{
+			AggregationTemporality: temporality,
+		}
+
+		for _, point := range s.Points {
+			if point.Distribution != nil {
+				m.Histogram.DataPoints = append(m.Histogram.DataPoints, toHistogramDataPoint(attributes, point))
+			}
+		}
+
+	case s.Metric.Kind == quantify.MetricKindGauge:
+
+		m.Gauge = &gauge{}
+
+		for _, point := range s.Points {
+			m.Gauge.DataPoints = append(m.Gauge.DataPoints, toNumberDataPoint(s.Metric, attributes, point, false))
+		}
+
+	default:
+
+		m.Sum = &sum{
+			AggregationTemporality: temporality,
+			IsMonotonic:            true,
+		}
+
+		for _, point := range s.Points {
+			m.Sum.DataPoints = append(m.Sum.DataPoints, toNumberDataPoint(s.Metric, attributes, point, true))
+		}
+	}
+
+	return m
+}
+
+// toNumberDataPoint converts a point of the provided metric to an OTLP number data
+// point, with a start time if withStart is set.
+func toNumberDataPoint(metric *quantify.Metric, attributes []*keyValue, point *quantify.Point, withStart bool) *numberDataPoint {
+
+	dp := &numberDataPoint{
+		Attributes:   attributes,
+		TimeUnixNano: unixNano(point.End),
+	}
+
+	if withStart {
+		dp.StartTimeUnixNano = unixNano(point.Start)
+	}
+
+	if metric.ValueType == quantify.ValueTypeDouble {
+		value := point.Value
+		dp.AsDouble = &value
+	} else {
+		value := strconv.FormatInt(point.Count, 10)
+		dp.AsInt = &value
+	}
+
+	return dp
+}
+
+// toHistogramDataPoint converts a point of a distribution to an OTLP histogram data
+// point. The underflow and overflow buckets of the distribution are the first and
+// last buckets of the histogram.
+func toHistogramDataPoint(attributes []*keyValue, point *quantify.Point) *histogramDataPoint {
+
+	dv := point.Distribution
+
+	dp := &histogramDataPoint{
+		Attributes:        attributes,
+		StartTimeUnixNano: unixNano(point.Start),
+		TimeUnixNano:      unixNano(point.End),
+		Count:             strconv.FormatInt(dv.Count, 10),
+		Sum:               dv.Mean * float64(dv.Count),
+		ExplicitBounds:    dv.Buckets.Boundaries(),
+		BucketCounts:      make([]string, len(dv.BucketCounts)),
+	}
+
+	for i, count := range dv.BucketCounts {
+		dp.BucketCounts[i] = strconv.FormatInt(count, 10)
+	}
+
+	if dv.Count > 0 {
+		dp.Min = &dv.Min
+		dp.Max = &dv.Max
+	}
+
+	return dp
+}
+
+// toAttributes converts the provided labels to OTLP attributes, sorted by key.
+func toAttributes(labels map[string]string) []*keyValue {
+
+	if len(labels) == 0 {
+		return nil
+	}
+
+	attributes := make([]*keyValue, 0, len(labels))
+	for key, value := range labels {
+		attributes = append(attributes, &keyValue{
+			Key:   key,
+			Value: &anyValue{StringValue: value},
+		})
+	}
+
+	sort.Slice(attributes, func(i, j int) bool {
+		return attributes[i].Key < attributes[j].Key
+	})
+
+	return attributes
+}
+
+// unixNano formats the provided time as OTLP's JSON encoding of a fixed64 (a
+// string of nanoseconds since the Unix epoch).
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// The types below are the subset of the OTLP metrics protos used by the Exporter,
+// in their JSON encoding.
+//
+// see: https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/metrics/v1/metrics.proto
+
+type exportRequest struct {
+	ResourceMetrics []*resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     *resource       `json:"resource"`
+	ScopeMetrics []*scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []*keyValue `json:"attributes,omitempty"`
+}
+
+type scopeMetrics struct {
+	Scope   *scope    `json:"scope"`
+	Metrics []*metric `json:"metrics"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type metric struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Unit        string     `json:"unit,omitempty"`
+	Sum         *sum       `json:"sum,omitempty"`
+	Gauge       *gauge     `json:"gauge,omitempty"`
+	Histogram   *histogram `json:"histogram,omitempty"`
+}
+
+type sum struct {
+	DataPoints             []*numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                `json:"aggregationTemporality"`
+	IsMonotonic            bool               `json:"isMonotonic"`
+}
+
+type gauge struct {
+	DataPoints []*numberDataPoint `json:"dataPoints"`
+}
+
+type histogram struct {
+	DataPoints             []*histogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+}
+
+type numberDataPoint struct {
+	Attributes        []*keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string      `json:"timeUnixNano"`
+	AsInt             *string     `json:"asInt,omitempty"`
+	AsDouble          *float64    `json:"asDouble,omitempty"`
+}
+
+type histogramDataPoint struct {
+	Attributes        []*keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	TimeUnixNano      string      `json:"timeUnixNano"`
+	Count             string      `json:"count"`
+	Sum               float64     `json:"sum"`
+	BucketCounts      []string    `json:"bucketCounts"`
+	ExplicitBounds    []float64   `json:"explicitBounds"`
+	Min               *float64    `json:"min,omitempty"`
+	Max               *float64    `json:"max,omitempty"`
+}
+
+type keyValue struct {
+	Key   string    `json:"key"`
+	Value *anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
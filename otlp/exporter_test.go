@@ -0,0 +1,177 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestExporter_Export(t *testing.T) {
+
+	var header http.Header
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	exporter, err := New(
+		OptionWithEndpoint(server.URL),
+		OptionWithHeader("Authorization", "Bearer token"),
+	)
+	assert.NoError(t, err)
+
+	series := []*quantify.Series{
+		{
+			Metric: &quantify.Metric{
+				Name:   "planes",
+				Labels: map[string]string{"manufacturer": "boeing"},
+				Kind:   quantify.MetricKindDelta,
+			},
+			Points: []*quantify.Point{
+				{Start: time.Unix(1672693340, 0), End: time.Unix(1672693350, 0), Count: 3},
+			},
+		},
+		{
+			Metric: &quantify.Metric{
+				Name:      "latency",
+				Unit:      "ms",
+				ValueType: quantify.ValueTypeDistribution,
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Distribution: &quantify.DistributionValue{
+						Count:        4,
+						Mean:         50,
+						Min:          5,
+						Max:          150,
+						Buckets:      quantify.ExplicitBuckets(10, 100),
+						BucketCounts: []int64{1, 2, 1},
+					},
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, exporter.Export(context.Background(), series))
+
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"resourceMetrics": [
+			{
+				"resource": {},
+				"scopeMetrics": [
+					{
+						"scope": {"name": "github.com/rustedturnip/quantify"},
+						"metrics": [
+							{
+								"name": "planes",
+								"sum": {
+									"dataPoints": [
+										{
+											"attributes": [{"key": "manufacturer", "value": {"stringValue": "boeing"}}],
+											"startTimeUnixNano": "1672693340000000000",
+											"timeUnixNano": "1672693350000000000",
+											"asInt": "3"
+										}
+									],
+									"aggregationTemporality": 1,
+									"isMonotonic": true
+								}
+							},
+							{
+								"name": "latency",
+								"unit": "ms",
+								"histogram": {
+									"dataPoints": [
+										{
+											"startTimeUnixNano": "1672693340000000000",
+											"timeUnixNano": "1672693350000000000",
+											"count": "4",
+											"sum": 200,
+											"bucketCounts": ["1", "2", "1"],
+											"explicitBounds": [10, 100],
+											"min": 5,
+											"max": 150
+										}
+									],
+									"aggregationTemporality": 2
+								}
+							}
+						]
+					}
+				]
+			}
+		]
+	}`, string(body))
+}
+
+func TestExporter_Export_error(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("invalid metrics"))
+	}))
+	defer server.Close()
+
+	exporter, err := New(OptionWithEndpoint(server.URL))
+	assert.NoError(t, err)
+
+	err = exporter.Export(context.Background(), []*quantify.Series{
+		{
+			Metric: &quantify.Metric{Name: "planes"},
+			Points: []*quantify.Point{{Start: time.Unix(1672693340, 0), End: time.Unix(1672693350, 0), Count: 3}},
+		},
+	})
+	assert.EqualError(t, err, "failed to submit metrics: 400 Bad Request: invalid metrics")
+}
+
+func TestExporter_Export_timeout(t *testing.T) {
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	exporter, err := New(OptionWithEndpoint(server.URL))
+	assert.NoError(t, err)
+	exporter.client.Timeout = time.Millisecond * 50
+
+	err = exporter.Export(context.Background(), []*quantify.Series{
+		{
+			Metric: &quantify.Metric{Name: "planes"},
+			Points: []*quantify.Point{{Start: time.Unix(1672693340, 0), End: time.Unix(1672693350, 0), Count: 3}},
+		},
+	})
+
+	var netErr net.Error
+	assert.True(t, errors.As(err, &netErr) && netErr.Timeout())
+}
+
+func TestNew(t *testing.T) {
+
+	_, err := New(OptionWithEndpoint(""))
+	assert.EqualError(t, err, "endpoint: can't be empty")
+
+	// the default client is bounded, rather than waiting indefinitely
+	exporter, err := New()
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTimeout, exporter.client.Timeout)
+}
@@ -0,0 +1,23 @@
+package otlp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rustedturnip/quantify/internal/golden"
+)
+
+func TestExporter_golden(t *testing.T) {
+
+	exporter, err := New(OptionWithResourceAttributes(map[string]string{"service.name": "quantify"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := json.Marshal(exporter.createRequest(golden.Series()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden.Assert(t, "payload", payload)
+}
@@ -0,0 +1,62 @@
+package otlp
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Option defines a function for supplying the Exporter constructor with certain
+// configurations.
+type Option func(*Exporter) error
+
+// OptionWithEndpoint sets the OTLP/HTTP metrics endpoint, which defaults to
+// DefaultEndpoint, e.g. "https://collector.example.com/v1/metrics".
+func OptionWithEndpoint(endpoint string) Option {
+	return func(exporter *Exporter) error {
+		if endpoint == "" {
+			return &quantify.FieldError{Path: "endpoint", Err: errors.New("can't be empty")}
+		}
+		exporter.endpoint = endpoint
+		return nil
+	}
+}
+
+// OptionWithHTTPClient allows a manually configured http.Client to be used to
+// submit metrics, instead of the default client, which times out requests after
+// 10 seconds. The provided client should also bound its requests (e.g. with a
+// Timeout), as a request that never completes blocks reporting.
+func OptionWithHTTPClient(client *http.Client) Option {
+	return func(exporter *Exporter) error {
+		exporter.client = client
+		return nil
+	}
+}
+
+// OptionWithHeader adds a header to each request made to the endpoint, for example
+// to provide authentication.
+func OptionWithHeader(key string, value string) Option {
+	return func(exporter *Exporter) error {
+		exporter.headers.Add(key, value)
+		return nil
+	}
+}
+
+// OptionWithResourceAttributes sets the attributes of the resource that metrics are
+// reported from, such as "service.name", which OpenTelemetry backends use to
+// identify the source of the metrics.
+func OptionWithResourceAttributes(attributes map[string]string) Option {
+	return func(exporter *Exporter) error {
+
+		if exporter.attributes == nil {
+			exporter.attributes = make(map[string]string, len(attributes))
+		}
+
+		for key, value := range attributes {
+			exporter.attributes[key] = value
+		}
+
+		return nil
+	}
+}
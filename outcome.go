@@ -0,0 +1,288 @@
+package quantify
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	outcomeLabelKeyOutcome = "outcome"
+	outcomeLabelKeyReason  = "reason"
+
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+
+	// ReasonUnknown is the reason failures are counted with when their reason isn't
+	// permitted (see OutcomeCounter.PermitReasons).
+	ReasonUnknown = "unknown"
+
+	// outcomeRatioSuffix is appended to the name of an OutcomeCounter to name its
+	// success ratio.
+	outcomeRatioSuffix = "_success_ratio"
+)
+
+// OutcomeCounter is an instrument that counts the outcomes of an operation, such as
+// requests to a service, publishing a series for successes (labelled
+// outcome="success") and a series for each reason of failure (labelled
+// outcome="failure" and reason). Optionally, the ratio of successes to all outcomes
+// is also published as a gauge.
+type OutcomeCounter struct {
+	q        *Quantifier
+	name     string
+	labels   map[string]string
+	interval int64
+	options  []MetricOption
+
+	success *metricCounter
+
+	mu       *sync.Mutex
+	failures map[string]*metricCounter
+
+	// permitted, when set, restricts the reasons that failures are counted with,
+	// with onUnknown called for any other reason.
+	permitted map[string]bool
+	onUnknown func(reason string)
+
+	// ratio is the metric of the success ratio, when published, and successes and
+	// total track the counts observed since it was last published.
+	ratio     *Metric
+	successes int64
+	total     int64
+
+	// noop is set for OutcomeCounters that discard outcomes (see
+	// OptionWithBestEffortInstruments).
+	noop bool
+}
+
+// CreateOutcomeCounter creates an OutcomeCounter, counting outcomes over the provided
+// interval (see CreateCounter). When ratio is set, the ratio of successes to all
+// outcomes within each refresh is also published, as a gauge named with the suffix
+// "_success_ratio".
+func (q *Quantifier) CreateOutcomeCounter(name string, labels map[string]string, interval int64, ratio bool, options ...MetricOption) (*OutcomeCounter, error) {
+
+	instrument, err := q.createOutcomeCounter(name, labels, interval, ratio, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopOutcomeCounter(), nil
+	}
+
+	return instrument, err
+}
+
+// createOutcomeCounter creates an OutcomeCounter (see CreateOutcomeCounter).
+func (q *Quantifier) createOutcomeCounter(name string, labels map[string]string, interval int64, ratio bool, options ...MetricOption) (*OutcomeCounter, error) {
+
+	success, err := q.newMetricCounter(nil, name, withOutcomeLabels(labels, outcomeSuccess, ""), interval, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	// validate the labels of failures up front, as they're created on first use
+	failure := &Metric{
+		Name:   name,
+		Labels: q.mergeCommonLabels(withOutcomeLabels(labels, outcomeFailure, ReasonUnknown)),
+	}
+
+	for _, option := range options {
+		option(failure)
+	}
+
+	err = q.validateMetric(failure)
+	if err != nil {
+		return nil, err
+	}
+
+	oc := &OutcomeCounter{
+		q:        q,
+		name:     name,
+		labels:   labels,
+		interval: interval,
+		options:  options,
+		success:  success,
+		mu:       &sync.Mutex{},
+		failures: make(map[string]*metricCounter),
+	}
+
+	if ratio {
+
+		oc.ratio = &Metric{
+			Name:      name + outcomeRatioSuffix,
+			Labels:    q.mergeCommonLabels(labels),
+			Kind:      MetricKindGauge,
+			ValueType: ValueTypeDouble,
+		}
+
+		err = q.validateMetric(oc.ratio)
+		if err != nil {
+			return nil, err
+		}
+
+		success.observers = append(success.observers, oc.observer(true))
+		q.collectors = append(q.collectors, oc)
+	}
+
+	q.sources = append(q.sources, oc)
+
+	return oc, nil
+}
+
+// Success counts a successful outcome.
+func (oc *OutcomeCounter) Success() {
+	oc.success.counter.Count()
+}
+
+// PermitReasons restricts the reasons that failures are counted with to those
+// provided, preventing unbounded numbers of series being published if reasons are
+// derived from unexpected values (e.g. error messages). Failures with any other
+// reason are counted with ReasonUnknown instead, and onUnknown, if not nil, is
+// called with the original reason so that it can be logged.
+func (oc *OutcomeCounter) PermitReasons(reasons []string, onUnknown func(reason string)) {
+
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	oc.permitted = make(map[string]bool, len(reasons))
+	for _, reason := range reasons {
+		oc.permitted[reason] = true
+	}
+
+	oc.onUnknown = onUnknown
+}
+
+// Failure counts a failed outcome for the provided reason, such as "timeout". A
+// series is published for each distinct reason, so reasons should be drawn from a
+// small, fixed set of values rather than, for example, error messages (see
+// PermitReasons).
+//
+// If the series for a new reason can't be created, the error is passed to the
+// Quantifier's error handler and the failure isn't counted.
+func (oc *OutcomeCounter) Failure(reason string) {
+
+	if oc.noop {
+		return
+	}
+
+	oc.mu.Lock()
+
+	var onUnknown func(string)
+	original := reason
+
+	reason = oc.success.metric.normalise(outcomeLabelKeyReason, reason)
+
+	if oc.permitted != nil && !oc.permitted[reason] {
+		onUnknown = oc.onUnknown
+		reason = ReasonUnknown
+	}
+
+	failure, err := oc.failure(reason)
+	oc.mu.Unlock()
+
+	if onUnknown != nil {
+		onUnknown(original)
+	}
+
+	if err != nil {
+		oc.q.errorHandler(oc.q, err)
+		return
+	}
+
+	failure.counter.Count()
+}
+
+// failure returns the counter of failures for the provided reason, creating it if
+// it doesn't exist. oc.mu must be held.
+func (oc *OutcomeCounter) failure(reason string) (*metricCounter, error) {
+
+	if failure, ok := oc.failures[reason]; ok {
+		return failure, nil
+	}
+
+	failure, err := oc.q.newMetricCounter(nil, oc.name, withOutcomeLabels(oc.labels, outcomeFailure, reason), oc.interval, oc.options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if oc.ratio != nil {
+		failure.observers = append(failure.observers, oc.observer(false))
+	}
+
+	oc.failures[reason] = failure
+	return failure, nil
+}
+
+// metricCounters implements counterSource, returning the counters of successes and
+// of each reason of failure.
+func (oc *OutcomeCounter) metricCounters() []*metricCounter {
+
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	counters := make([]*metricCounter, 0, len(oc.failures)+1)
+	counters = append(counters, oc.success)
+
+	for _, failure := range oc.failures {
+		counters = append(counters, failure)
+	}
+
+	return counters
+}
+
+// observer returns a function that records observed points towards the success
+// ratio.
+func (oc *OutcomeCounter) observer(success bool) func([]*Point) {
+	return func(points []*Point) {
+		oc.mu.Lock()
+		defer oc.mu.Unlock()
+
+		for _, point := range points {
+			oc.total += point.Count
+			if success {
+				oc.successes += point.Count
+			}
+		}
+	}
+}
+
+// collect implements collector, publishing the success ratio of the outcomes
+// reported since the previous report. Nothing is published if there were none.
+func (oc *OutcomeCounter) collect(now time.Time) []*Series {
+
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	successes, total := oc.successes, oc.total
+	oc.successes, oc.total = 0, 0
+
+	if total == 0 {
+		return nil
+	}
+
+	return []*Series{
+		{
+			Metric: oc.ratio,
+			Points: []*Point{
+				{
+					Start: now,
+					End:   now,
+					Value: float64(successes) / float64(total),
+				},
+			},
+		},
+	}
+}
+
+// withOutcomeLabels returns a copy of the provided labels with the outcome, and
+// reason if not empty, added.
+func withOutcomeLabels(labels map[string]string, outcome string, reason string) map[string]string {
+
+	merged := make(map[string]string, len(labels)+2)
+	for key, value := range labels {
+		merged[key] = value
+	}
+
+	merged[outcomeLabelKeyOutcome] = outcome
+	if reason != "" {
+		merged[outcomeLabelKeyReason] = reason
+	}
+
+	return merged
+}
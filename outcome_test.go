@@ -0,0 +1,127 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_CreateOutcomeCounter(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	requests, err := client.CreateOutcomeCounter("requests", map[string]string{"service": "api"}, 10, true)
+	assert.NoError(t, err)
+
+	for i := 0; i < 6; i++ {
+		requests.Success()
+	}
+	requests.Failure("timeout")
+	requests.Failure("timeout")
+	requests.Failure("refused")
+
+	client.report(true)
+
+	counts := make(map[string]int64)
+	var ratio float64
+
+	for _, s := range exporter.series {
+
+		assert.Equal(t, "api", s.Metric.Labels["service"])
+
+		if s.Metric.Name == "requests_success_ratio" {
+			ratio = s.Points[0].Value
+			continue
+		}
+
+		counts[s.Metric.Labels["outcome"]+"/"+s.Metric.Labels["reason"]] = s.Points[0].Count
+	}
+
+	assert.Equal(t, map[string]int64{
+		"success/":        6,
+		"failure/timeout": 2,
+		"failure/refused": 1,
+	}, counts)
+	assert.InDelta(t, 6.0/9.0, ratio, 0.0001)
+
+	// no outcomes within the next refresh, so no ratio is published
+	exporter.series = nil
+	mockClock.Add(time.Minute)
+	client.report(true)
+	assert.Empty(t, exporter.series)
+}
+
+func TestQuantifier_CreateOutcomeCounter_invalid(t *testing.T) {
+
+	var handled error
+
+	client := &Quantifier{
+		clock:        newMockClock(),
+		exporter:     &mockExporter{},
+		errorHandler: func(q *Quantifier, err error) { handled = err },
+	}
+
+	_, err := client.CreateOutcomeCounter("requests", nil, 0, false)
+	assert.Error(t, err)
+
+	requests, err := client.CreateOutcomeCounter("requests", nil, 10, false)
+	assert.NoError(t, err)
+
+	// reasons are validated when first used
+	client.exporter = &mockExporter{validationErr: errors.New("invalid label key provided")}
+	requests.Failure("timeout")
+	assert.EqualError(t, handled, "invalid label key provided")
+	assert.Len(t, requests.metricCounters(), 1)
+}
+
+func TestOutcomeCounter_PermitReasons(t *testing.T) {
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        newMockClock(),
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	requests, err := client.CreateOutcomeCounter("requests", nil, 10, false)
+	assert.NoError(t, err)
+
+	var unknown []string
+	requests.PermitReasons([]string{"timeout", "refused"}, func(reason string) {
+		unknown = append(unknown, reason)
+	})
+
+	requests.Failure("timeout")
+	requests.Failure("dial tcp 10.0.0.1:443: i/o timeout")
+	requests.Failure("dial tcp 10.0.0.2:443: i/o timeout")
+
+	client.report(true)
+
+	counts := make(map[string]int64)
+	for _, s := range exporter.series {
+		counts[s.Metric.Labels["reason"]] = s.Points[0].Count
+	}
+
+	assert.Equal(t, map[string]int64{
+		"timeout":     1,
+		ReasonUnknown: 2,
+	}, counts)
+	assert.Equal(t, []string{
+		"dial tcp 10.0.0.1:443: i/o timeout",
+		"dial tcp 10.0.0.2:443: i/o timeout",
+	}, unknown)
+}
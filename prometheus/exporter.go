@@ -0,0 +1,388 @@
+// Package prometheus provides a quantify.Exporter that serves the metrics exported
+// to it over HTTP in the Prometheus text exposition format, so that the same
+// instrumentation can be pushed to one backend and scraped by Prometheus, e.g.
+//
+//	exporter, err := prometheus.New()
+//
+//	http.Handle("/metrics", exporter)
+//
+// As the Exporter serves the metrics of the most recent export, scrapes only see
+// the counts of intervals that have been reported, so the Quantifier's refresh
+// interval should be shorter than the scrape interval.
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+)
+
+const (
+
+	// ContentType is the content type of the text exposition format.
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	// counterSuffix is the suffix of the names of Prometheus counters.
+	counterSuffix = "_total"
+)
+
+// family is the series of a metric, sharing a name and type.
+type family struct {
+	name  string
+	typ   string
+	help  string
+	nodes map[string]*node
+}
+
+// node is the state of a single series of a family, accumulated across exports.
+type node struct {
+	labels string
+
+	// start is the start of the point most recently exported to the series, and
+	// value its counter total or gauge value.
+	start time.Time
+	value float64
+
+	// bounds, buckets, count and sum are the running totals of histograms, with
+	// buckets holding the number of observations less than each bound.
+	bounds  []float64
+	buckets []int64
+	count   int64
+	sum     float64
+}
+
+// Exporter implements quantify.Exporter and http.Handler, serving the metrics
+// exported to it to Prometheus.
+type Exporter struct {
+	mu        *sync.Mutex
+	namespace string
+	families  map[string]*family
+}
+
+// New returns an instantiated Exporter, or returns an error if instantiation
+// fails.
+//
+// options allow the user to provide custom configurations as a list of Options.
+// If any options are invalid, a quantify.ValidationErrors describing every
+// problem is returned.
+func New(options ...Option) (*Exporter, error) {
+
+	exporter := &Exporter{
+		mu:       &sync.Mutex{},
+		families: make(map[string]*family),
+	}
+
+	// apply every option, so that all problems are reported together
+	var errs quantify.ValidationErrors
+
+	for _, option := range options {
+		err := option(exporter)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return exporter, nil
+}
+
+// Export implements quantify.Exporter, adding the points of the provided series to
+// the metrics served. Counters are served as running totals, whether the
+// Quantifier reports the counts of each interval or running totals (see
+// quantify.OptionWithCumulativeTotals), gauges as their latest value, and
+// distributions as histograms.
+func (e *Exporter) Export(ctx context.Context, series []*quantify.Series) error {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, s := range series {
+
+		f := e.family(s.Metric)
+
+		labels := formatLabels(s.Metric.Labels)
+
+		n, ok := f.nodes[labels]
+		if !ok {
+			n = &node{labels: labels}
+			f.nodes[labels] = n
+		}
+
+		for _, point := range s.Points {
+			n.add(s.Metric, point)
+		}
+	}
+
+	return nil
+}
+
+// family returns the family of the provided metric, creating it if it doesn't
+// exist.
+func (e *Exporter) family(metric *quantify.Metric) *family {
+
+	name := e.metricName(metric)
+
+	if f, ok := e.families[name]; ok {
+		return f
+	}
+
+	f := &family{
+		name:  name,
+		typ:   metricType(metric),
+		help:  metric.Description,
+		nodes: make(map[string]*node),
+	}
+
+	e.families[name] = f
+
+	return f
+}
+
+// metricName returns the Prometheus name of the provided metric, prefixed with the
+// namespace of the Exporter, if any, and with characters that aren't permitted
+// replaced by underscores. Counters are suffixed with "_total".
+func (e *Exporter) metricName(metric *quantify.Metric) string {
+
+	name := metric.Name
+	if e.namespace != "" {
+		name = e.namespace + "_" + name
+	}
+
+	name = sanitise(name, true)
+
+	if metricType(metric) == "counter" && !strings.HasSuffix(name, counterSuffix) {
+		name += counterSuffix
+	}
+
+	return name
+}
+
+// metricType returns the Prometheus type of the provided metric.
+func metricType(metric *quantify.Metric) string {
+
+	switch {
+	case metric.ValueType == quantify.ValueTypeDistribution:
+		return "histogram"
+	case metric.Kind == quantify.MetricKindGauge:
+		return "gauge"
+	default:
+		return "counter"
+	}
+}
+
+// add adds the provided point of the provided metric to the node.
+func (n *node) add(metric *quantify.Metric, point *quantify.Point) {
+
+	if metric.ValueType == quantify.ValueTypeDistribution {
+		n.observe(point.Distribution)
+		return
+	}
+
+	value := float64(point.Count)
+	if metric.ValueType == quantify.ValueTypeDouble {
+		value = point.Value
+	}
+
+	switch {
+	case metric.Kind == quantify.MetricKindGauge:
+		n.value = value
+
+	// running totals share the start of their series, so replace the previous
+	case metric.Kind == quantify.MetricKindCumulative && point.Start.Equal(n.start):
+		n.value = value
+
+	default:
+		n.value += value
+	}
+
+	n.start = point.Start
+}
+
+// observe adds the observations of the provided distribution to the node.
+func (n *node) observe(dv *quantify.DistributionValue) {
+
+	if dv == nil {
+		return
+	}
+
+	if n.buckets == nil {
+		n.bounds = dv.Buckets.Boundaries()
+		n.buckets = make([]int64, len(n.bounds))
+	}
+
+	// the underflow bucket is below the first bound, and each finite bucket below
+	// the next
+	var below int64
+	for i := range n.buckets {
+		if i < len(dv.BucketCounts) {
+			below += dv.BucketCounts[i]
+		}
+		n.buckets[i] += below
+	}
+
+	n.count += dv.Count
+	n.sum += dv.Mean * float64(dv.Count)
+}
+
+// ServeHTTP implements http.Handler, writing the metrics in the text exposition
+// format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", ContentType)
+	_, _ = w.Write(e.render())
+}
+
+// render returns the metrics in the text exposition format, with families sorted by
+// name, and their series by label.
+func (e *Exporter) render() []byte {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	buffer := &bytes.Buffer{}
+
+	names := make([]string, 0, len(e.families))
+	for name := range e.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		f := e.families[name]
+
+		if f.help != "" {
+			fmt.Fprintf(buffer, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		}
+		fmt.Fprintf(buffer, "# TYPE %s %s\n", f.name, f.typ)
+
+		labels := make([]string, 0, len(f.nodes))
+		for l := range f.nodes {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+
+		for _, l := range labels {
+			f.nodes[l].render(buffer, f)
+		}
+	}
+
+	return buffer.Bytes()
+}
+
+// render writes the samples of the node, a series of the provided family.
+func (n *node) render(buffer *bytes.Buffer, f *family) {
+
+	if f.typ != "histogram" {
+		fmt.Fprintf(buffer, "%s%s %s\n", f.name, braced(n.labels), formatFloat(n.value))
+		return
+	}
+
+	for i, bound := range n.bounds {
+		fmt.Fprintf(buffer, "%s_bucket%s %d\n", f.name, braced(withLe(n.labels, formatFloat(bound))), n.buckets[i])
+	}
+
+	fmt.Fprintf(buffer, "%s_bucket%s %d\n", f.name, braced(withLe(n.labels, "+Inf")), n.count)
+	fmt.Fprintf(buffer, "%s_sum%s %s\n", f.name, braced(n.labels), formatFloat(n.sum))
+	fmt.Fprintf(buffer, "%s_count%s %d\n", f.name, braced(n.labels), n.count)
+}
+
+// formatLabels returns the provided labels in the text exposition format, sorted by
+// key and without braces, e.g. `colour="red",model="737"`.
+func formatLabels(labels map[string]string) string {
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = sanitise(key, false) + `="` + escapeLabelValue(labels[key]) + `"`
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// withLe returns the provided formatted labels with the "le" label of a histogram
+// bucket.
+func withLe(labels string, le string) string {
+
+	if labels == "" {
+		return `le="` + le + `"`
+	}
+
+	return labels + `,le="` + le + `"`
+}
+
+// braced returns the provided formatted labels within braces, or nothing if there
+// are none.
+func braced(labels string) string {
+
+	if labels == "" {
+		return ""
+	}
+
+	return "{" + labels + "}"
+}
+
+// sanitise replaces the characters of the provided name that aren't permitted in
+// Prometheus metric names (or, if metric isn't set, label names) with underscores.
+// Names starting with a digit are prefixed with an underscore.
+func sanitise(name string, metric bool) string {
+
+	sanitised := []byte(name)
+
+	for i, c := range sanitised {
+
+		valid := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9') || (metric && c == ':')
+
+		if !valid {
+			sanitised[i] = '_'
+		}
+	}
+
+	if len(sanitised) > 0 && sanitised[0] >= '0' && sanitised[0] <= '9' {
+		return "_" + string(sanitised)
+	}
+
+	return string(sanitised)
+}
+
+// escapeLabelValue escapes backslashes, double quotes and line feeds in the
+// provided label value.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeHelp escapes backslashes and line feeds in the provided help text.
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// formatFloat formats the provided value as in the text exposition format.
+func formatFloat(value float64) string {
+
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
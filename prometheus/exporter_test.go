@@ -0,0 +1,170 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestExporter_Export(t *testing.T) {
+
+	exporter, err := New(OptionWithNamespace("fleet"))
+	assert.NoError(t, err)
+
+	planes := &quantify.Metric{
+		Name:        "planes",
+		Labels:      map[string]string{"model": "737-800", "manufacturer": "boeing"},
+		Description: "Planes in the fleet.",
+	}
+
+	totals := &quantify.Metric{
+		Name: "checkout/revenue",
+	}
+
+	altitude := &quantify.Metric{
+		Name:      "altitude",
+		Labels:    map[string]string{"callsign": `speedbird "1"`},
+		Kind:      quantify.MetricKindGauge,
+		ValueType: quantify.ValueTypeDouble,
+	}
+
+	latency := &quantify.Metric{
+		Name:      "latency",
+		ValueType: quantify.ValueTypeDistribution,
+	}
+
+	buckets := quantify.ExplicitBuckets(10, 100)
+
+	exports := [][]*quantify.Series{
+		{
+			{
+				Metric: planes,
+				Points: []*quantify.Point{
+					{Start: time.Unix(1672693340, 0), End: time.Unix(1672693350, 0), Count: 3},
+					{Start: time.Unix(1672693350, 0), End: time.Unix(1672693360, 0), Count: 5},
+				},
+			},
+			{
+				Metric: totals,
+				Points: []*quantify.Point{{Start: time.Unix(1672693340, 0), End: time.Unix(1672693350, 0), Count: 120}},
+			},
+			{
+				Metric: altitude,
+				Points: []*quantify.Point{{Start: time.Unix(1672693350, 0), End: time.Unix(1672693350, 0), Value: 35000}},
+			},
+			{
+				Metric: latency,
+				Points: []*quantify.Point{
+					{
+						Start: time.Unix(1672693340, 0),
+						End:   time.Unix(1672693350, 0),
+						Distribution: &quantify.DistributionValue{
+							Count:        4,
+							Mean:         50,
+							Buckets:      buckets,
+							BucketCounts: []int64{1, 2, 1},
+						},
+					},
+				},
+			},
+		},
+		{
+			{
+				Metric: planes,
+				Points: []*quantify.Point{{Start: time.Unix(1672693360, 0), End: time.Unix(1672693370, 0), Count: 2}},
+			},
+
+			// running totals share the start of the series
+			{
+				Metric: totals,
+				Points: []*quantify.Point{{Start: time.Unix(1672693340, 0), End: time.Unix(1672693360, 0), Count: 150}},
+			},
+			{
+				Metric: altitude,
+				Points: []*quantify.Point{{Start: time.Unix(1672693360, 0), End: time.Unix(1672693360, 0), Value: 36000}},
+			},
+		},
+	}
+
+	for _, series := range exports {
+		assert.NoError(t, exporter.Export(context.Background(), series))
+	}
+
+	expected := `# TYPE fleet_altitude gauge
+fleet_altitude{callsign="speedbird \"1\""} 36000
+# TYPE fleet_checkout_revenue_total counter
+fleet_checkout_revenue_total 150
+# TYPE fleet_latency histogram
+fleet_latency_bucket{le="10"} 1
+fleet_latency_bucket{le="100"} 3
+fleet_latency_bucket{le="+Inf"} 4
+fleet_latency_sum 200
+fleet_latency_count 4
+# HELP fleet_planes_total Planes in the fleet.
+# TYPE fleet_planes_total counter
+fleet_planes_total{manufacturer="boeing",model="737-800"} 10
+`
+
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, expected, recorder.Body.String())
+}
+
+func TestSanitise(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		metric   bool
+		expected string
+	}{
+		{
+			name:     "valid",
+			input:    "planes_total",
+			metric:   true,
+			expected: "planes_total",
+		},
+		{
+			name:     "separators",
+			input:    "checkout/revenue.usd",
+			metric:   true,
+			expected: "checkout_revenue_usd",
+		},
+		{
+			name:     "leading digit",
+			input:    "737_count",
+			metric:   true,
+			expected: "_737_count",
+		},
+		{
+			name:     "colon in metric",
+			input:    "job:planes",
+			metric:   true,
+			expected: "job:planes",
+		},
+		{
+			name:     "colon in label",
+			input:    "job:planes",
+			metric:   false,
+			expected: "job_planes",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, sanitise(test.input, test.metric), "%s failed", test.name)
+	}
+}
+
+func TestNew(t *testing.T) {
+
+	_, err := New(OptionWithNamespace(""))
+	assert.EqualError(t, err, "namespace: can't be empty")
+}
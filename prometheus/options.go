@@ -0,0 +1,24 @@
+package prometheus
+
+import (
+	"errors"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Option defines a function for supplying the Exporter constructor with certain
+// configurations.
+type Option func(*Exporter) error
+
+// OptionWithNamespace prefixes the name of every metric served with the provided
+// namespace and an underscore, e.g. "myapp_planes_total" for the namespace
+// "myapp".
+func OptionWithNamespace(namespace string) Option {
+	return func(exporter *Exporter) error {
+		if namespace == "" {
+			return &quantify.FieldError{Path: "namespace", Err: errors.New("can't be empty")}
+		}
+		exporter.namespace = namespace
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+package quantify
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrQueryUnsupported = errors.New("exporter doesn't support queries")
+)
+
+// Querier can optionally be implemented by an Exporter to allow previously
+// exported metrics to be read back.
+type Querier interface {
+
+	// Query returns the series of the named metric that have points within the
+	// provided time range.
+	Query(ctx context.Context, name string, start time.Time, end time.Time) ([]*Series, error)
+}
+
+// Query reads back the series of the named metric that have been exported within
+// the provided window (up until now), for example so an application can perform
+// self-checks or adapt its behaviour based on its own metrics.
+//
+// ErrQueryUnsupported is returned if the Quantifier's Exporter doesn't implement
+// Querier.
+func (q *Quantifier) Query(ctx context.Context, name string, window time.Duration) ([]*Series, error) {
+
+	querier, ok := q.exporter.(Querier)
+	if !ok {
+		return nil, ErrQueryUnsupported
+	}
+
+	end := q.clock.Now()
+
+	return querier.Query(ctx, name, end.Add(-window), end)
+}
@@ -0,0 +1,47 @@
+package quantify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockQuerier implements Exporter and Querier, recording the time range queried.
+type mockQuerier struct {
+	mockExporter
+	start time.Time
+	end   time.Time
+}
+
+func (mq *mockQuerier) Query(ctx context.Context, name string, start time.Time, end time.Time) ([]*Series, error) {
+	mq.start = start
+	mq.end = end
+	return []*Series{{Metric: &Metric{Name: name}}}, nil
+}
+
+func TestQuantifier_Query(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	// unsupported
+	client := &Quantifier{
+		clock:    mockClock,
+		exporter: &mockExporter{},
+	}
+
+	_, err := client.Query(context.Background(), "planes", time.Hour)
+	assert.Equal(t, ErrQueryUnsupported, err)
+
+	// supported
+	querier := &mockQuerier{}
+	client.exporter = querier
+
+	series, err := client.Query(context.Background(), "planes", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "planes", series[0].Metric.Name)
+	assert.Equal(t, time.Unix(1670678170, 0), querier.start)
+	assert.Equal(t, time.Unix(1670681770, 0), querier.end)
+}
@@ -0,0 +1,163 @@
+package quantify
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	rateLabelKeyWindow = "window"
+)
+
+var (
+	// defaultRateWindows are the windows over which a Rate decays, matching those of
+	// the load average.
+	defaultRateWindows = []time.Duration{
+		time.Minute,
+		5 * time.Minute,
+		15 * time.Minute,
+	}
+)
+
+// Rate is an instrument that tracks the rate of occurrences per second as
+// exponentially weighted moving averages (EWMA), like the 1, 5 and 15 minute load
+// averages of a system. Each occurrence's contribution decays exponentially with
+// time, so recent occurrences count for more than older ones, and the rate over
+// each window is published as a gauge, labelled with the window (e.g. window="5m").
+type Rate struct {
+	mu      *sync.Mutex
+	metric  *Metric
+	windows []time.Duration
+
+	// uncounted is the number of occurrences since the rates were last updated.
+	uncounted int64
+
+	// rates are the averages of each window, as of updated (valid once initialised
+	// is set).
+	rates       []float64
+	updated     time.Time
+	initialised bool
+}
+
+// CreateRate creates a Rate that tracks the rate of occurrences per second, decayed
+// over windows of 1m, 5m and 15m, for load average like signals. The averages are
+// updated and published with each refresh, the first being the rate since the Rate
+// was created.
+//
+// options allow optional metadata, such as a display name, to be provided as a
+// list of MetricOptions.
+func (q *Quantifier) CreateRate(name string, labels map[string]string, options ...MetricOption) (*Rate, error) {
+
+	instrument, err := q.createRate(name, labels, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopRate(), nil
+	}
+
+	return instrument, err
+}
+
+// createRate creates a Rate (see CreateRate).
+func (q *Quantifier) createRate(name string, labels map[string]string, options ...MetricOption) (*Rate, error) {
+
+	metric := &Metric{
+		Name:      name,
+		Labels:    q.mergeCommonLabels(labels),
+		Kind:      MetricKindGauge,
+		ValueType: ValueTypeDouble,
+		Unit:      "1/s",
+	}
+
+	for _, option := range options {
+		option(metric)
+	}
+
+	// validate including the window label that will be added on publishing
+	err := q.validateMetric(withLabel(metric, rateLabelKeyWindow, formatWindow(defaultRateWindows[0])))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Rate{
+		mu:      &sync.Mutex{},
+		metric:  metric,
+		windows: defaultRateWindows,
+		rates:   make([]float64, len(defaultRateWindows)),
+		updated: q.clock.Now(),
+	}
+
+	q.collectors = append(q.collectors, r)
+
+	return r, nil
+}
+
+// Mark records a single occurrence.
+func (r *Rate) Mark() {
+	atomic.AddInt64(&r.uncounted, 1)
+}
+
+// Add records n occurrences. If n is negative, ErrNegativeValue is returned.
+func (r *Rate) Add(n int64) error {
+
+	if n < 0 {
+		return ErrNegativeValue
+	}
+
+	atomic.AddInt64(&r.uncounted, n)
+	return nil
+}
+
+// collect implements collector, updating the rate of each window with the
+// occurrences since the previous update, and publishing them.
+func (r *Rate) collect(now time.Time) []*Series {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.update(now)
+
+	series := make([]*Series, 0, len(r.windows))
+
+	for i, window := range r.windows {
+		series = append(series, &Series{
+			Metric: withLabel(r.metric, rateLabelKeyWindow, formatWindow(window)),
+			Points: []*Point{
+				{
+					Start: now,
+					End:   now,
+					Value: r.rates[i],
+				},
+			},
+		})
+	}
+
+	return series
+}
+
+// update decays the rate of each window towards the rate of the occurrences since
+// the previous update, by a weight that depends on the time elapsed, so that the
+// averages don't depend on how often they're updated.
+func (r *Rate) update(now time.Time) {
+
+	elapsed := now.Sub(r.updated).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	instant := float64(atomic.SwapInt64(&r.uncounted, 0)) / elapsed
+
+	for i, window := range r.windows {
+
+		if !r.initialised {
+			r.rates[i] = instant
+			continue
+		}
+
+		alpha := 1 - math.Exp(-elapsed/window.Seconds())
+		r.rates[i] += alpha * (instant - r.rates[i])
+	}
+
+	r.initialised = true
+	r.updated = now
+}
@@ -0,0 +1,96 @@
+package quantify
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rateValues returns the published value of each window of the provided series.
+func rateValues(series []*Series) map[string]float64 {
+
+	values := make(map[string]float64)
+	for _, s := range series {
+		values[s.Metric.Labels[rateLabelKeyWindow]] = s.Points[0].Value
+	}
+
+	return values
+}
+
+func TestRate_collect(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     &mockExporter{},
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	rate, err := client.CreateRate("requests", map[string]string{"service": "bookings"})
+	assert.NoError(t, err)
+	assert.Equal(t, "1/s", rate.metric.Unit)
+
+	// the first update is the rate since creation
+	for i := 0; i < 60; i++ {
+		rate.Mark()
+	}
+	assert.NoError(t, rate.Add(60))
+
+	mockClock.Add(time.Minute)
+	series := rate.collect(mockClock.Now())
+
+	if assert.Len(t, series, 3) {
+		assert.Equal(t, map[string]string{"service": "bookings", "window": "1m"}, series[0].Metric.Labels)
+		assert.Equal(t, mockClock.Now(), series[0].Points[0].Start)
+	}
+	assert.Equal(t, map[string]float64{"1m": 2, "5m": 2, "15m": 2}, rateValues(series))
+
+	// without occurrences, each decays by e^(-elapsed/window)
+	mockClock.Add(time.Minute)
+	values := rateValues(rate.collect(mockClock.Now()))
+
+	assert.InDelta(t, 2*math.Exp(-1), values["1m"], 1e-9)
+	assert.InDelta(t, 2*math.Exp(-1.0/5), values["5m"], 1e-9)
+	assert.InDelta(t, 2*math.Exp(-1.0/15), values["15m"], 1e-9)
+
+	// the decay doesn't depend on how often the rates are updated
+	for i := 0; i < 4; i++ {
+		mockClock.Add(time.Second * 15)
+		values = rateValues(rate.collect(mockClock.Now()))
+	}
+
+	assert.InDelta(t, 2*math.Exp(-2), values["1m"], 1e-9)
+	assert.InDelta(t, 2*math.Exp(-2.0/5), values["5m"], 1e-9)
+	assert.InDelta(t, 2*math.Exp(-2.0/15), values["15m"], 1e-9)
+
+	// no time has elapsed, so the rates are unchanged
+	rate.Mark()
+	assert.Equal(t, values, rateValues(rate.collect(mockClock.Now())))
+}
+
+func TestRate_Add(t *testing.T) {
+
+	rate := newNoopRate()
+
+	assert.Equal(t, ErrNegativeValue, rate.Add(-1))
+	assert.NoError(t, rate.Add(3))
+	assert.Equal(t, int64(3), rate.uncounted)
+}
+
+func TestQuantifier_CreateRate_invalid(t *testing.T) {
+
+	client := &Quantifier{
+		clock:    systemClock{},
+		exporter: &mockExporter{validationErr: errors.New("invalid name")},
+	}
+
+	_, err := client.CreateRate("requests", nil)
+
+	assert.EqualError(t, err, "invalid name")
+	assert.Empty(t, client.collectors)
+}
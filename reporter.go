@@ -0,0 +1,73 @@
+package quantify
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// reporterInterval is the interval, in seconds, of counters created by a Reporter,
+// matching the minutely schedule of the cron-based Reporter it replaces.
+const reporterInterval = 60
+
+// warnDeprecated logs deprecation warnings for the Reporter API.
+var warnDeprecated = func(message string) {
+	log.Print("quantify: deprecated: " + message)
+}
+
+// Reporter maps the API of the cron-based Reporter of earlier versions onto a
+// Quantifier, allowing code written against it to be upgraded without changes.
+// Counters created by a Reporter count in intervals of 60 seconds.
+//
+// Deprecated: use Quantifier, created with New, directly.
+type Reporter struct {
+	q *Quantifier
+
+	warnOnce *sync.Once
+}
+
+// NewReporter returns a Reporter backed by a Quantifier created with the provided
+// options, logging a deprecation warning.
+//
+// Deprecated: use New.
+func NewReporter(ctx context.Context, options ...Option) (*Reporter, error) {
+
+	warnDeprecated("NewReporter is deprecated, use New")
+
+	q, err := New(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reporter{
+		q:        q,
+		warnOnce: &sync.Once{},
+	}, nil
+}
+
+// CreateCounter creates a Counter with the provided name and labels that counts in
+// intervals of 60 seconds. A deprecation warning is logged the first time it's
+// called.
+//
+// Deprecated: use Quantifier.CreateCounter with an interval of 60.
+func (r *Reporter) CreateCounter(name string, labels map[string]string) (*Counter, error) {
+
+	r.warnOnce.Do(func() {
+		warnDeprecated("Reporter.CreateCounter is deprecated, use Quantifier.CreateCounter with an interval of 60")
+	})
+
+	return r.q.CreateCounter(name, labels, reporterInterval)
+}
+
+// Quantifier returns the Quantifier backing the Reporter, so that code can be
+// migrated from the Reporter incrementally.
+func (r *Reporter) Quantifier() *Quantifier {
+	return r.q
+}
+
+// Stop reports any remaining counts and stops the Reporter (see Quantifier.Stop).
+//
+// Deprecated: use Quantifier.Stop.
+func (r *Reporter) Stop() {
+	r.q.Stop()
+}
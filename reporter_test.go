@@ -0,0 +1,72 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReporter(t *testing.T) {
+
+	warnings := make([]string, 0)
+
+	warn := warnDeprecated
+	warnDeprecated = func(message string) {
+		warnings = append(warnings, message)
+	}
+	defer func() {
+		warnDeprecated = warn
+	}()
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681760, 0))
+
+	exporter := &mockExporter{}
+
+	reporter, err := NewReporter(
+		context.Background(),
+		OptionWithClock(mockClock),
+		OptionWithExporter(exporter),
+		OptionSynchronous(),
+	)
+	assert.NoError(t, err)
+
+	red, err := reporter.CreateCounter("planes", map[string]string{"colour": "red"})
+	assert.NoError(t, err)
+
+	blue, err := reporter.CreateCounter("planes", map[string]string{"colour": "blue"})
+	assert.NoError(t, err)
+
+	red.Count()
+	blue.Count()
+	blue.Count()
+
+	// counters count in 60 second intervals
+	mockClock.Add(time.Second * 59)
+	assert.NoError(t, reporter.Quantifier().Flush())
+	assert.Empty(t, exporter.series)
+
+	mockClock.Add(time.Second)
+	assert.NoError(t, reporter.Quantifier().Flush())
+
+	if assert.Len(t, exporter.series, 2) {
+		for _, series := range exporter.series {
+			assert.Equal(t, time.Unix(1670681760, 0), series.Points[0].Start)
+			assert.Equal(t, time.Unix(1670681820, 0), series.Points[0].End)
+		}
+		assert.Equal(t, int64(1), exporter.series[0].Points[0].Count)
+		assert.Equal(t, int64(2), exporter.series[1].Points[0].Count)
+	}
+
+	reporter.Stop()
+
+	// warnings are logged on construction and the first counter
+	assert.Equal(t, []string{
+		"NewReporter is deprecated, use New",
+		"Reporter.CreateCounter is deprecated, use Quantifier.CreateCounter with an interval of 60",
+	}, warnings)
+}
@@ -0,0 +1,45 @@
+package quantify
+
+import "sync/atomic"
+
+const (
+	reservationPending int32 = iota
+	reservationCommitted
+	reservationRolledBack
+)
+
+// Reservation is a pending count of a Counter, which is only counted once it has
+// been committed (see Counter.Reserve).
+type Reservation struct {
+	counter *Counter
+	state   int32
+}
+
+// Reserve returns a Reservation for a single count of this Counter, allowing
+// speculative work to pre-count and later either Commit the count or Rollback to
+// cancel it, for counters where over-counting is unacceptable.
+//
+// The count is attributed to the interval in which it is committed.
+func (c *Counter) Reserve() *Reservation {
+	return &Reservation{
+		counter: c,
+	}
+}
+
+// Commit counts the reserved count. It returns false, without counting, if the
+// Reservation has already been committed or rolled back.
+func (r *Reservation) Commit() bool {
+
+	if !atomic.CompareAndSwapInt32(&r.state, reservationPending, reservationCommitted) {
+		return false
+	}
+
+	r.counter.add(r.counter.clock.Now(), 1)
+	return true
+}
+
+// Rollback cancels the reserved count. It returns false if the Reservation has
+// already been committed or rolled back.
+func (r *Reservation) Rollback() bool {
+	return atomic.CompareAndSwapInt32(&r.state, reservationPending, reservationRolledBack)
+}
@@ -0,0 +1,80 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservation(t *testing.T) {
+
+	tests := []struct {
+		name           string
+		actions        []func(r *Reservation) bool
+		expectedResult []bool
+		expectedCount  int64
+	}{
+		{
+			name:           "commit",
+			actions:        []func(r *Reservation) bool{(*Reservation).Commit},
+			expectedResult: []bool{true},
+			expectedCount:  1,
+		},
+		{
+			name:           "rollback",
+			actions:        []func(r *Reservation) bool{(*Reservation).Rollback},
+			expectedResult: []bool{true},
+			expectedCount:  0,
+		},
+		{
+			name:           "commit twice",
+			actions:        []func(r *Reservation) bool{(*Reservation).Commit, (*Reservation).Commit},
+			expectedResult: []bool{true, false},
+			expectedCount:  1,
+		},
+		{
+			name:           "commit after rollback",
+			actions:        []func(r *Reservation) bool{(*Reservation).Rollback, (*Reservation).Commit},
+			expectedResult: []bool{true, false},
+			expectedCount:  0,
+		},
+		{
+			name:           "rollback after commit",
+			actions:        []func(r *Reservation) bool{(*Reservation).Commit, (*Reservation).Rollback},
+			expectedResult: []bool{true, false},
+			expectedCount:  1,
+		},
+	}
+
+	for _, test := range tests {
+
+		mockClock := newMockClock()
+		mockClock.Set(time.Unix(1670681770, 0))
+
+		counter := &Counter{
+			clock:    mockClock,
+			interval: 10,
+			counts:   &sync.Map{},
+			mu:       &sync.Mutex{},
+		}
+
+		reservation := counter.Reserve()
+
+		results := make([]bool, 0, len(test.actions))
+		for _, action := range test.actions {
+			results = append(results, action(reservation))
+		}
+
+		var total int64
+		for _, point := range counter.takePoints(true, 0) {
+			total += point.Count
+		}
+
+		assert.Equalf(t, test.expectedResult, results, "%s failed", test.name)
+		assert.Equalf(t, test.expectedCount, total, "%s failed", test.name)
+	}
+}
@@ -0,0 +1,56 @@
+package quantify
+
+import "sync/atomic"
+
+// adaptiveSampler decides which calls to Counter.Count are recorded, sampling
+// progressively fewer calls as the volume of counts increases. Sampled calls are
+// weighted by the sample rate so that recorded totals remain an estimate of the
+// true total.
+type adaptiveSampler struct {
+
+	// threshold is the number of counts per interval that are recorded at full
+	// fidelity, above which sampling begins.
+	threshold int64
+
+	// rate is the current sample rate, where 1 in every rate calls is recorded.
+	rate int64
+
+	// calls is the number of calls made whilst sampling.
+	calls int64
+}
+
+func newAdaptiveSampler(threshold int64) *adaptiveSampler {
+	return &adaptiveSampler{
+		threshold: threshold,
+		rate:      1,
+	}
+}
+
+// sample returns whether the current call should be recorded, and if so, the
+// weight that it should be recorded with.
+func (as *adaptiveSampler) sample() (int64, bool) {
+
+	rate := atomic.LoadInt64(&as.rate)
+	if rate <= 1 {
+		return 1, true
+	}
+
+	if atomic.AddInt64(&as.calls, 1)%rate != 0 {
+		return 0, false
+	}
+
+	return rate, true
+}
+
+// adjust sets the sample rate based on the (estimated) count of the most recently
+// completed interval. The rate is the smallest power of 2 that brings the number
+// of recorded calls per interval within the threshold.
+func (as *adaptiveSampler) adjust(count int64) {
+
+	rate := int64(1)
+	for count/rate > as.threshold {
+		rate *= 2
+	}
+
+	atomic.StoreInt64(&as.rate, rate)
+}
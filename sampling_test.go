@@ -0,0 +1,82 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveSampler_adjust(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		count        int64
+		expectedRate int64
+	}{
+		{
+			name:         "below threshold",
+			count:        999,
+			expectedRate: 1,
+		},
+		{
+			name:         "at threshold",
+			count:        1000,
+			expectedRate: 1,
+		},
+		{
+			name:         "just above threshold",
+			count:        1001,
+			expectedRate: 2,
+		},
+		{
+			name:         "far above threshold",
+			count:        1000000,
+			expectedRate: 1024,
+		},
+	}
+
+	for _, test := range tests {
+
+		sampler := newAdaptiveSampler(1000)
+		sampler.adjust(test.count)
+
+		assert.Equalf(t, test.expectedRate, sampler.rate, "%s failed", test.name)
+	}
+}
+
+func TestCounter_Count_sampled(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	counter := &Counter{
+		clock:    mockClock,
+		interval: 10,
+		counts:   &sync.Map{},
+		mu:       &sync.Mutex{},
+		sampler:  newAdaptiveSampler(100),
+	}
+
+	// first interval is recorded in full, raising the sample rate
+	for i := 0; i < 1000; i++ {
+		counter.Count()
+	}
+	mockClock.Add(time.Second * 10)
+
+	points := counter.takePoints(false, 0)
+	assert.Equal(t, int64(1000), points[0].Count)
+	assert.Equal(t, int64(16), counter.sampler.rate)
+
+	// second interval is sampled, but corrected
+	for i := 0; i < 1600; i++ {
+		counter.Count()
+	}
+	mockClock.Add(time.Second * 10)
+
+	points = counter.takePoints(false, 0)
+	assert.Equal(t, int64(1600), points[0].Count)
+}
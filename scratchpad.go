@@ -0,0 +1,94 @@
+package quantify
+
+import (
+	"context"
+	"sync"
+)
+
+// scratchpadKey is the context key that a scratchpad is stored under.
+type scratchpadKey struct{}
+
+// scratchpad accumulates counts for a single request, which are only applied to
+// their counters once the request commits.
+type scratchpad struct {
+	mu     *sync.Mutex
+	counts map[*Counter]int64
+	done   bool
+}
+
+// WithContext returns a copy of the provided context carrying a scratchpad, on
+// which counts made with Counter.CountContext are accumulated rather than being
+// counted immediately. The accumulated counts are applied when Commit is called
+// with the context, or discarded when Discard is called, supporting "only count if
+// the transaction commits" semantics.
+//
+// Counts are attributed to the interval in which they are committed.
+func (q *Quantifier) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scratchpadKey{}, &scratchpad{
+		mu:     &sync.Mutex{},
+		counts: make(map[*Counter]int64),
+	})
+}
+
+// CountContext adds 1 to the running total of this Counter, deferring the count
+// until Commit if the provided context carries a scratchpad (see
+// Quantifier.WithContext). Otherwise, it is counted immediately, as with Count.
+func (c *Counter) CountContext(ctx context.Context) {
+
+	sp, ok := ctx.Value(scratchpadKey{}).(*scratchpad)
+	if !ok {
+		c.Count()
+		return
+	}
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	// counts made after the scratchpad is committed or discarded are dropped
+	if sp.done {
+		return
+	}
+
+	sp.counts[c]++
+}
+
+// Commit applies the counts accumulated on the scratchpad of the provided context
+// to their counters. Commit has no effect if the context doesn't carry a
+// scratchpad, or if it has already been committed or discarded.
+func Commit(ctx context.Context) {
+
+	sp, ok := ctx.Value(scratchpadKey{}).(*scratchpad)
+	if !ok {
+		return
+	}
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if sp.done {
+		return
+	}
+	sp.done = true
+
+	for counter, n := range sp.counts {
+		counter.add(counter.clock.Now(), n)
+	}
+	sp.counts = nil
+}
+
+// Discard drops the counts accumulated on the scratchpad of the provided context,
+// for example when the request fails. Discard has no effect if the context doesn't
+// carry a scratchpad, or if it has already been committed or discarded.
+func Discard(ctx context.Context) {
+
+	sp, ok := ctx.Value(scratchpadKey{}).(*scratchpad)
+	if !ok {
+		return
+	}
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	sp.done = true
+	sp.counts = nil
+}
@@ -0,0 +1,80 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScratchpad(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		withContext   bool
+		finish        func(ctx context.Context)
+		expectedCount int64
+	}{
+		{
+			name:          "committed",
+			withContext:   true,
+			finish:        Commit,
+			expectedCount: 2,
+		},
+		{
+			name:          "discarded",
+			withContext:   true,
+			finish:        Discard,
+			expectedCount: 0,
+		},
+		{
+			name:          "committed after discard",
+			withContext:   true,
+			finish:        func(ctx context.Context) { Discard(ctx); Commit(ctx) },
+			expectedCount: 0,
+		},
+		{
+			name:          "without scratchpad",
+			withContext:   false,
+			finish:        Discard,
+			expectedCount: 3,
+		},
+	}
+
+	for _, test := range tests {
+
+		mockClock := newMockClock()
+		mockClock.Set(time.Unix(1670681770, 0))
+
+		q := &Quantifier{}
+		counter := &Counter{
+			clock:    mockClock,
+			interval: 10,
+			counts:   &sync.Map{},
+			mu:       &sync.Mutex{},
+		}
+
+		ctx := context.Background()
+		if test.withContext {
+			ctx = q.WithContext(ctx)
+		}
+
+		counter.CountContext(ctx)
+		counter.CountContext(ctx)
+		test.finish(ctx)
+
+		// counts after finishing are dropped when using a scratchpad
+		counter.CountContext(ctx)
+
+		var total int64
+		for _, point := range counter.takePoints(true, 0) {
+			total += point.Count
+		}
+
+		assert.Equalf(t, test.expectedCount, total, "%s failed", test.name)
+	}
+}
@@ -0,0 +1,311 @@
+package quantify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxFlushAttempts is the number of consecutive flushes that buffered series
+	// are exported with before being dropped, so that series the underlying
+	// exporter will never accept aren't retried forever.
+	maxFlushAttempts = 3
+)
+
+// SharedExporter implements Exporter, allowing multiple Quantifiers (e.g. one per
+// library scope) to share a single underlying Exporter. Series exported by each
+// Quantifier are buffered and periodically forwarded to the underlying Exporter
+// in a single Export call, coalescing them into joint requests.
+//
+// As Export only buffers series, the Quantifiers sharing the exporter never see
+// the errors of the underlying exporter, which aren't covered by their own retries
+// or checkpoints. Instead, series that fail to export are buffered again, and
+// retried with the next flushes, until they've been attempted maxFlushAttempts (3)
+// times, when they're dropped and reported to the error handler.
+//
+// Quantifiers sharing the exporter should be stopped before the SharedExporter,
+// so their final series are included in its last flush.
+type SharedExporter struct {
+	exporter     Exporter
+	clock        Clock
+	interval     time.Duration
+	errorHandler func(error)
+
+	mu      *sync.Mutex
+	pending map[string]*Series
+
+	// attempts is the number of consecutive flushes that have failed to export
+	// the pending series.
+	attempts int
+
+	stopOnce *sync.Once
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+// NewSharedExporter returns a SharedExporter that forwards buffered series to the
+// provided exporter at the provided interval until Stop is called or ctx is
+// cancelled.
+//
+// errorHandler is called with any error returned by the underlying exporter, and
+// may be nil if errors should be ignored.
+func NewSharedExporter(ctx context.Context, exporter Exporter, interval time.Duration, errorHandler func(error)) *SharedExporter {
+	return newSharedExporter(ctx, exporter, interval, errorHandler, systemClock{})
+}
+
+func newSharedExporter(ctx context.Context, exporter Exporter, interval time.Duration, errorHandler func(error), clock Clock) *SharedExporter {
+
+	if errorHandler == nil {
+		errorHandler = func(error) {}
+	}
+
+	se := &SharedExporter{
+		exporter:     exporter,
+		clock:        clock,
+		interval:     interval,
+		errorHandler: errorHandler,
+		mu:           &sync.Mutex{},
+		pending:      make(map[string]*Series),
+		stopOnce:     &sync.Once{},
+		stop:         make(chan struct{}),
+		stopped:      make(chan struct{}),
+	}
+
+	go se.run(ctx, se.clock.NewTicker(interval))
+
+	return se
+}
+
+// Export implements Exporter, buffering the provided series until the next flush.
+// Series for the same metric (by name and labels) are merged.
+func (se *SharedExporter) Export(ctx context.Context, series []*Series) error {
+
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	se.buffer(series)
+
+	return nil
+}
+
+// buffer merges the provided series into the pending series. The caller must hold
+// mu.
+func (se *SharedExporter) buffer(series []*Series) {
+
+	for _, s := range series {
+
+		key := metricKey(s.Metric)
+
+		existing, ok := se.pending[key]
+		if !ok {
+			se.pending[key] = &Series{
+				Metric: s.Metric,
+				Points: append([]*Point{}, s.Points...),
+			}
+			continue
+		}
+
+		existing.Points = mergePoints(existing.Points, s.Points)
+	}
+}
+
+// ValidateMetric implements MetricValidator, delegating to the underlying
+// exporter if it implements MetricValidator.
+func (se *SharedExporter) ValidateMetric(metric *Metric) error {
+
+	if validator, ok := se.exporter.(MetricValidator); ok {
+		return validator.ValidateMetric(metric)
+	}
+
+	return nil
+}
+
+// MinWriteInterval implements WriteIntervalLimiter, delegating to the underlying
+// exporter if it implements WriteIntervalLimiter.
+func (se *SharedExporter) MinWriteInterval() time.Duration {
+	return minWriteInterval(se.exporter)
+}
+
+// LengthLimits implements LengthLimiter, delegating to the underlying exporter if
+// it implements LengthLimiter, so that names and labels exported through the
+// SharedExporter are truncated to the limits of its backend (see
+// OptionWithTruncation).
+func (se *SharedExporter) LengthLimits() LengthLimits {
+
+	if limiter, ok := se.exporter.(LengthLimiter); ok {
+		return limiter.LengthLimits()
+	}
+
+	return LengthLimits{}
+}
+
+// SumsCounts implements CountSummer, delegating to the underlying exporter if it
+// implements CountSummer.
+func (se *SharedExporter) SumsCounts() bool {
+	return sumsCounts(se.exporter)
+}
+
+// ProjectPath implements ProjectScoped, delegating to the underlying exporter if it
+// implements ProjectScoped.
+func (se *SharedExporter) ProjectPath() string {
+
+	if scoped, ok := se.exporter.(ProjectScoped); ok {
+		return scoped.ProjectPath()
+	}
+
+	return ""
+}
+
+// Stop ceases the periodic flushing of the SharedExporter, and forwards any
+// remaining buffered series to the underlying exporter. If that final export
+// fails, the series are lost.
+func (se *SharedExporter) Stop() {
+
+	se.stopOnce.Do(func() {
+		close(se.stop)
+	})
+
+	<-se.stopped
+
+	se.flush(context.Background())
+}
+
+// run flushes buffered series each time the provided Ticker ticks until the
+// SharedExporter is stopped or ctx is cancelled.
+func (se *SharedExporter) run(ctx context.Context, t Ticker) {
+
+	defer close(se.stopped)
+	defer t.Stop()
+
+	for {
+		select {
+
+		case <-t.C():
+			se.flush(ctx)
+
+		case <-ctx.Done():
+			return
+
+		case <-se.stop:
+			return
+
+		}
+	}
+}
+
+// flush forwards all buffered series to the underlying exporter. If the export
+// fails, the series are buffered again to be retried with the next flush, unless
+// they've been attempted maxFlushAttempts times.
+func (se *SharedExporter) flush(ctx context.Context) {
+
+	se.mu.Lock()
+	pending := se.pending
+	se.pending = make(map[string]*Series)
+	se.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+
+	series := make([]*Series, 0, len(pending))
+	for _, s := range pending {
+		series = append(series, s)
+	}
+	SortSeries(series)
+
+	err := se.exporter.Export(ctx, series)
+
+	se.mu.Lock()
+
+	if err == nil {
+		se.attempts = 0
+		se.mu.Unlock()
+		return
+	}
+
+	se.attempts++
+
+	if se.attempts >= maxFlushAttempts {
+		se.attempts = 0
+		err = fmt.Errorf("dropped %d series after %d failed flushes: %w", len(series), maxFlushAttempts, err)
+	} else {
+		se.buffer(series)
+	}
+
+	se.mu.Unlock()
+
+	se.errorHandler(err)
+}
+
+// metricKey returns a string uniquely identifying the provided Metric by its name
+// and labels.
+func metricKey(metric *Metric) string {
+
+	keys := make([]string, 0, len(metric.Labels))
+	for key := range metric.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	builder := &strings.Builder{}
+	builder.WriteString(metric.Name)
+
+	for _, key := range keys {
+		builder.WriteString("\x00")
+		builder.WriteString(key)
+		builder.WriteString("=")
+		builder.WriteString(metric.Labels[key])
+	}
+
+	return builder.String()
+}
+
+// mergePoints combines two sets of points ordered by start time, summing the
+// counts (and values) of any points that share the same interval, and merging
+// their distributions. Points that share a start time but not an end time (e.g.
+// cumulative totals) are kept separate.
+func mergePoints(a, b []*Point) []*Point {
+
+	byInterval := make(map[[2]int64]*Point)
+
+	for _, points := range [][]*Point{a, b} {
+		for _, point := range points {
+
+			interval := [2]int64{point.Start.UnixNano(), point.End.UnixNano()}
+
+			existing, ok := byInterval[interval]
+			if !ok {
+				byInterval[interval] = &Point{
+					Start:        point.Start,
+					End:          point.End,
+					Count:        point.Count,
+					Value:        point.Value,
+					Distribution: mergeDistributions(point.Distribution, nil),
+				}
+				continue
+			}
+
+			existing.Count += point.Count
+			existing.Value += point.Value
+			existing.Distribution = mergeDistributions(existing.Distribution, point.Distribution)
+		}
+	}
+
+	merged := make([]*Point, 0, len(byInterval))
+	for _, point := range byInterval {
+		merged = append(merged, point)
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Start.Equal(merged[j].Start) {
+			return merged[i].End.Before(merged[j].End)
+		}
+		return merged[i].Start.Before(merged[j].Start)
+	})
+
+	return merged
+}
@@ -0,0 +1,241 @@
+package quantify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedExporter(t *testing.T) {
+
+	mockClock := newMockClock()
+	underlying := &mockExporter{}
+	errs := make(chan error, 1)
+
+	shared := newSharedExporter(context.Background(), underlying, time.Minute, func(err error) {
+		errs <- err
+	}, mockClock)
+
+	start := time.Unix(1670681770, 0)
+	point := func(offset time.Duration, count int64) *Point {
+		return &Point{
+			Start: start.Add(offset),
+			End:   start.Add(offset + time.Second*10),
+			Count: count,
+		}
+	}
+
+	// exports from two separate quantifiers
+	assert.NoError(t, shared.Export(context.Background(), []*Series{
+		{
+			Metric: &Metric{Name: "planes", Labels: map[string]string{"model": "737-800"}},
+			Points: []*Point{point(0, 2), point(time.Second*10, 3)},
+		},
+	}))
+	assert.NoError(t, shared.Export(context.Background(), []*Series{
+		{
+			Metric: &Metric{Name: "planes", Labels: map[string]string{"model": "737-800"}},
+			Points: []*Point{point(time.Second*10, 4)},
+		},
+		{
+			Metric: &Metric{Name: "trains", Labels: map[string]string{}},
+			Points: []*Point{point(0, 1)},
+		},
+	}))
+
+	// nothing forwarded until flush
+	assert.Len(t, underlying.series, 0)
+
+	underlying.exportErr = errors.New("export failed")
+	mockClock.Add(time.Minute)
+	assert.Equal(t, errors.New("export failed"), <-errs)
+
+	// failed series are retried with the next flush
+	underlying.exportErr = nil
+	underlying.series = nil
+	shared.Stop()
+
+	underlying.mu.Lock()
+	defer underlying.mu.Unlock()
+
+	// identical metrics are merged into a single series
+	assert.Len(t, underlying.series, 2)
+	for _, s := range underlying.series {
+		if s.Metric.Name == "planes" {
+			assert.Equal(t, []*Point{point(0, 2), point(time.Second*10, 7)}, s.Points)
+		}
+	}
+}
+
+func TestSharedExporter_flushAttempts(t *testing.T) {
+
+	mockClock := newMockClock()
+	underlying := &mockExporter{exportErr: errors.New("export failed")}
+	errs := make(chan error, maxFlushAttempts)
+
+	shared := newSharedExporter(context.Background(), underlying, time.Minute, func(err error) {
+		errs <- err
+	}, mockClock)
+	defer shared.Stop()
+
+	assert.NoError(t, shared.Export(context.Background(), []*Series{
+		{
+			Metric: &Metric{Name: "planes"},
+			Points: []*Point{{Start: time.Unix(1670681770, 0), End: time.Unix(1670681780, 0), Count: 1}},
+		},
+	}))
+
+	for i := 1; i < maxFlushAttempts; i++ {
+		mockClock.Add(time.Minute)
+		assert.Equal(t, errors.New("export failed"), <-errs)
+	}
+
+	shared.mu.Lock()
+	assert.Len(t, shared.pending, 1)
+	shared.mu.Unlock()
+
+	// series are dropped once their attempts are exhausted
+	mockClock.Add(time.Minute)
+	assert.EqualError(t, <-errs, "dropped 1 series after 3 failed flushes: export failed")
+
+	shared.mu.Lock()
+	assert.Len(t, shared.pending, 0)
+	shared.mu.Unlock()
+
+	underlying.mu.Lock()
+	assert.Len(t, underlying.series, maxFlushAttempts)
+	underlying.mu.Unlock()
+}
+
+func TestSharedExporter_LengthLimits(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		exporter Exporter
+		expected LengthLimits
+	}{
+		{
+			name:     "limited",
+			exporter: &limitedLengthExporter{limits: LengthLimits{Name: 16, LabelKey: 12, LabelValue: 12}},
+			expected: LengthLimits{Name: 16, LabelKey: 12, LabelValue: 12},
+		},
+		{
+			name:     "unlimited",
+			exporter: &mockExporter{},
+			expected: LengthLimits{},
+		},
+	}
+
+	for _, test := range tests {
+
+		shared := newSharedExporter(context.Background(), test.exporter, time.Minute, nil, newMockClock())
+
+		assert.Equal(t, test.expected, shared.LengthLimits(), "%s failed", test.name)
+
+		shared.Stop()
+	}
+}
+
+func TestMetricKey(t *testing.T) {
+
+	a := metricKey(&Metric{Name: "planes", Labels: map[string]string{"a": "1", "b": "2"}})
+	b := metricKey(&Metric{Name: "planes", Labels: map[string]string{"b": "2", "a": "1"}})
+	c := metricKey(&Metric{Name: "planes", Labels: map[string]string{"a": "12"}})
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
+
+func TestMergePoints(t *testing.T) {
+
+	a := []*Point{
+		{Start: time.Unix(0, 0), End: time.Unix(10, 0), Count: 1},
+		{Start: time.Unix(0, 0), End: time.Unix(20, 0), Count: 4},
+	}
+	b := []*Point{
+		{Start: time.Unix(0, 0), End: time.Unix(10, 0), Count: 2},
+		{Start: time.Unix(10, 0), End: time.Unix(20, 0), Count: 3},
+	}
+
+	// points are only summed when their intervals match
+	assert.Equal(t, []*Point{
+		{Start: time.Unix(0, 0), End: time.Unix(10, 0), Count: 3},
+		{Start: time.Unix(0, 0), End: time.Unix(20, 0), Count: 4},
+		{Start: time.Unix(10, 0), End: time.Unix(20, 0), Count: 3},
+	}, mergePoints(a, b))
+}
+
+func TestMergePoints_distributions(t *testing.T) {
+
+	buckets := ExplicitBuckets(2, 4)
+
+	// observations of 1 and 3, and of 5
+	a := []*Point{
+		{
+			Start: time.Unix(0, 0),
+			End:   time.Unix(10, 0),
+			Distribution: &DistributionValue{
+				Count: 2, Mean: 2, SumOfSquaredDeviation: 2, Min: 1, Max: 3,
+				Buckets: buckets, BucketCounts: []int64{1, 1, 0},
+			},
+		},
+	}
+	b := []*Point{
+		{
+			Start: time.Unix(0, 0),
+			End:   time.Unix(10, 0),
+			Distribution: &DistributionValue{
+				Count: 1, Mean: 5, Min: 5, Max: 5,
+				Buckets: buckets, BucketCounts: []int64{0, 0, 1},
+			},
+		},
+		{
+			Start:        time.Unix(10, 0),
+			End:          time.Unix(20, 0),
+			Distribution: &DistributionValue{Buckets: buckets, BucketCounts: []int64{0, 0, 0}},
+		},
+	}
+
+	// merged as if 1, 3 and 5 had been recorded together
+	assert.Equal(t, []*Point{
+		{
+			Start: time.Unix(0, 0),
+			End:   time.Unix(10, 0),
+			Distribution: &DistributionValue{
+				Count: 3, Mean: 3, SumOfSquaredDeviation: 8, Min: 1, Max: 5,
+				Buckets: buckets, BucketCounts: []int64{1, 1, 1},
+			},
+		},
+		b[1],
+	}, mergePoints(a, b))
+
+	// without modifying the points merged
+	assert.Equal(t, []int64{1, 1, 0}, a[0].Distribution.BucketCounts)
+	assert.Equal(t, int64(2), a[0].Distribution.Count)
+}
+
+func TestMergeDistributions(t *testing.T) {
+
+	value := &DistributionValue{Count: 1, Mean: 5, Min: 5, Max: 5, BucketCounts: []int64{0, 1}}
+	empty := &DistributionValue{BucketCounts: []int64{0, 0}}
+
+	tests := []struct {
+		name     string
+		a        *DistributionValue
+		b        *DistributionValue
+		expected *DistributionValue
+	}{
+		{name: "both nil", a: nil, b: nil, expected: nil},
+		{name: "first nil", a: nil, b: value, expected: value},
+		{name: "second nil", a: value, b: nil, expected: value},
+		{name: "first empty", a: empty, b: value, expected: value},
+		{name: "second empty", a: value, b: empty, expected: value},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, mergeDistributions(test.a, test.b), "%s failed", test.name)
+	}
+}
@@ -0,0 +1,40 @@
+package quantify
+
+// MetricOptionWithShutdownValue sets a value that a gauge publishes with the final
+// report, made when the Quantifier is stopped, in place of its current value (e.g.
+// 0 for the number of requests in flight, or for a gauge set to 1 whilst the
+// instance is up). Dashboards then show a definitive end of life for the instance,
+// rather than its data just stopping.
+//
+// The value replaces the points of any gauge that publishes with the final report,
+// including those of a Gauge, UpDownCounter or ObservableGauge, so a Gauge must have
+// been set at least once. The option has no effect on instruments that aren't
+// gauges, such as counters.
+func MetricOptionWithShutdownValue(value float64) MetricOption {
+	return func(metric *Metric) {
+		metric.shutdownValue = &value
+	}
+}
+
+// applyShutdownValues replaces the values of the points of gauges that have a
+// shutdown value (see MetricOptionWithShutdownValue).
+func applyShutdownValues(series []*Series) {
+
+	for _, s := range series {
+
+		if s.Metric.Kind != MetricKindGauge || s.Metric.shutdownValue == nil {
+			continue
+		}
+
+		value := *s.Metric.shutdownValue
+
+		for _, point := range s.Points {
+			switch s.Metric.ValueType {
+			case ValueTypeInt64:
+				point.Count = int64(value)
+			case ValueTypeDouble:
+				point.Value = value
+			}
+		}
+	}
+}
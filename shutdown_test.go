@@ -0,0 +1,74 @@
+package quantify
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricOptionWithShutdownValue(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		mu:           &sync.Mutex{},
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	up, _ := client.CreateGauge("up", nil, GaugeAggregationLast, MetricOptionWithShutdownValue(0))
+	inFlight, _ := client.CreateUpDownCounter("requests_in_flight", nil, MetricOptionWithShutdownValue(0))
+	depth, _ := client.CreateGauge("queue_depth", nil, GaugeAggregationLast)
+
+	up.Set(1)
+	inFlight.Add(3)
+	depth.Set(7)
+
+	// current values are published until the final report
+	client.report(false)
+
+	assert.Equal(t, []*Series{
+		{Metric: up.metric, Points: []*Point{{Start: time.Unix(1670681770, 0), End: time.Unix(1670681770, 0), Value: 1}}},
+		{Metric: inFlight.metric, Points: []*Point{{Start: time.Unix(1670681770, 0), End: time.Unix(1670681770, 0), Count: 3}}},
+		{Metric: depth.metric, Points: []*Point{{Start: time.Unix(1670681770, 0), End: time.Unix(1670681770, 0), Value: 7}}},
+	}, exporter.series)
+
+	mockClock.Add(time.Second * 10)
+	client.Stop()
+
+	assert.Equal(t, []*Series{
+		{Metric: up.metric, Points: []*Point{{Start: time.Unix(1670681780, 0), End: time.Unix(1670681780, 0), Value: 0}}},
+		{Metric: inFlight.metric, Points: []*Point{{Start: time.Unix(1670681780, 0), End: time.Unix(1670681780, 0), Count: 0}}},
+		{Metric: depth.metric, Points: []*Point{{Start: time.Unix(1670681780, 0), End: time.Unix(1670681780, 0), Value: 7}}},
+	}, exporter.series[3:])
+
+	// the instruments themselves are unaffected
+	assert.Equal(t, int64(3), inFlight.Value())
+}
+
+func TestApplyShutdownValues(t *testing.T) {
+
+	shutdown := MetricOptionWithShutdownValue(2.5)
+
+	gauge := &Metric{Name: "up", Kind: MetricKindGauge, ValueType: ValueTypeDouble}
+	shutdown(gauge)
+
+	counter := &Metric{Name: "planes", Kind: MetricKindCumulative, ValueType: ValueTypeInt64}
+	shutdown(counter)
+
+	series := []*Series{
+		{Metric: gauge, Points: []*Point{{Value: 1}}},
+		{Metric: counter, Points: []*Point{{Count: 4}}},
+	}
+
+	applyShutdownValues(series)
+
+	assert.Equal(t, 2.5, series[0].Points[0].Value)
+	assert.Equal(t, int64(4), series[1].Points[0].Count)
+}
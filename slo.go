@@ -0,0 +1,233 @@
+package quantify
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	burnRateLabelKeyWindow = "window"
+)
+
+var (
+	// defaultBurnRateWindows are the windows used for the standard multi-window,
+	// multi-burn-rate, SLO alerting pattern.
+	defaultBurnRateWindows = []time.Duration{
+		5 * time.Minute,
+		time.Hour,
+		6 * time.Hour,
+	}
+
+	ErrInvalidTarget = errors.New("target must be between 0 and 1 (exclusive)")
+)
+
+// BurnRate is an instrument that derives the error budget burn rate of an SLO from a
+// pair of counters, one counting good events and the other counting all events.
+//
+// A burn rate of 1 means the error budget is being consumed at exactly the rate
+// that would exhaust it at the end of the SLO period, with higher values consuming
+// it proportionally faster. The burn rate over each window is published as a
+// gauge, labelled with the window (e.g. window="5m").
+type BurnRate struct {
+	mu      *sync.Mutex
+	metric  *Metric
+	target  float64
+	windows []time.Duration
+
+	// good and total track the counts observed from each counter, keyed by the
+	// point's start time (unix seconds).
+	good  map[int64]int64
+	total map[int64]int64
+}
+
+// CreateBurnRate creates a BurnRate that derives the burn rate of an SLO with the
+// provided target (e.g. 0.999 for 99.9%) from the provided counters, publishing it
+// at the default windows of 5m, 1h and 6h.
+//
+// good and total must have been created by this Quantifier.
+func (q *Quantifier) CreateBurnRate(name string, labels map[string]string, good *Counter, total *Counter, target float64, options ...MetricOption) (*BurnRate, error) {
+
+	instrument, err := q.createBurnRate(name, labels, good, total, target, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopBurnRate(), nil
+	}
+
+	return instrument, err
+}
+
+// createBurnRate creates a BurnRate (see CreateBurnRate).
+func (q *Quantifier) createBurnRate(name string, labels map[string]string, good *Counter, total *Counter, target float64, options ...MetricOption) (*BurnRate, error) {
+
+	if target <= 0 || target >= 1 {
+		return nil, ErrInvalidTarget
+	}
+
+	goodCounter := q.findCounter(good)
+	totalCounter := q.findCounter(total)
+	if goodCounter == nil || totalCounter == nil {
+		return nil, ErrUnknownCounter
+	}
+
+	metric := &Metric{
+		Name:      name,
+		Labels:    q.mergeCommonLabels(labels),
+		Kind:      MetricKindGauge,
+		ValueType: ValueTypeDouble,
+	}
+
+	for _, option := range options {
+		option(metric)
+	}
+
+	// validate including the window label that will be added on publishing
+	err := q.validateMetric(withLabel(metric, burnRateLabelKeyWindow, formatWindow(defaultBurnRateWindows[0])))
+	if err != nil {
+		return nil, err
+	}
+
+	br := &BurnRate{
+		mu:      &sync.Mutex{},
+		metric:  metric,
+		target:  target,
+		windows: defaultBurnRateWindows,
+		good:    make(map[int64]int64),
+		total:   make(map[int64]int64),
+	}
+
+	goodCounter.observers = append(goodCounter.observers, br.observer(br.good))
+	totalCounter.observers = append(totalCounter.observers, br.observer(br.total))
+
+	q.collectors = append(q.collectors, br)
+
+	return br, nil
+}
+
+// findCounter returns the metricCounter of the provided Counter, or nil if the
+// Counter wasn't created by this Quantifier.
+func (q *Quantifier) findCounter(counter *Counter) *metricCounter {
+
+	for _, mc := range q.registeredCounters() {
+		if mc.counter == counter {
+			return mc
+		}
+	}
+
+	return nil
+}
+
+// observer returns a function that records observed points within history.
+func (br *BurnRate) observer(history map[int64]int64) func([]*Point) {
+	return func(points []*Point) {
+		br.mu.Lock()
+		defer br.mu.Unlock()
+
+		for _, point := range points {
+			history[point.Start.Unix()] += point.Count
+		}
+	}
+}
+
+// collect implements collector, publishing the burn rate over each window.
+func (br *BurnRate) collect(now time.Time) []*Series {
+
+	br.mu.Lock()
+	defer br.mu.Unlock()
+
+	br.prune(now)
+
+	series := make([]*Series, 0, len(br.windows))
+
+	for _, window := range br.windows {
+		series = append(series, &Series{
+			Metric: withLabel(br.metric, burnRateLabelKeyWindow, formatWindow(window)),
+			Points: []*Point{
+				{
+					Start: now,
+					End:   now,
+					Value: br.burnRate(now, window),
+				},
+			},
+		})
+	}
+
+	return series
+}
+
+// burnRate returns the burn rate over the window preceding now.
+func (br *BurnRate) burnRate(now time.Time, window time.Duration) float64 {
+
+	from := now.Add(-window).Unix()
+
+	var good, total int64
+
+	for start, count := range br.good {
+		if start >= from {
+			good += count
+		}
+	}
+
+	for start, count := range br.total {
+		if start >= from {
+			total += count
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	errorRate := float64(total-good) / float64(total)
+
+	return errorRate / (1 - br.target)
+}
+
+// prune removes any history older than the largest window.
+func (br *BurnRate) prune(now time.Time) {
+
+	var largest time.Duration
+	for _, window := range br.windows {
+		if window > largest {
+			largest = window
+		}
+	}
+
+	from := now.Add(-largest).Unix()
+
+	for _, history := range []map[int64]int64{br.good, br.total} {
+		for start := range history {
+			if start < from {
+				delete(history, start)
+			}
+		}
+	}
+}
+
+// withLabel returns a copy of the provided metric with an additional label.
+func withLabel(metric *Metric, key string, value string) *Metric {
+
+	labels := make(map[string]string, len(metric.Labels)+1)
+	for k, v := range metric.Labels {
+		labels[k] = v
+	}
+	labels[key] = value
+
+	copied := *metric
+	copied.Labels = labels
+
+	return &copied
+}
+
+// formatWindow formats a window duration in its shortest form, e.g. "5m" or "6h".
+func formatWindow(window time.Duration) string {
+
+	switch {
+	case window%time.Hour == 0:
+		return fmt.Sprintf("%dh", window/time.Hour)
+	case window%time.Minute == 0:
+		return fmt.Sprintf("%dm", window/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", window/time.Second)
+	}
+}
@@ -0,0 +1,67 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_CreateBurnRate(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670680800, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	good, _ := client.CreateCounter("requests_good", nil, 60)
+	total, _ := client.CreateCounter("requests_total", nil, 60)
+
+	_, err := client.CreateBurnRate("requests_burn_rate", nil, good, total, 1.5)
+	assert.Equal(t, ErrInvalidTarget, err)
+
+	_, err = client.CreateBurnRate("requests_burn_rate", nil, good, &Counter{}, 0.99)
+	assert.Equal(t, ErrUnknownCounter, err)
+
+	_, err = client.CreateBurnRate("requests_burn_rate", nil, good, total, 0.99)
+	assert.NoError(t, err)
+
+	// an hour ago, 100 requests with 10 failures
+	assert.NoError(t, good.AddAt(mockClock.Now(), 90))
+	assert.NoError(t, total.AddAt(mockClock.Now(), 100))
+
+	mockClock.Add(time.Hour - time.Minute)
+
+	// in the last minute, 100 requests with 1 failure
+	assert.NoError(t, good.AddAt(mockClock.Now(), 99))
+	assert.NoError(t, total.AddAt(mockClock.Now(), 100))
+
+	mockClock.Add(time.Minute)
+	client.report(false)
+
+	rates := make(map[string]float64)
+	for _, s := range exporter.series {
+		if s.Metric.Name == "requests_burn_rate" {
+			assert.Equal(t, MetricKindGauge, s.Metric.Kind)
+			rates[s.Metric.Labels["window"]] = s.Points[0].Value
+		}
+	}
+
+	assert.InDelta(t, 1.0, rates["5m"], 0.0001)
+	assert.InDelta(t, 5.5, rates["1h"], 0.0001)
+	assert.InDelta(t, 5.5, rates["6h"], 0.0001)
+}
+
+func TestFormatWindow(t *testing.T) {
+	assert.Equal(t, "5m", formatWindow(5*time.Minute))
+	assert.Equal(t, "6h", formatWindow(6*time.Hour))
+	assert.Equal(t, "90s", formatWindow(90*time.Second))
+}
@@ -0,0 +1,113 @@
+package quantify
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// latencyBounds are the upper bounds (inclusive) of the buckets used to record
+	// the latency of the library's hot path.
+	latencyBounds = []time.Duration{
+		25 * time.Nanosecond,
+		50 * time.Nanosecond,
+		100 * time.Nanosecond,
+		250 * time.Nanosecond,
+		500 * time.Nanosecond,
+		time.Microsecond,
+		2500 * time.Nanosecond,
+		5 * time.Microsecond,
+		10 * time.Microsecond,
+		100 * time.Microsecond,
+		time.Millisecond,
+	}
+)
+
+// Stats provides information about the internal operation of a Quantifier.
+type Stats struct {
+
+	// CountLatency is a histogram of the time taken by calls to Counter.Count. It
+	// is only populated when OptionWithSelfInstrumentation is used.
+	CountLatency *LatencyHistogram
+
+	// MemoryBytes is the approximate number of bytes held for counts, observations
+	// and series that are yet to be reported (see OptionWithMemoryLimit).
+	MemoryBytes int64
+}
+
+// LatencyHistogram is a snapshot of recorded latencies, bucketed by duration.
+type LatencyHistogram struct {
+
+	// Bounds are the upper bounds (inclusive) of each bucket.
+	Bounds []time.Duration
+
+	// Counts are the number of latencies recorded within each bucket. Counts has
+	// one more entry than Bounds, the last being for latencies greater than the
+	// final bound.
+	Counts []int64
+
+	// Total is the number of latencies recorded.
+	Total int64
+
+	// Sum is the sum of all recorded latencies.
+	Sum time.Duration
+}
+
+// Mean returns the mean of all recorded latencies.
+func (lh *LatencyHistogram) Mean() time.Duration {
+
+	if lh.Total == 0 {
+		return 0
+	}
+
+	return lh.Sum / time.Duration(lh.Total)
+}
+
+// latencyRecorder records latencies into a fixed set of buckets, safe for
+// concurrent use.
+type latencyRecorder struct {
+	counts []int64
+	total  int64
+	sum    int64
+}
+
+func newLatencyRecorder() *latencyRecorder {
+	return &latencyRecorder{
+		counts: make([]int64, len(latencyBounds)+1),
+	}
+}
+
+// record adds the time elapsed since start to the recorder.
+func (lr *latencyRecorder) record(start time.Time) {
+
+	elapsed := time.Since(start)
+
+	bucket := len(latencyBounds)
+	for i, bound := range latencyBounds {
+		if elapsed <= bound {
+			bucket = i
+			break
+		}
+	}
+
+	atomic.AddInt64(&lr.counts[bucket], 1)
+	atomic.AddInt64(&lr.total, 1)
+	atomic.AddInt64(&lr.sum, int64(elapsed))
+}
+
+// snapshot returns the current state of the recorder as a LatencyHistogram.
+func (lr *latencyRecorder) snapshot() *LatencyHistogram {
+
+	histogram := &LatencyHistogram{
+		Bounds: append([]time.Duration{}, latencyBounds...),
+		Counts: make([]int64, len(lr.counts)),
+		Total:  atomic.LoadInt64(&lr.total),
+		Sum:    time.Duration(atomic.LoadInt64(&lr.sum)),
+	}
+
+	for i := range lr.counts {
+		histogram.Counts[i] = atomic.LoadInt64(&lr.counts[i])
+	}
+
+	return histogram
+}
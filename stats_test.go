@@ -0,0 +1,46 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyRecorder(t *testing.T) {
+
+	recorder := newLatencyRecorder()
+
+	// a latency exceeding all bounds
+	recorder.record(time.Now().Add(time.Second * -1))
+
+	histogram := recorder.snapshot()
+
+	assert.Equal(t, int64(1), histogram.Total)
+	assert.Equal(t, int64(1), histogram.Counts[len(histogram.Counts)-1])
+	assert.Len(t, histogram.Counts, len(histogram.Bounds)+1)
+	assert.GreaterOrEqual(t, histogram.Mean(), time.Second)
+}
+
+func TestQuantifier_Stats(t *testing.T) {
+
+	client := &Quantifier{
+		clock: systemClock{},
+	}
+
+	// without instrumentation
+	assert.Nil(t, client.Stats().CountLatency)
+
+	assert.NoError(t, OptionWithSelfInstrumentation()(client))
+
+	counter, err := client.CreateCounter("planes", nil, 10)
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		counter.Count()
+	}
+
+	assert.Equal(t, int64(100), client.Stats().CountLatency.Total)
+}
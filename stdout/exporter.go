@@ -0,0 +1,142 @@
+// Package stdout provides a quantify.Exporter for local development, which
+// pretty-prints each exported series as JSON to stdout (or any io.Writer), so that
+// what would have been sent can be seen without contacting a backend such as
+// Google Cloud Monitoring:
+//
+//	{
+//	  "metric": "planes",
+//	  "labels": {
+//	    "manufacturer": "boeing"
+//	  },
+//	  "kind": "CUMULATIVE",
+//	  "value_type": "INT64",
+//	  "points": [
+//	    {
+//	      "start": "2023-01-02T21:02:20Z",
+//	      "end": "2023-01-02T21:02:30Z",
+//	      "count": 3
+//	    }
+//	  ]
+//	}
+package stdout
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+	"github.com/rustedturnip/quantify/internal/record"
+)
+
+const (
+	indent = "  "
+)
+
+// series is the JSON representation of a single exported series.
+type series struct {
+	Metric    string            `json:"metric"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Kind      string            `json:"kind"`
+	ValueType string            `json:"value_type"`
+	Unit      string            `json:"unit,omitempty"`
+	Points    []*point          `json:"points"`
+}
+
+// point is the JSON representation of a single point of a series.
+type point struct {
+	Start        time.Time            `json:"start"`
+	End          time.Time            `json:"end"`
+	Count        *int64               `json:"count,omitempty"`
+	Value        *float64             `json:"value,omitempty"`
+	Distribution *record.Distribution `json:"distribution,omitempty"`
+}
+
+// Exporter implements quantify.Exporter, pretty-printing series as JSON.
+type Exporter struct {
+	mu     *sync.Mutex
+	writer io.Writer
+}
+
+// New returns an instantiated Exporter that writes to stdout, or returns an error
+// if instantiation fails.
+//
+// options allow the user to provide custom configurations as a list of Options.
+// If any options are invalid, a quantify.ValidationErrors describing every
+// problem is returned.
+func New(options ...Option) (*Exporter, error) {
+
+	exporter := &Exporter{
+		mu:     &sync.Mutex{},
+		writer: os.Stdout,
+	}
+
+	// apply every option, so that all problems are reported together
+	var errs quantify.ValidationErrors
+
+	for _, option := range options {
+		err := option(exporter)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return exporter, nil
+}
+
+// Export implements quantify.Exporter, writing each of the provided series as an
+// indented JSON object, followed by a newline. Each series is written with a
+// single call to the io.Writer, so the output of concurrent exports isn't
+// interleaved.
+func (e *Exporter) Export(ctx context.Context, series []*quantify.Series) error {
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, s := range series {
+
+		encoded, err := json.MarshalIndent(fromSeries(s), "", indent)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.writer.Write(append(encoded, '\n'))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fromSeries returns the JSON representation of the provided series.
+func fromSeries(s *quantify.Series) *series {
+
+	encoded := &series{
+		Metric:    s.Metric.Name,
+		Labels:    s.Metric.Labels,
+		Kind:      s.Metric.Kind.String(),
+		ValueType: s.Metric.ValueType.String(),
+		Unit:      s.Metric.Unit,
+		Points:    make([]*point, 0, len(s.Points)),
+	}
+
+	for _, r := range record.FromSeries([]*quantify.Series{s}) {
+		encoded.Points = append(encoded.Points, &point{
+			Start:        r.Start,
+			End:          r.End,
+			Count:        r.Count,
+			Value:        r.Value,
+			Distribution: r.Distribution,
+		})
+	}
+
+	return encoded
+}
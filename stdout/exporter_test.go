@@ -0,0 +1,111 @@
+package stdout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestExporter_Export(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+
+	exporter, err := New(OptionWithWriter(buffer))
+	assert.NoError(t, err)
+
+	series := []*quantify.Series{
+		{
+			Metric: &quantify.Metric{
+				Name:   "planes",
+				Labels: map[string]string{"manufacturer": "boeing"},
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: 3,
+				},
+			},
+		},
+		{
+			Metric: &quantify.Metric{
+				Name:      "queue_depth",
+				Kind:      quantify.MetricKindGauge,
+				ValueType: quantify.ValueTypeDouble,
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693350, 0),
+					End:   time.Unix(1672693350, 0),
+					Value: 0,
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, exporter.Export(context.Background(), series))
+
+	assert.Equal(t, `{
+  "metric": "planes",
+  "labels": {
+    "manufacturer": "boeing"
+  },
+  "kind": "CUMULATIVE",
+  "value_type": "INT64",
+  "points": [
+    {
+      "start": "2023-01-02T21:02:20Z",
+      "end": "2023-01-02T21:02:30Z",
+      "count": 3
+    }
+  ]
+}
+{
+  "metric": "queue_depth",
+  "kind": "GAUGE",
+  "value_type": "DOUBLE",
+  "points": [
+    {
+      "start": "2023-01-02T21:02:30Z",
+      "end": "2023-01-02T21:02:30Z",
+      "value": 0
+    }
+  ]
+}
+`, buffer.String())
+}
+
+// failingWriter is an io.Writer that always fails.
+type failingWriter struct{}
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestExporter_Export_writeError(t *testing.T) {
+
+	exporter, err := New(OptionWithWriter(&failingWriter{}))
+	assert.NoError(t, err)
+
+	err = exporter.Export(context.Background(), []*quantify.Series{
+		{Metric: &quantify.Metric{Name: "planes"}, Points: []*quantify.Point{{Count: 1}}},
+	})
+
+	assert.EqualError(t, err, "disk full")
+}
+
+func TestNew(t *testing.T) {
+
+	exporter, err := New()
+	assert.NoError(t, err)
+	assert.NotNil(t, exporter.writer)
+
+	_, err = New(OptionWithWriter(nil))
+	assert.EqualError(t, err, "writer: can't be nil")
+}
@@ -0,0 +1,23 @@
+package stdout
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rustedturnip/quantify/internal/golden"
+)
+
+func TestExporter_golden(t *testing.T) {
+
+	encoded := make([]*series, 0)
+	for _, s := range golden.Series() {
+		encoded = append(encoded, fromSeries(s))
+	}
+
+	payload, err := json.Marshal(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden.Assert(t, "series", payload)
+}
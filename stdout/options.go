@@ -0,0 +1,24 @@
+package stdout
+
+import (
+	"errors"
+	"io"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Option defines a function for supplying the Exporter constructor with certain
+// configurations.
+type Option func(*Exporter) error
+
+// OptionWithWriter sets the io.Writer that series are written to, which defaults
+// to os.Stdout, e.g. to write to os.Stderr instead.
+func OptionWithWriter(writer io.Writer) Option {
+	return func(exporter *Exporter) error {
+		if writer == nil {
+			return &quantify.FieldError{Path: "writer", Err: errors.New("can't be nil")}
+		}
+		exporter.writer = writer
+		return nil
+	}
+}
@@ -0,0 +1,81 @@
+package quantify
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// stripes spreads the counts of a Counter across a fixed number of maps, each
+// mapping interval keys to counts, so that goroutines on different processors
+// rarely count into the same memory.
+type stripes struct {
+	stripes []*sync.Map
+
+	// tokens hold stripe indexes. As sync.Pool caches objects per processor (P),
+	// a goroutine tends to get the index last used on the same processor. Tokens
+	// dropped by the pool are simply replaced, as counts are held by the stripes.
+	tokens *sync.Pool
+	next   uint32
+}
+
+// newStripes returns stripes with the provided number of stripes (at least 1).
+func newStripes(n int) *stripes {
+
+	if n < 1 {
+		n = 1
+	}
+
+	s := &stripes{
+		stripes: make([]*sync.Map, n),
+	}
+
+	for i := range s.stripes {
+		s.stripes[i] = &sync.Map{}
+	}
+
+	s.tokens = &sync.Pool{
+		New: func() any {
+			index := int((atomic.AddUint32(&s.next, 1) - 1) % uint32(len(s.stripes)))
+			return &index
+		},
+	}
+
+	return s
+}
+
+// add adds n to the count of the interval with the provided key.
+func (s *stripes) add(key int64, n int64) {
+
+	token := s.tokens.Get().(*int)
+
+	var zero int64
+	count, _ := s.stripes[*token].LoadOrStore(key, &zero)
+	atomic.AddInt64(count.(*int64), n)
+
+	s.tokens.Put(token)
+}
+
+// fold moves the counts of the stripes into the provided counts, for intervals
+// before currentFrame, and the current interval too if current is set.
+func (s *stripes) fold(counts *sync.Map, current bool, currentFrame int64) {
+
+	for _, stripe := range s.stripes {
+		stripe.Range(func(key, value any) bool {
+
+			if !current && key.(int64) >= currentFrame {
+				return true // continue
+			}
+
+			value, ok := stripe.LoadAndDelete(key)
+			if !ok {
+				return true
+			}
+
+			var zero int64
+			count, _ := counts.LoadOrStore(key, &zero)
+			atomic.AddInt64(count.(*int64), atomic.LoadInt64(value.(*int64)))
+
+			return true
+		})
+	}
+}
@@ -0,0 +1,88 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounter_striped(t *testing.T) {
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	client := &Quantifier{
+		clock:    mockClock,
+		exporter: &mockExporter{},
+	}
+
+	runtime.GOMAXPROCS(4)
+
+	parent, err := client.CreateCounter("requests", nil, 10)
+	assert.NoError(t, err)
+
+	counter, err := client.CreateChildCounter(parent, "requests_hot", nil, 10, MetricOptionWithStripedCounting())
+	assert.NoError(t, err)
+	assert.Len(t, counter.stripes.stripes, 4)
+
+	count := func(goroutines int, n int) {
+
+		wg := &sync.WaitGroup{}
+
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < n; j++ {
+					counter.Count()
+				}
+			}()
+		}
+
+		wg.Wait()
+	}
+
+	// counts remain correct as the number of processors changes
+	for _, procs := range []int{1, 8, 2} {
+		runtime.GOMAXPROCS(procs)
+		count(16, 1000)
+	}
+
+	// counts within a completed interval, and the current one
+	assert.NoError(t, counter.AddAt(mockClock.Now().Add(-time.Second*10), 5))
+	mockClock.Add(time.Second * 10)
+	count(4, 10)
+
+	assert.Equal(t, []*Point{
+		{
+			Start: time.Unix(1670681760, 0),
+			End:   time.Unix(1670681770, 0),
+			Count: 5,
+		},
+		{
+			Start: time.Unix(1670681770, 0),
+			End:   time.Unix(1670681780, 0),
+			Count: 48000,
+		},
+	}, counter.takePoints(false, 0))
+
+	points := counter.takePoints(true, 0)
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, int64(40), points[0].Count)
+	}
+
+	// and roll up into the parent
+	var total int64
+	for _, point := range parent.takePoints(true, 0) {
+		total += point.Count
+	}
+	assert.Equal(t, int64(48045), total)
+}
@@ -0,0 +1,119 @@
+// Package structlog provides a quantify.Exporter that writes each exported point
+// as a structured (JSON) log line, for when metrics must be derived within a log
+// pipeline (e.g. log-based metrics) rather than pushed directly.
+//
+// Lines follow the shape of common structured loggers, with "time", "level" and
+// "msg" fields followed by the fields of the point:
+//
+//	{"time":"2023-01-02T21:02:30Z","level":"INFO","msg":"metric point","metric":"planes",...}
+//
+// Any io.Writer can be used as the destination, including a *syslog.Writer.
+package structlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+	"github.com/rustedturnip/quantify/internal/record"
+)
+
+const (
+	defaultLevel = "INFO"
+
+	// DefaultMessage is the message of each log line unless otherwise configured
+	// with OptionWithMessage.
+	DefaultMessage = "metric point"
+)
+
+var (
+	ErrNoWriter = errors.New("no writer provided")
+)
+
+// line is a single log line written by the Exporter.
+type line struct {
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	Msg   string    `json:"msg"`
+	*record.Record
+}
+
+// Exporter implements quantify.Exporter, writing points as structured log lines.
+type Exporter struct {
+	mu      *sync.Mutex
+	writer  io.Writer
+	now     func() time.Time
+	level   string
+	message string
+}
+
+// New returns an instantiated Exporter that writes to the provided io.Writer, or
+// returns an error if instantiation fails.
+//
+// options allow the user to provide custom configurations as a list of Options.
+// If any options are invalid, a quantify.ValidationErrors describing every
+// problem is returned.
+func New(writer io.Writer, options ...Option) (*Exporter, error) {
+
+	if writer == nil {
+		return nil, ErrNoWriter
+	}
+
+	exporter := &Exporter{
+		mu:      &sync.Mutex{},
+		writer:  writer,
+		now:     time.Now,
+		level:   defaultLevel,
+		message: DefaultMessage,
+	}
+
+	// apply every option, so that all problems are reported together
+	var errs quantify.ValidationErrors
+
+	for _, option := range options {
+		err := option(exporter)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return exporter, nil
+}
+
+// Export implements quantify.Exporter, writing a log line for each point of the
+// provided series. Each line is written with a single call to the io.Writer.
+func (e *Exporter) Export(ctx context.Context, series []*quantify.Series) error {
+
+	now := e.now().UTC()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range record.FromSeries(series) {
+
+		encoded, err := json.Marshal(&line{
+			Time:   now,
+			Level:  e.level,
+			Msg:    e.message,
+			Record: r,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = e.writer.Write(append(encoded, '\n'))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package structlog
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+func TestExporter_Export(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+
+	exporter, err := New(buffer, OptionWithMessage("quantify"))
+	assert.NoError(t, err)
+
+	exporter.now = func() time.Time {
+		return time.Unix(1672693351, 0)
+	}
+
+	series := []*quantify.Series{
+		{
+			Metric: &quantify.Metric{
+				Name:   "planes",
+				Labels: map[string]string{"manufacturer": "boeing"},
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: 3,
+				},
+				{
+					Start: time.Unix(1672693350, 0),
+					End:   time.Unix(1672693360, 0),
+					Count: 1,
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, exporter.Export(context.Background(), series))
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.JSONEq(t, `{
+		"time": "2023-01-02T21:02:31Z",
+		"level": "INFO",
+		"msg": "quantify",
+		"metric": "planes",
+		"labels": {"manufacturer": "boeing"},
+		"kind": "CUMULATIVE",
+		"value_type": "INT64",
+		"start": "2023-01-02T21:02:20Z",
+		"end": "2023-01-02T21:02:30Z",
+		"count": 3
+	}`, lines[0])
+}
+
+func TestNew(t *testing.T) {
+
+	_, err := New(nil)
+	assert.Equal(t, ErrNoWriter, err)
+
+	_, err = New(&bytes.Buffer{}, OptionWithLevel(""))
+	assert.EqualError(t, err, "level: can't be empty")
+}
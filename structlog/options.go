@@ -0,0 +1,34 @@
+package structlog
+
+import (
+	"errors"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Option defines a function for supplying the Exporter constructor with certain
+// configurations.
+type Option func(*Exporter) error
+
+// OptionWithLevel sets the level of each log line, which defaults to "INFO".
+func OptionWithLevel(level string) Option {
+	return func(exporter *Exporter) error {
+		if level == "" {
+			return &quantify.FieldError{Path: "level", Err: errors.New("can't be empty")}
+		}
+		exporter.level = level
+		return nil
+	}
+}
+
+// OptionWithMessage sets the message of each log line, which defaults to
+// "metric point", allowing log pipelines to filter for metric lines.
+func OptionWithMessage(message string) Option {
+	return func(exporter *Exporter) error {
+		if message == "" {
+			return &quantify.FieldError{Path: "message", Err: errors.New("can't be empty")}
+		}
+		exporter.message = message
+		return nil
+	}
+}
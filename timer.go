@@ -0,0 +1,111 @@
+package quantify
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+
+	// defaultTimerUnit is the unit of Timers created without one.
+	defaultTimerUnit = "ms"
+)
+
+// Timer measures the durations of operations, such as requests, recording each as
+// an observation of a Distribution in the unit of its metric (milliseconds unless
+// another unit of time is provided with MetricOptionWithUnit).
+type Timer struct {
+	distribution *Distribution
+	clock        Clock
+}
+
+// Stopwatch measures a single operation of a Timer (see Timer.Start).
+type Stopwatch struct {
+	timer *Timer
+	start time.Time
+	once  sync.Once
+}
+
+// CreateTimer creates a Timer that records durations into the provided buckets over
+// intervals of the provided number of seconds (see CreateDistribution). The bounds
+// of the buckets are in the unit of the Timer.
+//
+// options allow optional metadata to be provided as a list of MetricOptions. Any
+// unit provided must be a unit of time, e.g. "s" or "ms".
+func (q *Quantifier) CreateTimer(name string, labels map[string]string, interval int64, buckets *Buckets, options ...MetricOption) (*Timer, error) {
+
+	instrument, err := q.createTimer(name, labels, interval, buckets, options...)
+	if err != nil && q.noopInstead(err) {
+		return &Timer{distribution: &Distribution{noop: true}, clock: systemClock{}}, nil
+	}
+
+	return instrument, err
+}
+
+// createTimer creates a Timer (see CreateTimer).
+func (q *Quantifier) createTimer(name string, labels map[string]string, interval int64, buckets *Buckets, options ...MetricOption) (*Timer, error) {
+
+	// options are applied in order, so a provided unit replaces the default
+	options = append([]MetricOption{MetricOptionWithUnit(defaultTimerUnit)}, options...)
+
+	// validate the unit before the distribution is registered
+	unit := &Metric{}
+	for _, option := range options {
+		option(unit)
+	}
+
+	if _, err := durationIn(unit.Unit, 0); err != nil {
+		return nil, err
+	}
+
+	distribution, err := q.createDistribution(name, labels, interval, buckets, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Timer{
+		distribution: distribution,
+		clock:        q.clock,
+	}, nil
+}
+
+// Start starts measuring an operation, which is recorded when the returned
+// Stopwatch is stopped, e.g.
+//
+//	defer timer.Start().Stop()
+func (t *Timer) Start() *Stopwatch {
+	return &Stopwatch{
+		timer: t,
+		start: t.clock.Now(),
+	}
+}
+
+// Time measures and records the duration of fn.
+func (t *Timer) Time(fn func()) {
+
+	sw := t.Start()
+	defer sw.Stop()
+
+	fn()
+}
+
+// Record records the provided duration, for operations measured by other means.
+func (t *Timer) Record(d time.Duration) {
+
+	// the unit was validated when the Timer was created
+	_ = t.distribution.RecordDuration(d)
+}
+
+// Stop records the time elapsed since the Stopwatch was started, returning it.
+// Only the first call to Stop records the duration, so that it can be both deferred
+// and called early.
+func (sw *Stopwatch) Stop() time.Duration {
+
+	elapsed := sw.timer.clock.Now().Sub(sw.start)
+
+	sw.once.Do(func() {
+		sw.timer.Record(elapsed)
+	})
+
+	return elapsed
+}
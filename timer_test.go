@@ -0,0 +1,95 @@
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimer(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681765, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	timer, err := client.CreateTimer("latency", nil, 60, ExplicitBuckets(100, 1000))
+	assert.NoError(t, err)
+
+	// stopped early and again when deferred, recording once
+	sw := timer.Start()
+	mockClock.Add(time.Millisecond * 250)
+	assert.Equal(t, time.Millisecond*250, sw.Stop())
+	mockClock.Add(time.Millisecond * 250)
+	sw.Stop()
+
+	timer.Time(func() {
+		mockClock.Add(time.Millisecond * 50)
+	})
+
+	timer.Record(time.Second * 2)
+
+	mockClock.Set(time.Unix(1670681820, 0))
+	assert.NoError(t, client.report(false))
+
+	if assert.Len(t, exporter.series, 1) {
+
+		assert.Equal(t, "ms", exporter.series[0].Metric.Unit)
+
+		dv := exporter.series[0].Points[0].Distribution
+		assert.Equal(t, int64(3), dv.Count)
+		assert.Equal(t, 50.0, dv.Min)
+		assert.Equal(t, 2000.0, dv.Max)
+		assert.Equal(t, []int64{1, 1, 1}, dv.BucketCounts)
+	}
+}
+
+func TestQuantifier_CreateTimer(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		options      []MetricOption
+		expectedUnit string
+		expectedErr  error
+	}{
+		{
+			name:         "default unit",
+			expectedUnit: "ms",
+		},
+		{
+			name:         "seconds",
+			options:      []MetricOption{MetricOptionWithUnit("s")},
+			expectedUnit: "s",
+		},
+		{
+			name:        "unit of information",
+			options:     []MetricOption{MetricOptionWithUnit("By")},
+			expectedErr: ErrIncompatibleUnit,
+		},
+	}
+
+	for _, test := range tests {
+
+		client := &Quantifier{
+			clock:    newMockClock(),
+			exporter: &mockExporter{},
+		}
+
+		timer, err := client.CreateTimer("latency", nil, 60, ExponentialBuckets(10, 2, 1), test.options...)
+		assert.ErrorIsf(t, err, test.expectedErr, "%s failed", test.name)
+
+		if test.expectedErr != nil {
+			assert.Emptyf(t, client.recorders, "%s failed", test.name)
+			continue
+		}
+
+		assert.Equalf(t, test.expectedUnit, timer.distribution.metric.Unit, "%s failed", test.name)
+	}
+}
@@ -0,0 +1,167 @@
+package quantify
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// TopKOther is the label value of the series counting every value of a TopK
+	// outside of the top K.
+	TopKOther = "other"
+)
+
+var (
+	ErrInvalidK = errors.New("k must be greater than 0")
+)
+
+// TopK is an instrument that counts occurrences of the values of a high-cardinality
+// label (e.g. URL paths or SQL fingerprints), publishing only the K most frequent
+// values of each refresh along with an "other" series counting the rest. This bounds
+// the number of series published whilst preserving the most significant values.
+type TopK struct {
+	mu     *sync.Mutex
+	metric *Metric
+	key    string
+	k      int
+
+	// start is the start of the current interval, and counts tracks the count of
+	// each value within it.
+	start  time.Time
+	counts map[string]int64
+
+	// noop is set for TopKs that discard counts (see
+	// OptionWithBestEffortInstruments).
+	noop bool
+}
+
+// CreateTopK creates a TopK that publishes the K most frequent values of the label
+// key with each refresh. Each published series is labelled with the provided labels
+// and key, set to the value counted, or TopKOther for the remainder.
+//
+// Note: every distinct value counted is held until the next refresh, so memory use
+// grows with the number of distinct values seen within a refresh interval.
+func (q *Quantifier) CreateTopK(name string, labels map[string]string, key string, k int, options ...MetricOption) (*TopK, error) {
+
+	instrument, err := q.createTopK(name, labels, key, k, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopTopK(), nil
+	}
+
+	return instrument, err
+}
+
+// createTopK creates a TopK (see CreateTopK).
+func (q *Quantifier) createTopK(name string, labels map[string]string, key string, k int, options ...MetricOption) (*TopK, error) {
+
+	if k <= 0 {
+		return nil, ErrInvalidK
+	}
+
+	metric := &Metric{
+		Name:   name,
+		Labels: q.mergeCommonLabels(labels),
+	}
+
+	for _, option := range options {
+		option(metric)
+	}
+
+	// validate including the label that will be added on publishing
+	err := q.validateMetric(withLabel(metric, key, TopKOther))
+	if err != nil {
+		return nil, err
+	}
+
+	tk := &TopK{
+		mu:     &sync.Mutex{},
+		metric: metric,
+		key:    key,
+		k:      k,
+		start:  q.clock.Now(),
+		counts: make(map[string]int64),
+	}
+
+	q.collectors = append(q.collectors, tk)
+
+	return tk, nil
+}
+
+// Count adds 1 to the count of the provided value.
+func (tk *TopK) Count(value string) {
+
+	if tk.noop {
+		return
+	}
+
+	value = tk.metric.normalise(tk.key, value)
+
+	tk.mu.Lock()
+	defer tk.mu.Unlock()
+
+	tk.counts[value]++
+}
+
+// collect implements collector, publishing the counts of the K most frequent values
+// since the previous report, and the combined count of all other values.
+func (tk *TopK) collect(now time.Time) []*Series {
+
+	tk.mu.Lock()
+	counts := tk.counts
+	start := tk.start
+	tk.counts = make(map[string]int64)
+	tk.start = now
+	tk.mu.Unlock()
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+
+	// order by count descending, breaking ties by value so the top K is stable
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] == counts[values[j]] {
+			return values[i] < values[j]
+		}
+		return counts[values[i]] > counts[values[j]]
+	})
+
+	series := make([]*Series, 0, tk.k+1)
+
+	var other int64
+	for i, value := range values {
+
+		if i >= tk.k {
+			other += counts[value]
+			continue
+		}
+
+		series = append(series, tk.series(value, start, now, counts[value]))
+	}
+
+	if other > 0 {
+		series = append(series, tk.series(TopKOther, start, now, other))
+	}
+
+	return series
+}
+
+// series returns a Series holding a single point for the provided value.
+func (tk *TopK) series(value string, start time.Time, end time.Time, count int64) *Series {
+	return &Series{
+		Metric: withLabel(tk.metric, tk.key, value),
+		Points: []*Point{
+			{
+				Start: start,
+				End:   end,
+				Count: count,
+			},
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package quantify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_CreateTopK(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	client := &Quantifier{
+		clock:    mockClock,
+		exporter: &mockExporter{},
+	}
+
+	_, err := client.CreateTopK("requests", nil, "path", 0)
+	assert.Equal(t, ErrInvalidK, err)
+
+	topK, err := client.CreateTopK("requests", map[string]string{"service": "api"}, "path", 2)
+	assert.NoError(t, err)
+
+	for path, n := range map[string]int{"/a": 5, "/b": 2, "/c": 2, "/d": 1} {
+		for i := 0; i < n; i++ {
+			topK.Count(path)
+		}
+	}
+
+	mockClock.Add(time.Minute)
+
+	counts := make(map[string]int64)
+	for _, s := range topK.collect(mockClock.Now()) {
+		assert.Equal(t, "api", s.Metric.Labels["service"])
+		assert.Equal(t, time.Unix(1670681770, 0), s.Points[0].Start)
+		assert.Equal(t, time.Unix(1670681830, 0), s.Points[0].End)
+		counts[s.Metric.Labels["path"]] = s.Points[0].Count
+	}
+
+	// ties are broken by value, so "/b" is kept over "/c"
+	assert.Equal(t, map[string]int64{
+		"/a":      5,
+		"/b":      2,
+		TopKOther: 3,
+	}, counts)
+
+	// counts restart with each report
+	assert.Empty(t, topK.collect(mockClock.Now()))
+}
+
+func TestTopK_Count_normaliser(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	client := &Quantifier{
+		clock:    mockClock,
+		exporter: &mockExporter{},
+	}
+
+	topK, err := client.CreateTopK("requests", nil, "path", 2, MetricOptionWithLabelNormaliser("path", strings.ToLower))
+	assert.NoError(t, err)
+
+	topK.Count("/A")
+	topK.Count("/a")
+
+	series := topK.collect(mockClock.Now())
+	if assert.Len(t, series, 1) {
+		assert.Equal(t, "/a", series[0].Metric.Labels["path"])
+		assert.Equal(t, int64(2), series[0].Points[0].Count)
+	}
+}
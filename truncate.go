@@ -0,0 +1,104 @@
+package quantify
+
+import (
+	"fmt"
+	"hash/fnv"
+	"unicode/utf8"
+)
+
+const (
+
+	// truncationHashLength is the length of the hash suffix of truncated names and
+	// labels.
+	truncationHashLength = 8
+)
+
+// OptionWithTruncation truncates metric names, label keys and label values that
+// exceed the limits of the Exporter (see LengthLimiter), rather than rejecting
+// them, which keeps generated names flowing. Each is cut short and suffixed with
+// a hash of the original, so that names and labels sharing a long prefix remain
+// distinct, and the same original is always truncated in the same way.
+//
+// The option has no effect if the Exporter doesn't implement LengthLimiter.
+func OptionWithTruncation() Option {
+	return func(q *Quantifier) error {
+
+		if err := q.configure("truncation"); err != nil {
+			return err
+		}
+
+		q.truncate = true
+		return nil
+	}
+}
+
+// truncateMetric truncates the name and labels of the provided metric that exceed
+// the limits of the Quantifier's Exporter (see OptionWithTruncation). The labels
+// are replaced, rather than modified, as they may belong to the caller.
+func (q *Quantifier) truncateMetric(metric *Metric) {
+
+	limiter, ok := q.exporter.(LengthLimiter)
+	if !ok {
+		return
+	}
+
+	limits := limiter.LengthLimits()
+
+	metric.Name = truncateWithHash(metric.Name, limits.Name)
+
+	truncated := false
+	for key, value := range metric.Labels {
+		if exceeds(key, limits.LabelKey) || exceeds(value, limits.LabelValue) {
+			truncated = true
+			break
+		}
+	}
+
+	if !truncated {
+		return
+	}
+
+	labels := make(map[string]string, len(metric.Labels))
+	for key, value := range metric.Labels {
+		labels[truncateWithHash(key, limits.LabelKey)] = truncateWithHash(value, limits.LabelValue)
+	}
+
+	metric.Labels = labels
+}
+
+// exceeds returns whether s is longer than the provided limit, where a limit of 0
+// means there is none.
+func exceeds(s string, limit int) bool {
+	return limit > 0 && len(s) > limit
+}
+
+// truncateWithHash returns s, or if s exceeds the provided limit, as much of s as
+// fits followed by a hexadecimal hash of s. s is only cut between characters, so
+// the result may be shorter than the limit.
+func truncateWithHash(s string, limit int) string {
+
+	if !exceeds(s, limit) {
+		return s
+	}
+
+	// too short to hold a hash, so can only be cut
+	if limit <= truncationHashLength {
+		return cut(s, limit)
+	}
+
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(s))
+
+	return cut(s, limit-truncationHashLength) + fmt.Sprintf("%08x", hasher.Sum32())
+}
+
+// cut returns the longest prefix of s, no longer than n bytes, that doesn't split a
+// character.
+func cut(s string, n int) string {
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
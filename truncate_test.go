@@ -0,0 +1,97 @@
+package quantify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// limitedLengthExporter implements Exporter and LengthLimiter.
+type limitedLengthExporter struct {
+	mockExporter
+	limits LengthLimits
+}
+
+func (lle *limitedLengthExporter) LengthLimits() LengthLimits {
+	return lle.limits
+}
+
+func TestTruncateWithHash(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		input    string
+		limit    int
+		expected string
+	}{
+		{
+			name:     "within limit",
+			input:    "planes",
+			limit:    10,
+			expected: "planes",
+		},
+		{
+			name:     "no limit",
+			input:    strings.Repeat("p", 100),
+			limit:    0,
+			expected: strings.Repeat("p", 100),
+		},
+		{
+			name:     "exceeds limit",
+			input:    "planes_by_manufacturer",
+			limit:    16,
+			expected: "planes_bdf34b9c6",
+		},
+		{
+			name:     "shared prefix",
+			input:    "planes_by_model",
+			limit:    12,
+			expected: "plan56a52604",
+		},
+		{
+			name:     "limit below hash length",
+			input:    "planes_by_manufacturer",
+			limit:    6,
+			expected: "planes",
+		},
+		{
+			name:     "multi-byte character",
+			input:    "aéroplanes_by_manufacturer",
+			limit:    10,
+			expected: "a8844c960",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equalf(t, test.expected, truncateWithHash(test.input, test.limit), "%s failed", test.name)
+	}
+}
+
+func TestQuantifier_CreateCounter_truncation(t *testing.T) {
+
+	labels := map[string]string{
+		"manufacturer_of_plane": "boeing",
+		"colour":                strings.Repeat("r", 20),
+	}
+
+	client := &Quantifier{
+		clock: newMockClock(),
+		exporter: &limitedLengthExporter{
+			limits: LengthLimits{Name: 16, LabelKey: 12, LabelValue: 12},
+		},
+		truncate: true,
+	}
+
+	_, err := client.CreateCounter("planes_by_manufacturer", labels, 10)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "planes_bdf34b9c6", client.counters[0].metric.Name)
+	assert.Equal(t, map[string]string{
+		"manu62ba1f9d": "boeing",
+		"colour":       "rrrr9b52ea85",
+	}, client.counters[0].metric.Labels)
+
+	// the caller's labels are left as they were
+	assert.Contains(t, labels, "manufacturer_of_plane")
+}
@@ -0,0 +1,55 @@
+package quantify
+
+// Tx records increments to several counters as one (see Quantifier.Record).
+type Tx struct {
+	increments []increment
+	err        error
+}
+
+// increment is an amount to add to a Counter.
+type increment struct {
+	counter *Counter
+	n       int64
+}
+
+// Record calls fn to record increments to several counters, such as the requests
+// and bytes of a single operation, and then applies them all at the same instant,
+// so that every increment lands in the interval containing that instant, even if
+// fn straddles an interval boundary.
+//
+// If any increment is invalid (e.g. negative), none are applied and the error is
+// returned.
+func (q *Quantifier) Record(fn func(tx *Tx)) error {
+
+	tx := &Tx{}
+	fn(tx)
+
+	if tx.err != nil {
+		return tx.err
+	}
+
+	now := q.clock.Now()
+	for _, inc := range tx.increments {
+		inc.counter.add(now, inc.n)
+	}
+
+	return nil
+}
+
+// Count records an increment of 1 to the provided Counter.
+func (tx *Tx) Count(counter *Counter) {
+	tx.increments = append(tx.increments, increment{counter: counter, n: 1})
+}
+
+// Add records an increment of n to the provided Counter. n must not be negative.
+func (tx *Tx) Add(counter *Counter, n int64) {
+
+	if n < 0 {
+		if tx.err == nil {
+			tx.err = ErrNegativeValue
+		}
+		return
+	}
+
+	tx.increments = append(tx.increments, increment{counter: counter, n: n})
+}
@@ -0,0 +1,57 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_Record(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681775, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	requests, err := client.CreateCounter("requests", nil, 10)
+	assert.NoError(t, err)
+
+	bytes, err := client.CreateCounter("bytes", nil, 10)
+	assert.NoError(t, err)
+
+	// the call straddles an interval boundary, but both land in the same interval
+	err = client.Record(func(tx *Tx) {
+		tx.Count(requests)
+		mockClock.Add(time.Second * 10)
+		tx.Add(bytes, 512)
+	})
+	assert.NoError(t, err)
+
+	// invalid increments aren't applied
+	err = client.Record(func(tx *Tx) {
+		tx.Count(requests)
+		tx.Add(bytes, -1)
+	})
+	assert.Equal(t, ErrNegativeValue, err)
+
+	mockClock.Add(time.Second * 10)
+	assert.NoError(t, client.report(false))
+
+	if assert.Len(t, exporter.series, 2) {
+		for _, series := range exporter.series {
+			assert.Len(t, series.Points, 1)
+			assert.Equal(t, time.Unix(1670681780, 0), series.Points[0].Start)
+		}
+		assert.Equal(t, int64(1), exporter.series[0].Points[0].Count)
+		assert.Equal(t, int64(512), exporter.series[1].Points[0].Count)
+	}
+}
@@ -0,0 +1,35 @@
+package quantify
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantifier_CreateUniqueCounter(t *testing.T) {
+
+	now := time.Unix(1670681770, 0)
+
+	client := &Quantifier{
+		clock:    newMockClock(),
+		exporter: &mockExporter{},
+	}
+
+	users, err := client.CreateUniqueCounter("unique_users", nil)
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		users.Observe(fmt.Sprintf("user-%d", i%20))
+	}
+
+	series := users.collect(now)
+	if assert.Len(t, series, 1) {
+		assert.Equal(t, MetricKindGauge, series[0].Metric.Kind)
+		assert.Equal(t, []*Point{{Start: now, End: now, Count: 20}}, series[0].Points)
+	}
+
+	// estimates restart with each report
+	assert.Equal(t, int64(0), users.collect(now.Add(time.Minute))[0].Points[0].Count)
+}
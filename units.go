@@ -0,0 +1,132 @@
+package quantify
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	ErrIncompatibleUnit = errors.New("value can't be converted to the metric's unit")
+)
+
+// durationUnits maps each time unit of the Unified Code for Units of Measure to its
+// duration.
+var durationUnits = map[string]time.Duration{
+	"ns":  time.Nanosecond,
+	"us":  time.Microsecond,
+	"ms":  time.Millisecond,
+	"s":   time.Second,
+	"min": time.Minute,
+	"h":   time.Hour,
+	"d":   time.Hour * 24,
+}
+
+// byteUnits maps each information unit of the Unified Code for Units of Measure to
+// its size in bytes.
+var byteUnits = map[string]float64{
+	"bit":  0.125,
+	"By":   1,
+	"kBy":  1e3,
+	"KiBy": 1 << 10,
+	"MBy":  1e6,
+	"MiBy": 1 << 20,
+	"GBy":  1e9,
+	"GiBy": 1 << 30,
+	"TBy":  1e12,
+	"TiBy": 1 << 40,
+}
+
+// RecordDuration adds the provided duration to the Counter, converted to the unit
+// of its metric (e.g. 1500 for 1.5s when the unit is "ms"), rounded to the nearest
+// whole unit. The unit must be a unit of time (see MetricOptionWithUnit).
+func RecordDuration(counter *Counter, d time.Duration) error {
+
+	value, err := durationIn(counter.unit, d)
+	if err != nil {
+		return err
+	}
+
+	return counter.Add(int64(math.Round(value)))
+}
+
+// RecordBytes adds the provided number of bytes to the Counter, converted to the
+// unit of its metric (e.g. 2 for 2048 bytes when the unit is "KiBy"), rounded to
+// the nearest whole unit. The unit must be a unit of information (see
+// MetricOptionWithUnit).
+func RecordBytes(counter *Counter, n int64) error {
+
+	value, err := bytesIn(counter.unit, n)
+	if err != nil {
+		return err
+	}
+
+	return counter.Add(int64(math.Round(value)))
+}
+
+// SetDuration sets the value of the Gauge to the provided duration, converted to
+// the unit of its metric (see RecordDuration).
+func SetDuration(gauge *Gauge, d time.Duration) error {
+
+	value, err := durationIn(gauge.metric.Unit, d)
+	if err != nil {
+		return err
+	}
+
+	gauge.Set(value)
+	return nil
+}
+
+// SetBytes sets the value of the Gauge to the provided number of bytes, converted
+// to the unit of its metric (see RecordBytes).
+func SetBytes(gauge *Gauge, n int64) error {
+
+	value, err := bytesIn(gauge.metric.Unit, n)
+	if err != nil {
+		return err
+	}
+
+	gauge.Set(value)
+	return nil
+}
+
+// durationIn returns the provided duration in the provided unit.
+func durationIn(unit string, d time.Duration) (float64, error) {
+
+	size, ok := durationUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q isn't a unit of time", ErrIncompatibleUnit, unit)
+	}
+
+	return float64(d) / float64(size), nil
+}
+
+// bytesIn returns the provided number of bytes in the provided unit.
+func bytesIn(unit string, n int64) (float64, error) {
+
+	size, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q isn't a unit of information", ErrIncompatibleUnit, unit)
+	}
+
+	return float64(n) / size, nil
+}
+
+// RecordDuration records the provided duration as an observation of the
+// Distribution, converted to the unit of its metric (e.g. 1.5 for 1500ms when the
+// unit is "s"). The unit must be a unit of time (see MetricOptionWithUnit).
+func (d *Distribution) RecordDuration(duration time.Duration) error {
+
+	if d.noop {
+		return nil
+	}
+
+	value, err := durationIn(d.metric.Unit, duration)
+	if err != nil {
+		return err
+	}
+
+	d.Record(value)
+	return nil
+}
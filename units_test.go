@@ -0,0 +1,167 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordDuration(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		unit          string
+		input         time.Duration
+		expected      int64
+		expectedError error
+	}{
+		{
+			name:     "milliseconds",
+			unit:     "ms",
+			input:    time.Millisecond * 1500,
+			expected: 1500,
+		},
+		{
+			name:     "seconds rounded",
+			unit:     "s",
+			input:    time.Millisecond * 1500,
+			expected: 2,
+		},
+		{
+			name:     "microseconds",
+			unit:     "us",
+			input:    time.Millisecond,
+			expected: 1000,
+		},
+		{
+			name:          "no unit",
+			input:         time.Second,
+			expectedError: ErrIncompatibleUnit,
+		},
+		{
+			name:          "unit of information",
+			unit:          "By",
+			input:         time.Second,
+			expectedError: ErrIncompatibleUnit,
+		},
+		{
+			name:          "negative",
+			unit:          "s",
+			input:         -time.Second,
+			expectedError: ErrNegativeValue,
+		},
+	}
+
+	for _, test := range tests {
+
+		mockClock := newMockClock()
+		mockClock.Set(time.Unix(1670681770, 0))
+
+		q := &Quantifier{clock: mockClock}
+
+		counter, err := q.CreateCounter("latency", nil, 10, MetricOptionWithUnit(test.unit))
+		assert.NoError(t, err, "%s failed", test.name)
+
+		err = RecordDuration(counter, test.input)
+		assert.ErrorIs(t, err, test.expectedError, "%s failed", test.name)
+
+		if test.expectedError == nil {
+			points := counter.takePoints(true, 0)
+			if assert.Len(t, points, 1, "%s failed", test.name) {
+				assert.Equal(t, test.expected, points[0].Count, "%s failed", test.name)
+			}
+		}
+	}
+}
+
+func TestRecordBytes(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		unit          string
+		input         int64
+		expected      int64
+		expectedError error
+	}{
+		{
+			name:     "bytes",
+			unit:     "By",
+			input:    2048,
+			expected: 2048,
+		},
+		{
+			name:     "kibibytes",
+			unit:     "KiBy",
+			input:    2048,
+			expected: 2,
+		},
+		{
+			name:     "kilobytes",
+			unit:     "kBy",
+			input:    2048,
+			expected: 2,
+		},
+		{
+			name:     "bits",
+			unit:     "bit",
+			input:    2,
+			expected: 16,
+		},
+		{
+			name:          "unit of time",
+			unit:          "s",
+			input:         1,
+			expectedError: ErrIncompatibleUnit,
+		},
+	}
+
+	for _, test := range tests {
+
+		mockClock := newMockClock()
+		mockClock.Set(time.Unix(1670681770, 0))
+
+		q := &Quantifier{clock: mockClock}
+
+		counter, err := q.CreateCounter("transferred", nil, 10, MetricOptionWithUnit(test.unit))
+		assert.NoError(t, err, "%s failed", test.name)
+
+		err = RecordBytes(counter, test.input)
+		assert.ErrorIs(t, err, test.expectedError, "%s failed", test.name)
+
+		if test.expectedError == nil {
+			points := counter.takePoints(true, 0)
+			if assert.Len(t, points, 1, "%s failed", test.name) {
+				assert.Equal(t, test.expected, points[0].Count, "%s failed", test.name)
+			}
+		}
+	}
+}
+
+func TestSetDuration(t *testing.T) {
+
+	q := &Quantifier{clock: systemClock{}}
+
+	lag, err := q.CreateGauge("lag", nil, GaugeAggregationLast, MetricOptionWithUnit("s"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, SetDuration(lag, time.Millisecond*1500))
+	assert.Equal(t, 1.5, lag.last)
+
+	assert.ErrorIs(t, SetBytes(lag, 1), ErrIncompatibleUnit)
+}
+
+func TestSetBytes(t *testing.T) {
+
+	q := &Quantifier{clock: systemClock{}}
+
+	size, err := q.CreateGauge("size", nil, GaugeAggregationLast, MetricOptionWithUnit("MiBy"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, SetBytes(size, 1<<19))
+	assert.Equal(t, 0.5, size.last)
+
+	assert.ErrorIs(t, SetDuration(size, time.Second), ErrIncompatibleUnit)
+}
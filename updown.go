@@ -0,0 +1,94 @@
+package quantify
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// UpDownCounter implements a thread-safe tally that can be both incremented and
+// decremented, such as the number of requests in flight or open connections. Unlike
+// a Counter, which counts occurrences within each interval, its value is the running
+// total of every change, published as a gauge.
+type UpDownCounter struct {
+	metric *Metric
+	value  int64
+}
+
+// CreateUpDownCounter creates an UpDownCounter, starting at 0, whose current value
+// is published with each refresh.
+//
+// options allow optional metadata, such as a display name, to be provided as a
+// list of MetricOptions.
+func (q *Quantifier) CreateUpDownCounter(name string, labels map[string]string, options ...MetricOption) (*UpDownCounter, error) {
+
+	instrument, err := q.createUpDownCounter(name, labels, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopUpDownCounter(), nil
+	}
+
+	return instrument, err
+}
+
+// createUpDownCounter creates an UpDownCounter (see CreateUpDownCounter).
+func (q *Quantifier) createUpDownCounter(name string, labels map[string]string, options ...MetricOption) (*UpDownCounter, error) {
+
+	metric := &Metric{
+		Name:      name,
+		Labels:    q.mergeCommonLabels(labels),
+		Kind:      MetricKindGauge,
+		ValueType: ValueTypeInt64,
+	}
+
+	for _, option := range options {
+		option(metric)
+	}
+
+	err := q.validateMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	udc := &UpDownCounter{
+		metric: metric,
+	}
+
+	q.collectors = append(q.collectors, udc)
+
+	return udc, nil
+}
+
+// Inc increments the UpDownCounter by 1.
+func (udc *UpDownCounter) Inc() {
+	atomic.AddInt64(&udc.value, 1)
+}
+
+// Dec decrements the UpDownCounter by 1.
+func (udc *UpDownCounter) Dec() {
+	atomic.AddInt64(&udc.value, -1)
+}
+
+// Add adds n, which may be negative, to the UpDownCounter.
+func (udc *UpDownCounter) Add(n int64) {
+	atomic.AddInt64(&udc.value, n)
+}
+
+// Value returns the current value of the UpDownCounter.
+func (udc *UpDownCounter) Value() int64 {
+	return atomic.LoadInt64(&udc.value)
+}
+
+// collect implements collector, publishing the current value.
+func (udc *UpDownCounter) collect(now time.Time) []*Series {
+	return []*Series{
+		{
+			Metric: udc.metric,
+			Points: []*Point{
+				{
+					Start: now,
+					End:   now,
+					Count: udc.Value(),
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package quantify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpDownCounter_collect(t *testing.T) {
+
+	now := time.Unix(1670681770, 0)
+
+	tests := []struct {
+		name          string
+		changes       func(udc *UpDownCounter)
+		expectedCount int64
+	}{
+		{
+			name:          "unchanged",
+			changes:       func(udc *UpDownCounter) {},
+			expectedCount: 0,
+		},
+		{
+			name: "inc and dec",
+			changes: func(udc *UpDownCounter) {
+				udc.Inc()
+				udc.Inc()
+				udc.Dec()
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "negative",
+			changes: func(udc *UpDownCounter) {
+				udc.Add(3)
+				udc.Add(-5)
+			},
+			expectedCount: -2,
+		},
+	}
+
+	for _, test := range tests {
+
+		client := &Quantifier{
+			clock:    newMockClock(),
+			exporter: &mockExporter{},
+		}
+
+		udc, err := client.CreateUpDownCounter("requests_in_flight", nil)
+		assert.NoErrorf(t, err, "%s failed", test.name)
+
+		test.changes(udc)
+
+		series := udc.collect(now)
+		if assert.Lenf(t, series, 1, "%s failed", test.name) {
+			assert.Equalf(t, MetricKindGauge, series[0].Metric.Kind, "%s failed", test.name)
+			assert.Equalf(t, ValueTypeInt64, series[0].Metric.ValueType, "%s failed", test.name)
+			assert.Equalf(t, []*Point{{Start: now, End: now, Count: test.expectedCount}}, series[0].Points, "%s failed", test.name)
+		}
+	}
+}
+
+func TestUpDownCounter_collect_runningTotal(t *testing.T) {
+
+	now := time.Unix(1670681770, 0)
+
+	client := &Quantifier{
+		clock:    newMockClock(),
+		exporter: &mockExporter{},
+	}
+
+	udc, err := client.CreateUpDownCounter("requests_in_flight", nil)
+	assert.NoError(t, err)
+
+	udc.Add(2)
+	udc.collect(now)
+
+	// the value isn't reset by a report
+	udc.Dec()
+
+	series := udc.collect(now)
+	assert.Equal(t, int64(1), series[0].Points[0].Count)
+	assert.Equal(t, int64(1), udc.Value())
+}
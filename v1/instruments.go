@@ -0,0 +1,76 @@
+// Package v1 is the stable instrumentation surface of quantify: the instruments
+// used to record measurements, the options used to describe them, and a Meter to
+// create them with.
+//
+// Libraries should depend on this package rather than quantify itself, so that
+// they aren't affected by changes to exporters, reporting or the configuration of
+// a Quantifier, which remain the concern of the application:
+//
+//	func NewClient(meter v1.Meter) (*Client, error) {
+//
+//		requests, err := meter.Counter("requests", nil, 60, v1.WithUnit("1"))
+//		...
+//	}
+//
+// The application then provides a Meter backed by its Quantifier:
+//
+//	client, err := library.NewClient(v1.NewMeter(quantifier))
+//
+// Interfaces of this package are frozen: methods won't be added to or removed from
+// them, nor will their signatures change.
+package v1
+
+import (
+	"context"
+	"time"
+)
+
+// Counter counts events, publishing the number of events in each interval.
+type Counter interface {
+
+	// Count counts a single event now.
+	Count()
+
+	// CountAt counts a single event at time t.
+	CountAt(t time.Time)
+
+	// AddAt counts n events at time t.
+	AddAt(t time.Time, n int64) error
+
+	// CountContext counts a single event now, unless counting is suppressed for
+	// ctx.
+	CountContext(ctx context.Context)
+}
+
+// Gauge records a value measured at an instant in time, such as a queue depth.
+type Gauge interface {
+
+	// Set records the current value of the Gauge.
+	Set(value float64)
+}
+
+// OutcomeCounter counts the outcomes of an operation, such as requests to a
+// service.
+type OutcomeCounter interface {
+
+	// Success counts a successful outcome.
+	Success()
+
+	// Failure counts a failed outcome with the provided reason.
+	Failure(reason string)
+}
+
+// TopK counts events by value, publishing the most frequent values in each
+// interval.
+type TopK interface {
+
+	// Count counts a single event with the provided value.
+	Count(value string)
+}
+
+// UniqueCounter estimates the number of distinct values observed.
+type UniqueCounter interface {
+
+	// Observe records an occurrence of value.
+	Observe(value string)
+}
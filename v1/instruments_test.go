@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// the instruments of quantify must continue to implement the interfaces of v1.
+var (
+	_ Counter        = (*quantify.Counter)(nil)
+	_ Gauge          = (*quantify.Gauge)(nil)
+	_ OutcomeCounter = (*quantify.OutcomeCounter)(nil)
+	_ TopK           = (*quantify.TopK)(nil)
+	_ UniqueCounter  = (*quantify.UniqueCounter)(nil)
+)
+
+// TestInterfaces_frozen guards against the interfaces of v1 changing, which would
+// break implementations outside of this module.
+func TestInterfaces_frozen(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		iface    reflect.Type
+		expected []string
+	}{
+		{
+			name:  "counter",
+			iface: reflect.TypeOf((*Counter)(nil)).Elem(),
+			expected: []string{
+				"AddAt(time.Time, int64) error",
+				"Count()",
+				"CountAt(time.Time)",
+				"CountContext(context.Context)",
+			},
+		},
+		{
+			name:     "gauge",
+			iface:    reflect.TypeOf((*Gauge)(nil)).Elem(),
+			expected: []string{"Set(float64)"},
+		},
+		{
+			name:     "outcome counter",
+			iface:    reflect.TypeOf((*OutcomeCounter)(nil)).Elem(),
+			expected: []string{"Failure(string)", "Success()"},
+		},
+		{
+			name:     "top k",
+			iface:    reflect.TypeOf((*TopK)(nil)).Elem(),
+			expected: []string{"Count(string)"},
+		},
+		{
+			name:     "unique counter",
+			iface:    reflect.TypeOf((*UniqueCounter)(nil)).Elem(),
+			expected: []string{"Observe(string)"},
+		},
+		{
+			name:  "meter",
+			iface: reflect.TypeOf((*Meter)(nil)).Elem(),
+			expected: []string{
+				"Counter(string, map[string]string, int64, ...v1.Option) (v1.Counter, error)",
+				"Gauge(string, map[string]string, v1.Aggregation, ...v1.Option) (v1.Gauge, error)",
+				"OutcomeCounter(string, map[string]string, int64, bool, ...v1.Option) (v1.OutcomeCounter, error)",
+				"TopK(string, map[string]string, string, int, ...v1.Option) (v1.TopK, error)",
+				"UniqueCounter(string, map[string]string, ...v1.Option) (v1.UniqueCounter, error)",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, methodSet(test.iface), "%s failed", test.name)
+	}
+}
+
+// methodSet returns the sorted signatures of the methods of the provided interface.
+func methodSet(iface reflect.Type) []string {
+
+	methods := make([]string, 0, iface.NumMethod())
+	for i := 0; i < iface.NumMethod(); i++ {
+
+		method := iface.Method(i)
+		signature := method.Type.String()
+
+		// "func(...) ..." -> "Name(...) ..."
+		methods = append(methods, method.Name+signature[len("func"):])
+	}
+
+	sort.Strings(methods)
+	return methods
+}
@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"errors"
+
+	"github.com/rustedturnip/quantify"
+)
+
+var (
+	ErrInvalidAggregation = errors.New("unknown aggregation")
+)
+
+// Aggregation defines how the values set on a Gauge between reports are combined.
+type Aggregation int
+
+const (
+	// AggregationLast publishes the most recently set value.
+	AggregationLast Aggregation = iota
+
+	// AggregationMin publishes the lowest value set.
+	AggregationMin
+
+	// AggregationMax publishes the highest value set.
+	AggregationMax
+
+	// AggregationMean publishes the mean of the values set.
+	AggregationMean
+)
+
+// aggregations maps each Aggregation to its quantify.GaugeAggregation.
+var aggregations = map[Aggregation]quantify.GaugeAggregation{
+	AggregationLast: quantify.GaugeAggregationLast,
+	AggregationMin:  quantify.GaugeAggregationMin,
+	AggregationMax:  quantify.GaugeAggregationMax,
+	AggregationMean: quantify.GaugeAggregationMean,
+}
+
+// Option describes an instrument created by a Meter.
+type Option struct {
+	option quantify.MetricOption
+}
+
+// WithDisplayName sets a human-readable name for the instrument.
+func WithDisplayName(displayName string) Option {
+	return Option{option: quantify.MetricOptionWithDisplayName(displayName)}
+}
+
+// WithDescription sets a description of what the instrument measures.
+func WithDescription(description string) Option {
+	return Option{option: quantify.MetricOptionWithDescription(description)}
+}
+
+// WithUnit sets the unit of the values published by the instrument, in UCUM form
+// (e.g. "By" or "1").
+func WithUnit(unit string) Option {
+	return Option{option: quantify.MetricOptionWithUnit(unit)}
+}
+
+// Meter creates instruments. Labels are fixed when an instrument is created, and
+// intervals are in seconds.
+type Meter interface {
+	Counter(name string, labels map[string]string, interval int64, options ...Option) (Counter, error)
+	Gauge(name string, labels map[string]string, aggregation Aggregation, options ...Option) (Gauge, error)
+	OutcomeCounter(name string, labels map[string]string, interval int64, ratio bool, options ...Option) (OutcomeCounter, error)
+	TopK(name string, labels map[string]string, key string, k int, options ...Option) (TopK, error)
+	UniqueCounter(name string, labels map[string]string, options ...Option) (UniqueCounter, error)
+}
+
+// meter implements Meter with a quantify.Quantifier.
+type meter struct {
+	q *quantify.Quantifier
+}
+
+// NewMeter returns a Meter that creates instruments with the provided Quantifier.
+func NewMeter(q *quantify.Quantifier) Meter {
+	return &meter{q: q}
+}
+
+func (m *meter) Counter(name string, labels map[string]string, interval int64, options ...Option) (Counter, error) {
+
+	counter, err := m.q.CreateCounter(name, labels, interval, metricOptions(options)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return counter, nil
+}
+
+func (m *meter) Gauge(name string, labels map[string]string, aggregation Aggregation, options ...Option) (Gauge, error) {
+
+	gaugeAggregation, ok := aggregations[aggregation]
+	if !ok {
+		return nil, &quantify.FieldError{Path: "aggregation", Err: ErrInvalidAggregation}
+	}
+
+	gauge, err := m.q.CreateGauge(name, labels, gaugeAggregation, metricOptions(options)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return gauge, nil
+}
+
+func (m *meter) OutcomeCounter(name string, labels map[string]string, interval int64, ratio bool, options ...Option) (OutcomeCounter, error) {
+
+	counter, err := m.q.CreateOutcomeCounter(name, labels, interval, ratio, metricOptions(options)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return counter, nil
+}
+
+func (m *meter) TopK(name string, labels map[string]string, key string, k int, options ...Option) (TopK, error) {
+
+	topK, err := m.q.CreateTopK(name, labels, key, k, metricOptions(options)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return topK, nil
+}
+
+func (m *meter) UniqueCounter(name string, labels map[string]string, options ...Option) (UniqueCounter, error) {
+
+	counter, err := m.q.CreateUniqueCounter(name, labels, metricOptions(options)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return counter, nil
+}
+
+// metricOptions returns the quantify.MetricOptions of the provided Options.
+func metricOptions(options []Option) []quantify.MetricOption {
+
+	metricOptions := make([]quantify.MetricOption, 0, len(options))
+	for _, option := range options {
+		if option.option != nil {
+			metricOptions = append(metricOptions, option.option)
+		}
+	}
+
+	return metricOptions
+}
@@ -0,0 +1,129 @@
+//go:build !quantify_disabled
+
+package v1
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// mockExporter implements quantify.Exporter, recording the series exported.
+type mockExporter struct {
+	mu     sync.Mutex
+	series []*quantify.Series
+}
+
+func (me *mockExporter) Export(ctx context.Context, series []*quantify.Series) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	me.series = append(me.series, series...)
+	return nil
+}
+
+func (me *mockExporter) ValidateMetric(metric *quantify.Metric) error {
+	return nil
+}
+
+func TestMeter(t *testing.T) {
+
+	exporter := &mockExporter{}
+
+	q, err := quantify.New(
+		context.Background(),
+		quantify.OptionWithExporter(exporter),
+		quantify.OptionSynchronous(),
+	)
+	assert.NoError(t, err)
+
+	meter := NewMeter(q)
+
+	counter, err := meter.Counter("requests", map[string]string{"method": "GET"}, 60,
+		WithDisplayName("Requests"),
+		WithDescription("Requests received."),
+		WithUnit("1"),
+	)
+	assert.NoError(t, err)
+
+	gauge, err := meter.Gauge("queue_depth", nil, AggregationMax)
+	assert.NoError(t, err)
+
+	outcomes, err := meter.OutcomeCounter("calls", nil, 60, false)
+	assert.NoError(t, err)
+
+	topK, err := meter.TopK("paths", nil, "path", 3)
+	assert.NoError(t, err)
+
+	unique, err := meter.UniqueCounter("users", nil)
+	assert.NoError(t, err)
+
+	counter.Count()
+	gauge.Set(2)
+	gauge.Set(1)
+	outcomes.Success()
+	outcomes.Failure("timeout")
+	topK.Count("/")
+	unique.Observe("user-a")
+
+	q.Stop()
+
+	metrics := make(map[string]*quantify.Series)
+	for _, series := range exporter.series {
+		metrics[series.Metric.Name+"/"+series.Metric.Labels["outcome"]] = series
+	}
+
+	assert.Len(t, metrics, 6)
+
+	if series := metrics["requests/"]; assert.NotNil(t, series) {
+		assert.Equal(t, "Requests", series.Metric.DisplayName)
+		assert.Equal(t, "Requests received.", series.Metric.Description)
+		assert.Equal(t, "1", series.Metric.Unit)
+		assert.Equal(t, int64(1), series.Points[0].Count)
+	}
+
+	if series := metrics["queue_depth/"]; assert.NotNil(t, series) {
+		assert.Equal(t, float64(2), series.Points[0].Value)
+	}
+}
+
+func TestMeter_Gauge(t *testing.T) {
+
+	q, err := quantify.New(
+		context.Background(),
+		quantify.OptionWithExporter(&mockExporter{}),
+		quantify.OptionSynchronous(),
+	)
+	assert.NoError(t, err)
+	defer q.Stop()
+
+	tests := []struct {
+		name          string
+		aggregation   Aggregation
+		expectedError error
+	}{
+		{
+			name:        "last",
+			aggregation: AggregationLast,
+		},
+		{
+			name:        "mean",
+			aggregation: AggregationMean,
+		},
+		{
+			name:          "unknown",
+			aggregation:   Aggregation(-1),
+			expectedError: ErrInvalidAggregation,
+		},
+	}
+
+	for _, test := range tests {
+
+		_, err := NewMeter(q).Gauge("gauge_"+test.name, nil, test.aggregation)
+		assert.ErrorIs(t, err, test.expectedError, "%s failed", test.name)
+	}
+}
@@ -0,0 +1,76 @@
+package quantify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldError describes a problem with a single configuration field, identified by
+// its path (e.g. "resource.labels.project_id").
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (fe *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", fe.Path, fe.Err)
+}
+
+func (fe *FieldError) Unwrap() error {
+	return fe.Err
+}
+
+// ValidationErrors aggregates every problem found whilst validating configuration
+// (e.g. the Options provided to New), rather than only the first.
+//
+// errors.Is and errors.As report whether any of the aggregated errors match.
+type ValidationErrors []error
+
+func (ve ValidationErrors) Error() string {
+
+	messages := make([]string, 0, len(ve))
+	for _, err := range ve {
+		messages = append(messages, err.Error())
+	}
+
+	if len(messages) == 1 {
+		return messages[0]
+	}
+
+	return fmt.Sprintf("%d configuration problems: %s", len(messages), strings.Join(messages, "; "))
+}
+
+// Is reports whether any of the aggregated errors match target.
+func (ve ValidationErrors) Is(target error) bool {
+
+	for _, err := range ve {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first of the aggregated errors that matches target.
+func (ve ValidationErrors) As(target interface{}) bool {
+
+	for _, err := range ve {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Err returns the ValidationErrors as an error, or nil if there are none.
+func (ve ValidationErrors) Err() error {
+
+	if len(ve) == 0 {
+		return nil
+	}
+
+	return ve
+}
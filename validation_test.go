@@ -0,0 +1,93 @@
+package quantify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// summingExporter implements Exporter and CountSummer.
+type summingExporter struct {
+	mockExporter
+}
+
+func (se *summingExporter) SumsCounts() bool {
+	return true
+}
+
+func TestNew_validationErrors(t *testing.T) {
+
+	tests := []struct {
+		name            string
+		options         []Option
+		expectedMessage string
+	}{
+		{
+			name:            "single problem",
+			options:         []Option{OptionWithExporter(&mockExporter{}), OptionWithMaxPointsPerFlush(-1)},
+			expectedMessage: "max_points_per_flush: can't be negative",
+		},
+		{
+			name:            "checkpoint max attempts",
+			options:         []Option{OptionWithExporter(&mockExporter{}), OptionWithCheckpointMaxAttempts(0)},
+			expectedMessage: "checkpoint_max_attempts: must be greater than 0",
+		},
+		{
+			name:            "cumulative totals summed",
+			options:         []Option{OptionWithExporter(&summingExporter{}), OptionWithCumulativeTotals()},
+			expectedMessage: "cumulative_totals: exporter doesn't support cumulative totals",
+		},
+		{
+			name:            "cumulative totals summed through a shared exporter",
+			options:         []Option{OptionWithExporter(&SharedExporter{exporter: &summingExporter{}}), OptionWithCumulativeTotals()},
+			expectedMessage: "cumulative_totals: exporter doesn't support cumulative totals",
+		},
+		{
+			name: "multiple problems",
+			options: []Option{
+				OptionWithMaxPointsPerFlush(-1),
+				OptionWithReportingDelay(-time.Second),
+			},
+			expectedMessage: "3 configuration problems: max_points_per_flush: can't be negative; " +
+				"reporting_delay: can't be negative; exporter: no exporter provided",
+		},
+	}
+
+	for _, test := range tests {
+
+		_, err := New(context.Background(), test.options...)
+
+		assert.EqualErrorf(t, err, test.expectedMessage, "%s failed", test.name)
+	}
+}
+
+func TestNew_duplicateOptions(t *testing.T) {
+
+	_, err := New(
+		context.Background(),
+		OptionWithExporter(&mockExporter{}),
+		OptionWithExporter(&mockExporter{}),
+	)
+
+	assert.ErrorIs(t, err, ErrDuplicateOption)
+	assert.EqualError(t, err, "exporter: option provided more than once")
+}
+
+func TestValidationErrors(t *testing.T) {
+
+	errs := ValidationErrors{
+		&FieldError{Path: "reporting_delay", Err: errors.New("can't be negative")},
+		&FieldError{Path: "exporter", Err: ErrNoExporter},
+	}
+
+	var fieldErr *FieldError
+
+	assert.ErrorIs(t, errs, ErrNoExporter)
+	assert.True(t, errors.As(errs, &fieldErr))
+	assert.Equal(t, "reporting_delay", fieldErr.Path)
+
+	assert.Nil(t, ValidationErrors{}.Err())
+}
@@ -0,0 +1,288 @@
+package quantify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrLabelMismatch = errors.New("label values don't match the label keys")
+)
+
+// CounterVec is a set of Counters of the same metric, distinguished by the values
+// of a fixed set of label keys, with the Counter of each set of values created the
+// first time it's used (see CounterVec.With). This avoids creating a Counter for
+// every combination of values up front.
+type CounterVec struct {
+	q        *Quantifier
+	name     string
+	keys     []string
+	interval int64
+	options  []MetricOption
+
+	// metric describes the series of the CounterVec, with empty label values.
+	metric *Metric
+
+	mu       *sync.Mutex
+	counters map[string]*metricCounter
+
+	// lastActive is when each series was last used, keyed as counters are, which
+	// is tracked when the metric has an idle TTL (see MetricOptionWithIdleTTL).
+	lastActive map[string]time.Time
+
+	// noop is set for CounterVecs that discard counts (see
+	// OptionWithBestEffortInstruments).
+	noop bool
+}
+
+// MetricOptionWithIdleTTL removes the series of a CounterVec once they've been idle
+// (neither returned by CounterVec.With, nor counted) for the provided duration, so
+// that stale label combinations, such as those of disconnected clients or finished
+// jobs, aren't tracked and exported forever. Any counts still held by a removed
+// series are reported with the refresh that removes it, the OnDelete hook is called
+// for it (see Hooks), and a later call to With creates the series afresh.
+//
+// Counts are only seen once their interval is reported, so the TTL should exceed
+// the interval of the CounterVec. Counters returned by With shouldn't be retained,
+// as counts made to the Counter of a removed series aren't reported.
+//
+// The option has no effect on instruments other than CounterVecs.
+func MetricOptionWithIdleTTL(ttl time.Duration) MetricOption {
+	return func(metric *Metric) {
+		metric.idleTTL = ttl
+	}
+}
+
+// CreateCounterVec creates a CounterVec of the named metric with the provided label
+// keys, counting over the provided interval (see CreateCounter).
+func (q *Quantifier) CreateCounterVec(name string, keys []string, interval int64, options ...MetricOption) (*CounterVec, error) {
+
+	instrument, err := q.createCounterVec(name, keys, interval, options...)
+	if err != nil && q.noopInstead(err) {
+		return newNoopCounterVec(), nil
+	}
+
+	return instrument, err
+}
+
+// createCounterVec creates a CounterVec (see CreateCounterVec).
+func (q *Quantifier) createCounterVec(name string, keys []string, interval int64, options ...MetricOption) (*CounterVec, error) {
+
+	if interval <= 0 {
+		return nil, errors.New("interval must be greater than 0")
+	}
+
+	labels := make(map[string]string, len(keys))
+	for _, key := range keys {
+
+		if _, ok := labels[key]; ok {
+			return nil, fmt.Errorf("%w: duplicate label key %s", ErrLabelMismatch, key)
+		}
+
+		labels[key] = ""
+	}
+
+	// validate the labels up front, as counters are created on first use
+	metric := &Metric{
+		Name:   name,
+		Labels: q.mergeCommonLabels(labels),
+	}
+
+	for _, option := range options {
+		option(metric)
+	}
+
+	if metric.idleTTL < 0 {
+		return nil, fmt.Errorf("%w: idle ttl %s", ErrNegativeValue, metric.idleTTL)
+	}
+
+	err := q.validateMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.validateInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &CounterVec{
+		q:        q,
+		name:     name,
+		keys:     append([]string{}, keys...),
+		interval: interval,
+		options:  options,
+		metric:   metric,
+		mu:       &sync.Mutex{},
+		counters: make(map[string]*metricCounter),
+	}
+
+	if metric.idleTTL > 0 {
+		v.lastActive = make(map[string]time.Time)
+	}
+
+	q.sources = append(q.sources, v)
+
+	return v, nil
+}
+
+// With returns the Counter of the series with the provided label values, given as
+// key/value pairs (e.g. With("status", "500")), creating it if it doesn't exist.
+// Every label key of the CounterVec must be given a value.
+//
+// If the labels don't match the label keys of the CounterVec, or the series can't
+// be created, the error is passed to the Quantifier's error handler and a Counter
+// that discards its counts is returned.
+func (v *CounterVec) With(keysAndValues ...string) *Counter {
+
+	if v.noop {
+		return newNoopCounter()
+	}
+
+	labels, err := v.labels(keysAndValues)
+	if err != nil {
+		v.q.errorHandler(v.q, err)
+		return newNoopCounter()
+	}
+
+	key := v.seriesKey(labels)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.lastActive != nil {
+		v.lastActive[key] = v.q.clock.Now()
+	}
+
+	if mc, ok := v.counters[key]; ok {
+		return mc.counter
+	}
+
+	mc, err := v.q.newMetricCounter(nil, v.name, labels, v.interval, v.options...)
+	if err != nil {
+		v.q.errorHandler(v.q, err)
+		return newNoopCounter()
+	}
+
+	// counts made to Counters retained by the caller are seen as they're reported
+	if v.lastActive != nil {
+		mc.observers = append(mc.observers, v.observer(key))
+	}
+
+	v.counters[key] = mc
+	return mc.counter
+}
+
+// observer returns a function that marks the series with the provided key as
+// active whenever its points are reported.
+func (v *CounterVec) observer(key string) func([]*Point) {
+	return func(points []*Point) {
+
+		v.mu.Lock()
+		defer v.mu.Unlock()
+
+		// the series may have been removed since its points were taken
+		if _, ok := v.lastActive[key]; ok {
+			v.lastActive[key] = v.q.clock.Now()
+		}
+	}
+}
+
+// evictIdle implements idleEvicter, removing and returning the counters of series
+// that have been idle for the idle TTL of the metric (see MetricOptionWithIdleTTL).
+func (v *CounterVec) evictIdle(now time.Time) []*metricCounter {
+
+	if v.lastActive == nil {
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var evicted []*metricCounter
+
+	for key, mc := range v.counters {
+
+		if now.Sub(v.lastActive[key]) < v.metric.idleTTL {
+			continue
+		}
+
+		delete(v.counters, key)
+		delete(v.lastActive, key)
+
+		evicted = append(evicted, mc)
+	}
+
+	return evicted
+}
+
+// labels returns the labels of the provided key/value pairs, or ErrLabelMismatch if
+// they don't match the label keys of the CounterVec.
+func (v *CounterVec) labels(keysAndValues []string) (map[string]string, error) {
+
+	if len(keysAndValues) != len(v.keys)*2 {
+		return nil, fmt.Errorf("%w of %s: expected values for %s", ErrLabelMismatch, v.name, strings.Join(v.keys, ", "))
+	}
+
+	labels := make(map[string]string, len(v.keys))
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+
+		key := keysAndValues[i]
+
+		if !v.hasKey(key) {
+			return nil, fmt.Errorf("%w of %s: unknown label key %s", ErrLabelMismatch, v.name, key)
+		}
+
+		labels[key] = v.metric.normalise(key, keysAndValues[i+1])
+	}
+
+	// a key given twice leaves another without a value
+	if len(labels) != len(v.keys) {
+		return nil, fmt.Errorf("%w of %s: expected values for %s", ErrLabelMismatch, v.name, strings.Join(v.keys, ", "))
+	}
+
+	return labels, nil
+}
+
+// hasKey returns whether the provided key is a label key of the CounterVec.
+func (v *CounterVec) hasKey(key string) bool {
+
+	for _, k := range v.keys {
+		if k == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// seriesKey returns a key identifying the series with the provided labels, joining
+// their values in the order of the CounterVec's label keys.
+func (v *CounterVec) seriesKey(labels map[string]string) string {
+
+	values := make([]string, len(v.keys))
+	for i, key := range v.keys {
+		values[i] = labels[key]
+	}
+
+	return strings.Join(values, "\xff")
+}
+
+// metricCounters implements counterSource, returning the counter of each series
+// created.
+func (v *CounterVec) metricCounters() []*metricCounter {
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	counters := make([]*metricCounter, 0, len(v.counters))
+	for _, mc := range v.counters {
+		counters = append(counters, mc)
+	}
+
+	return counters
+}
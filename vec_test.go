@@ -0,0 +1,198 @@
+//go:build !quantify_disabled
+
+package quantify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterVec_With(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681775, 0))
+
+	exporter := &mockExporter{}
+	errs := make([]error, 0)
+
+	client := &Quantifier{
+		clock:    mockClock,
+		exporter: exporter,
+		errorHandler: func(q *Quantifier, err error) {
+			errs = append(errs, err)
+		},
+	}
+
+	vec, err := client.CreateCounterVec("requests", []string{"method", "status"}, 10)
+	assert.NoError(t, err)
+
+	vec.With("status", "500", "method", "GET").Count()
+	vec.With("method", "GET", "status", "500").Count()
+	assert.NoError(t, vec.With("method", "POST", "status", "200").Add(3))
+
+	// counts with mismatched labels are discarded
+	vec.With("method", "GET").Count()
+	vec.With("method", "GET", "region", "eu").Count()
+	vec.With("method", "GET", "method", "POST").Count()
+
+	if assert.Len(t, errs, 3) {
+		for _, err := range errs {
+			assert.ErrorIs(t, err, ErrLabelMismatch)
+		}
+	}
+
+	mockClock.Add(time.Second * 10)
+	assert.NoError(t, client.report(false))
+
+	SortSeries(exporter.series)
+
+	if assert.Len(t, exporter.series, 2) {
+		assert.Equal(t, map[string]string{"method": "GET", "status": "500"}, exporter.series[0].Metric.Labels)
+		assert.Equal(t, int64(2), exporter.series[0].Points[0].Count)
+		assert.Equal(t, map[string]string{"method": "POST", "status": "200"}, exporter.series[1].Metric.Labels)
+		assert.Equal(t, int64(3), exporter.series[1].Points[0].Count)
+	}
+}
+
+func TestCounterVec_With_normaliser(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681775, 0))
+
+	exporter := &mockExporter{}
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+	}
+
+	stripQuery := func(value string) string {
+		path, _, _ := strings.Cut(value, "?")
+		return path
+	}
+
+	vec, err := client.CreateCounterVec("requests", []string{"path"}, 10, MetricOptionWithLabelNormaliser("path", stripQuery))
+	assert.NoError(t, err)
+
+	// values normalising to the same value count towards the same series
+	vec.With("path", "/planes?model=737").Count()
+	vec.With("path", "/planes?model=747").Count()
+	vec.With("path", "/planes").Count()
+
+	mockClock.Add(time.Second * 10)
+	assert.NoError(t, client.report(false))
+
+	if assert.Len(t, exporter.series, 1) {
+		assert.Equal(t, map[string]string{"path": "/planes"}, exporter.series[0].Metric.Labels)
+		assert.Equal(t, int64(3), exporter.series[0].Points[0].Count)
+	}
+}
+
+func TestQuantifier_CreateCounterVec(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		keys        []string
+		interval    int64
+		options     []MetricOption
+		expectedErr error
+	}{
+		{
+			name:     "valid",
+			keys:     []string{"method", "status"},
+			interval: 10,
+		},
+		{
+			name:        "duplicate key",
+			keys:        []string{"method", "method"},
+			interval:    10,
+			expectedErr: ErrLabelMismatch,
+		},
+		{
+			name:        "negative idle ttl",
+			keys:        []string{"method"},
+			interval:    10,
+			options:     []MetricOption{MetricOptionWithIdleTTL(-time.Second)},
+			expectedErr: ErrNegativeValue,
+		},
+	}
+
+	for _, test := range tests {
+
+		client := &Quantifier{
+			clock:    newMockClock(),
+			exporter: &mockExporter{},
+		}
+
+		_, err := client.CreateCounterVec("requests", test.keys, test.interval, test.options...)
+		assert.ErrorIsf(t, err, test.expectedErr, "%s failed", test.name)
+	}
+}
+
+func TestCounterVec_idleTTL(t *testing.T) {
+
+	mockClock := newMockClock()
+	mockClock.Set(time.Unix(1670681770, 0))
+
+	exporter := &mockExporter{}
+	deleted := make([]string, 0)
+
+	client := &Quantifier{
+		clock:        mockClock,
+		exporter:     exporter,
+		errorHandler: func(q *Quantifier, err error) {},
+		hooks: Hooks{
+			OnDelete: func(metric *Metric) {
+				deleted = append(deleted, metric.Labels["tenant"])
+			},
+		},
+	}
+
+	vec, err := client.CreateCounterVec("tenant_requests", []string{"tenant"}, 10, MetricOptionWithIdleTTL(time.Second*30))
+	assert.NoError(t, err)
+
+	// counters are retained, so activity is only seen as points are reported
+	a := vec.With("tenant", "a")
+	b := vec.With("tenant", "b")
+
+	a.Count()
+	b.Count()
+	mockClock.Add(time.Second * 10)
+	assert.NoError(t, client.report(false))
+
+	// b remains active, whilst a is idle
+	mockClock.Add(time.Second * 10)
+	b.Count()
+	mockClock.Add(time.Second * 10)
+	assert.NoError(t, client.report(false))
+
+	// a's remaining counts, including the current interval, are reported as it's
+	// removed
+	mockClock.Add(time.Second * 10)
+	a.Count()
+	exporter.series = nil
+	assert.NoError(t, client.report(false))
+
+	if assert.Len(t, exporter.series, 1) {
+		assert.Equal(t, "a", exporter.series[0].Metric.Labels["tenant"])
+		assert.Equal(t, int64(1), exporter.series[0].Points[0].Count)
+	}
+	assert.Equal(t, []string{"a"}, deleted)
+	assert.Len(t, vec.metricCounters(), 1)
+
+	// and a later use creates the series afresh
+	vec.With("tenant", "a").Count()
+	mockClock.Add(time.Second * 10)
+	exporter.series = nil
+	assert.NoError(t, client.report(false))
+
+	if assert.Len(t, exporter.series, 1) {
+		assert.Equal(t, "a", exporter.series[0].Metric.Labels["tenant"])
+		assert.Equal(t, int64(1), exporter.series[0].Points[0].Count)
+	}
+	assert.Len(t, vec.metricCounters(), 2)
+}
@@ -0,0 +1,170 @@
+// Package webhook provides a quantify.Exporter that POSTs a JSON payload of the
+// exported points to a configurable URL, for quick integrations with internal
+// collectors.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+	"github.com/rustedturnip/quantify/internal/record"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBackoff     = time.Second
+
+	// defaultTimeout bounds each request made by the default http.Client, so that
+	// an unresponsive webhook can't block reporting indefinitely.
+	defaultTimeout = time.Second * 10
+)
+
+var (
+	ErrNoURL = errors.New("no url provided")
+)
+
+// Payload is the JSON body posted to the webhook on each export.
+type Payload struct {
+	Points []*record.Record `json:"points"`
+}
+
+// StatusError is returned when the webhook responds with a non-2xx status code.
+type StatusError struct {
+	StatusCode int
+}
+
+func (se *StatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", se.StatusCode)
+}
+
+// retryable returns whether a request that received the status code should be
+// retried.
+func (se *StatusError) retryable() bool {
+	return se.StatusCode == http.StatusTooManyRequests || se.StatusCode >= http.StatusInternalServerError
+}
+
+// Exporter implements quantify.Exporter, posting points to a webhook.
+type Exporter struct {
+	url         string
+	client      *http.Client
+	headers     http.Header
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// New returns an instantiated Exporter that posts points to the provided URL, or
+// returns an error if instantiation fails.
+//
+// options allow the user to provide custom configurations as a list of Options.
+// If any options are invalid, a quantify.ValidationErrors describing every
+// problem is returned.
+func New(url string, options ...Option) (*Exporter, error) {
+
+	if url == "" {
+		return nil, ErrNoURL
+	}
+
+	exporter := &Exporter{
+		url:         url,
+		client:      &http.Client{Timeout: defaultTimeout},
+		headers:     http.Header{},
+		maxAttempts: defaultMaxAttempts,
+		backoff:     defaultBackoff,
+	}
+
+	// apply every option, so that all problems are reported together
+	var errs quantify.ValidationErrors
+
+	for _, option := range options {
+		err := option(exporter)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	return exporter, nil
+}
+
+// Export implements quantify.Exporter, posting a Payload of the provided series'
+// points to the webhook. Requests that fail with a network error, a 429, or a 5xx
+// status code are retried with an exponential backoff, up to the configured
+// maximum number of attempts.
+func (e *Exporter) Export(ctx context.Context, series []*quantify.Series) error {
+
+	records := record.FromSeries(series)
+	if len(records) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(&Payload{
+		Points: records,
+	})
+	if err != nil {
+		return err
+	}
+
+	backoff := e.backoff
+
+	for attempt := 1; ; attempt++ {
+
+		err = e.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && !statusErr.retryable() {
+			return err
+		}
+
+		if attempt >= e.maxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// post sends a single request to the webhook with the provided body.
+func (e *Exporter) post(ctx context.Context, body []byte) error {
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for key, values := range e.headers {
+		request.Header[key] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return &StatusError{
+			StatusCode: response.StatusCode,
+		}
+	}
+
+	return nil
+}
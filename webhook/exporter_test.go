@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rustedturnip/quantify"
+)
+
+var (
+	testSeries = []*quantify.Series{
+		{
+			Metric: &quantify.Metric{
+				Name: "planes",
+			},
+			Points: []*quantify.Point{
+				{
+					Start: time.Unix(1672693340, 0),
+					End:   time.Unix(1672693350, 0),
+					Count: 3,
+				},
+			},
+		},
+	}
+)
+
+func TestExporter_Export(t *testing.T) {
+
+	var body []byte
+	var header http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer server.Close()
+
+	exporter, err := New(server.URL, OptionWithBearerToken("secret"), OptionWithHeader("X-Source", "quantify"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, exporter.Export(context.Background(), testSeries))
+
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+	assert.Equal(t, "quantify", header.Get("X-Source"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"points": [
+			{
+				"metric": "planes",
+				"kind": "CUMULATIVE",
+				"value_type": "INT64",
+				"start": "2023-01-02T21:02:20Z",
+				"end": "2023-01-02T21:02:30Z",
+				"count": 3
+			}
+		]
+	}`, string(body))
+}
+
+func TestExporter_Export_retries(t *testing.T) {
+
+	tests := []struct {
+		name             string
+		statuses         []int
+		expectedAttempts int32
+		expectedError    error
+	}{
+		{
+			name:             "success after server error",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedAttempts: 2,
+			expectedError:    nil,
+		},
+		{
+			name:             "attempts exhausted",
+			statuses:         []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusInternalServerError},
+			expectedAttempts: 3,
+			expectedError:    &StatusError{StatusCode: http.StatusInternalServerError},
+		},
+		{
+			name:             "client error isn't retried",
+			statuses:         []int{http.StatusUnauthorized},
+			expectedAttempts: 1,
+			expectedError:    &StatusError{StatusCode: http.StatusUnauthorized},
+		},
+	}
+
+	for _, test := range tests {
+
+		var attempts int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempt := atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(test.statuses[attempt-1])
+		}))
+
+		exporter, err := New(server.URL, OptionWithRetries(3, time.Millisecond))
+		assert.NoError(t, err)
+
+		err = exporter.Export(context.Background(), testSeries)
+		server.Close()
+
+		assert.Equalf(t, test.expectedError, err, "%s failed", test.name)
+		assert.Equalf(t, test.expectedAttempts, attempts, "%s failed", test.name)
+	}
+}
+
+func TestNew(t *testing.T) {
+
+	_, err := New("")
+	assert.Equal(t, ErrNoURL, err)
+
+	_, err = New("http://localhost", OptionWithRetries(0, time.Second))
+	assert.EqualError(t, err, "retries.max_attempts: must be at least 1")
+
+	// the default client is bounded, rather than waiting indefinitely
+	exporter, err := New("http://localhost")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTimeout, exporter.client.Timeout)
+}
+
+func TestExporter_Export_timeout(t *testing.T) {
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	exporter, err := New(server.URL, OptionWithRetries(1, 0))
+	assert.NoError(t, err)
+	exporter.client.Timeout = time.Millisecond * 50
+
+	err = exporter.Export(context.Background(), testSeries)
+
+	var netErr net.Error
+	assert.True(t, errors.As(err, &netErr) && netErr.Timeout())
+}
@@ -0,0 +1,21 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rustedturnip/quantify/internal/golden"
+	"github.com/rustedturnip/quantify/internal/record"
+)
+
+func TestPayload_golden(t *testing.T) {
+
+	payload, err := json.Marshal(&Payload{
+		Points: record.FromSeries(golden.Series()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden.Assert(t, "payload", payload)
+}
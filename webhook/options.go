@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rustedturnip/quantify"
+)
+
+// Option defines a function for supplying the Exporter constructor with certain
+// configurations.
+type Option func(*Exporter) error
+
+// OptionWithHTTPClient allows a manually configured http.Client to be used to
+// post to the webhook, instead of the default client, which times out each
+// attempt after 10 seconds. The provided client should also bound its requests
+// (e.g. with a Timeout), as a request that never completes blocks reporting.
+func OptionWithHTTPClient(client *http.Client) Option {
+	return func(exporter *Exporter) error {
+		exporter.client = client
+		return nil
+	}
+}
+
+// OptionWithHeader adds a header to each request made to the webhook, for example
+// to provide authentication.
+func OptionWithHeader(key string, value string) Option {
+	return func(exporter *Exporter) error {
+		exporter.headers.Add(key, value)
+		return nil
+	}
+}
+
+// OptionWithBearerToken sets the Authorization header of each request made to the
+// webhook to the provided bearer token.
+func OptionWithBearerToken(token string) Option {
+	return func(exporter *Exporter) error {
+		exporter.headers.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// OptionWithRetries configures the number of attempts made to post each payload,
+// and the backoff between the first and second attempts, which doubles for each
+// subsequent attempt.
+func OptionWithRetries(maxAttempts int, backoff time.Duration) Option {
+	return func(exporter *Exporter) error {
+
+		if maxAttempts < 1 {
+			return &quantify.FieldError{Path: "retries.max_attempts", Err: errors.New("must be at least 1")}
+		}
+
+		if backoff < 0 {
+			return &quantify.FieldError{Path: "retries.backoff", Err: errors.New("can't be negative")}
+		}
+
+		exporter.maxAttempts = maxAttempts
+		exporter.backoff = backoff
+		return nil
+	}
+}